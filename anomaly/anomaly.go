@@ -0,0 +1,147 @@
+/*
+	Package anomaly detects rows which are unlike the other rows, such as
+	impossible values in a CSV file. Each detector gives a score to each
+	row, where a larger score is more anomalous, and flags the rows with a
+	score above a threshold. The z-score and interquartile range detectors
+	score each feature independently, Isolation Forest and the Local
+	Outlier Factor score rows using all the features together, and the
+	residual detector scores each value of a time series by its error
+	from a forecast.
+*/
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Detector is a fitted anomaly detector
+type Detector interface {
+	// Scores returns the score of each fitted row, where a larger score
+	// is more anomalous, or NaN when a row could not be scored
+	Scores() []float64
+
+	// Threshold returns the score above which a row is flagged
+	Threshold() float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Flags returns true for each fitted row with a score above the threshold
+// of the detector
+func Flags(detector Detector) []bool {
+	scores, threshold := detector.Scores(), detector.Threshold()
+	flags := make([]bool, len(scores))
+	for i, score := range scores {
+		flags[i] = score > threshold
+	}
+	return flags
+}
+
+// Append appends a column with the score of each row to the table, unless
+// the score column name is empty, and a column with a flag of true or
+// false for each row. Rows which could not be scored have nil values
+func Append(table *util.Table, detector Detector, score, flag string) error {
+	scores, threshold := detector.Scores(), detector.Threshold()
+	if scores == nil {
+		return ErrNotFitted
+	}
+	if score != "" {
+		if err := table.AppendFloatColumn(score, scores); err != nil {
+			return err
+		}
+	}
+	flags := make([]string, len(scores))
+	for i, value := range scores {
+		if math.IsNaN(value) == false {
+			flags[i] = strconv.FormatBool(value > threshold)
+		}
+	}
+	return table.AppendStringColumn(flag, flags)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// points returns the rows of a matrix with no missing values and the
+// index of each row, returning an error if there are no such rows
+func points(x mat.Matrix) ([][]float64, []int, error) {
+	r, c := x.Dims()
+	if r == 0 || c == 0 {
+		return nil, nil, ErrEmpty
+	}
+	result, index := make([][]float64, 0, r), make([]int, 0, r)
+	for i := 0; i < r; i++ {
+		if row := mat.Row(nil, i, x); hasNaN(row) == false {
+			result, index = append(result, row), append(index, i)
+		}
+	}
+	if len(result) == 0 {
+		return nil, nil, fmt.Errorf("%v: Every row has a missing value", ErrEmpty)
+	}
+	return result, index, nil
+}
+
+// finite returns the values which are not missing, in sorted order
+func finite(values []float64) []float64 {
+	result := make([]float64, 0, len(values))
+	for _, value := range values {
+		if math.IsNaN(value) == false {
+			result = append(result, value)
+		}
+	}
+	sort.Float64s(result)
+	return result
+}
+
+// quantile returns the p quantile of values, ignoring missing values
+func quantile(p float64, values []float64) float64 {
+	sorted := finite(values)
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	return stat.Quantile(p, stat.LinInterp, sorted, nil)
+}
+
+// mad returns the median absolute deviation from the median, scaled to
+// estimate the standard deviation of normally distributed values
+func mad(values []float64) (float64, float64) {
+	median := quantile(0.5, values)
+	deviations := make([]float64, len(values))
+	for i, value := range values {
+		deviations[i] = math.Abs(value - median)
+	}
+	return median, 1.4826 * quantile(0.5, deviations)
+}
+
+func hasNaN(values []float64) bool {
+	for _, value := range values {
+		if math.IsNaN(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func squared(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return sum
+}
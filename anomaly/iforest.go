@@ -0,0 +1,234 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// IsolationForestConfig is the configuration for Isolation Forest
+type IsolationForestConfig struct {
+	// Trees is the number of trees, and SampleSize is the number of rows
+	// sampled without replacement to build each tree
+	Trees      uint
+	SampleSize uint
+
+	// Contamination is the expected fraction of anomalies, which sets the
+	// threshold from the scores of the fitted rows. When zero, rows with
+	// a score above one half are flagged
+	Contamination float64
+
+	// Seed for sampling the rows and choosing the splits
+	Seed int64
+}
+
+// IsolationForest scores rows by how easily they are isolated from the
+// other rows by random splits, since anomalies are few and different.
+// Each tree splits a sample of rows on a random feature at a random value
+// until every row is isolated, and rows with short paths from the root
+// are anomalies (Liu, Ting and Zhou, 2008). Scores are between zero and
+// one, where scores close to one are anomalies
+type IsolationForest struct {
+	config    IsolationForestConfig
+	features  int
+	sample    int
+	trees     []*isolationNode
+	scores    []float64
+	threshold float64
+}
+
+// isolationNode is a node of an isolation tree, where a leaf has no
+// children and holds the number of rows which reached it
+type isolationNode struct {
+	feature     int
+	value       float64
+	left, right *isolationNode
+	size        int
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_TREES       = 100
+	DEFAULT_SAMPLE_SIZE = 256
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewIsolationForest returns Isolation Forest with the configuration
+func NewIsolationForest(config IsolationForestConfig) *IsolationForest {
+	return &IsolationForest{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit builds the trees from the samples, with one row for each sample,
+// and scores the rows. Rows with a missing value are not used to build
+// the trees and have a score of NaN
+func (this *IsolationForest) Fit(x mat.Matrix) error {
+	rows, _, err := points(x)
+	if err != nil {
+		return err
+	} else if this.config.Contamination < 0 || this.config.Contamination >= 1 {
+		return fmt.Errorf("%v: Contamination should be between zero and one", ErrBadParameter)
+	}
+	this.features = len(rows[0])
+	this.sample = this.sampleSize()
+	if this.sample > len(rows) {
+		this.sample = len(rows)
+	}
+
+	// Trees are limited to the average height of a tree built from the
+	// sample, since only short paths matter
+	limit := int(math.Ceil(math.Log2(float64(this.sample))))
+	r := rand.New(rand.NewSource(this.config.Seed))
+	this.trees = make([]*isolationNode, this.numberOfTrees())
+	for t := range this.trees {
+		sample := make([][]float64, this.sample)
+		for i, j := range r.Perm(len(rows))[:this.sample] {
+			sample[i] = rows[j]
+		}
+		this.trees[t] = isolate(sample, 0, limit, r)
+	}
+
+	if this.scores, err = this.Score(x); err != nil {
+		return err
+	}
+	if this.config.Contamination > 0 {
+		this.threshold = quantile(1-this.config.Contamination, this.scores)
+	} else {
+		this.threshold = 0.5
+	}
+	return nil
+}
+
+// Score returns the score of each row, or NaN when a value in the row is
+// missing
+func (this *IsolationForest) Score(x mat.Matrix) ([]float64, error) {
+	if this.trees == nil {
+		return nil, ErrNotFitted
+	}
+	rows, cols := x.Dims()
+	if cols != this.features {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, this.features)
+	}
+	scores := make([]float64, rows)
+	row := make([]float64, cols)
+	for i := range scores {
+		mat.Row(row, i, x)
+		if hasNaN(row) {
+			scores[i] = math.NaN()
+			continue
+		}
+		var length float64
+		for _, tree := range this.trees {
+			length += tree.pathLength(row, 0)
+		}
+		scores[i] = math.Pow(2, -length/float64(len(this.trees))/averagePath(this.sample))
+	}
+	return scores, nil
+}
+
+// Scores returns the score of each fitted row
+func (this *IsolationForest) Scores() []float64 {
+	return this.scores
+}
+
+// Threshold returns the score above which a row is flagged
+func (this *IsolationForest) Threshold() float64 {
+	return this.threshold
+}
+
+// Stringify
+func (this *IsolationForest) String() string {
+	return fmt.Sprintf("isolation_forest{ trees=%v sample_size=%v contamination=%v threshold=%.4f }", this.numberOfTrees(), this.sample, this.config.Contamination, this.threshold)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *IsolationForest) numberOfTrees() int {
+	if this.config.Trees == 0 {
+		return DEFAULT_TREES
+	}
+	return int(this.config.Trees)
+}
+
+func (this *IsolationForest) sampleSize() int {
+	if this.config.SampleSize == 0 {
+		return DEFAULT_SAMPLE_SIZE
+	}
+	return int(this.config.SampleSize)
+}
+
+// isolate returns a tree which splits rows on a random feature at a random
+// value between the smallest and largest values, until each row is
+// isolated, the rows are identical or the depth reaches the limit
+func isolate(rows [][]float64, depth, limit int, r *rand.Rand) *isolationNode {
+	if len(rows) <= 1 || depth >= limit {
+		return &isolationNode{size: len(rows)}
+	}
+
+	// Choose from the features which have more than one value
+	features := make([]int, 0, len(rows[0]))
+	min, max := make([]float64, len(rows[0])), make([]float64, len(rows[0]))
+	for j := range rows[0] {
+		min[j], max[j] = math.Inf(1), math.Inf(-1)
+		for _, row := range rows {
+			min[j], max[j] = math.Min(min[j], row[j]), math.Max(max[j], row[j])
+		}
+		if max[j] > min[j] {
+			features = append(features, j)
+		}
+	}
+	if len(features) == 0 {
+		return &isolationNode{size: len(rows)}
+	}
+	j := features[r.Intn(len(features))]
+	value := min[j] + r.Float64()*(max[j]-min[j])
+	var left, right [][]float64
+	for _, row := range rows {
+		if row[j] < value {
+			left = append(left, row)
+		} else {
+			right = append(right, row)
+		}
+	}
+	return &isolationNode{
+		feature: j,
+		value:   value,
+		left:    isolate(left, depth+1, limit, r),
+		right:   isolate(right, depth+1, limit, r),
+	}
+}
+
+// pathLength returns the number of edges from the root to the leaf for a
+// row, plus the average path length of the rows remaining in the leaf
+func (this *isolationNode) pathLength(row []float64, depth int) float64 {
+	if this.left == nil {
+		return float64(depth) + averagePath(this.size)
+	} else if row[this.feature] < this.value {
+		return this.left.pathLength(row, depth+1)
+	} else {
+		return this.right.pathLength(row, depth+1)
+	}
+}
+
+// averagePath returns the average path length of an unsuccessful search
+// in a binary search tree of n rows, used to normalise path lengths
+func averagePath(n int) float64 {
+	switch {
+	case n <= 1:
+		return 0
+	case n == 2:
+		return 1
+	default:
+		const euler = 0.5772156649
+		return 2*(math.Log(float64(n-1))+euler) - 2*float64(n-1)/float64(n)
+	}
+}
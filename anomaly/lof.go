@@ -0,0 +1,206 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// LOFConfig is the configuration for the Local Outlier Factor
+type LOFConfig struct {
+	// K is the number of neighbours used to estimate the local density
+	K uint
+
+	// Threshold is the factor above which a row is flagged, unless
+	// Contamination is set, which is the expected fraction of anomalies
+	// used to set the threshold from the scores of the fitted rows
+	Threshold     float64
+	Contamination float64
+}
+
+// LOF is the Local Outlier Factor, which compares the density of rows
+// around each row with the density around its neighbours (Breunig et al,
+// 2000). A factor close to one is as dense as its neighbours, and a factor
+// much larger than one is an anomaly, even when the density of rows
+// varies across the data
+type LOF struct {
+	config    LOFConfig
+	rows      [][]float64
+	distance  []float64
+	density   []float64
+	scores    []float64
+	threshold float64
+}
+
+// neighbour is a row with its distance
+type neighbour struct {
+	index    int
+	distance float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_NEIGHBOURS    = 20
+	DEFAULT_LOF_THRESHOLD = 1.5
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewLOF returns the Local Outlier Factor with the configuration
+func NewLOF(config LOFConfig) *LOF {
+	return &LOF{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit estimates the density around each sample, with one row for each
+// sample, and scores the rows. Each row is not counted as its own
+// neighbour. Rows with a missing value are not used to estimate the
+// density and have a score of NaN
+func (this *LOF) Fit(x mat.Matrix) error {
+	rows, index, err := points(x)
+	if err != nil {
+		return err
+	} else if len(rows) < 2 {
+		return fmt.Errorf("%v: Expected at least two rows", ErrBadParameter)
+	} else if this.config.Threshold < 0 || this.config.Contamination < 0 || this.config.Contamination >= 1 {
+		return fmt.Errorf("%v: Threshold or contamination out of range", ErrBadParameter)
+	}
+	this.rows = rows
+
+	// The k-distance of each row is the distance to its k'th neighbour
+	neighbours := make([][]neighbour, len(rows))
+	this.distance = make([]float64, len(rows))
+	for i, row := range rows {
+		neighbours[i] = this.neighbours(row, i)
+		this.distance[i] = neighbours[i][len(neighbours[i])-1].distance
+	}
+
+	// The local reachability density is the inverse of the mean
+	// reachability distance to the neighbours
+	this.density = make([]float64, len(rows))
+	for i := range rows {
+		this.density[i] = this.reachability(neighbours[i])
+	}
+
+	// The score is the mean density of the neighbours over the density
+	n, _ := x.Dims()
+	this.scores = make([]float64, n)
+	for i := range this.scores {
+		this.scores[i] = math.NaN()
+	}
+	for i := range rows {
+		this.scores[index[i]] = this.factor(neighbours[i], this.density[i])
+	}
+	if this.config.Contamination > 0 {
+		this.threshold = quantile(1-this.config.Contamination, this.scores)
+	} else if this.config.Threshold > 0 {
+		this.threshold = this.config.Threshold
+	} else {
+		this.threshold = DEFAULT_LOF_THRESHOLD
+	}
+	return nil
+}
+
+// Score returns the score of new rows relative to the fitted rows, or NaN
+// when a value in the row is missing
+func (this *LOF) Score(x mat.Matrix) ([]float64, error) {
+	if this.rows == nil {
+		return nil, ErrNotFitted
+	}
+	rows, cols := x.Dims()
+	if cols != len(this.rows[0]) {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, len(this.rows[0]))
+	}
+	scores := make([]float64, rows)
+	for i := range scores {
+		row := mat.Row(nil, i, x)
+		if hasNaN(row) {
+			scores[i] = math.NaN()
+			continue
+		}
+		neighbours := this.neighbours(row, -1)
+		scores[i] = this.factor(neighbours, this.reachability(neighbours))
+	}
+	return scores, nil
+}
+
+// Scores returns the score of each fitted row
+func (this *LOF) Scores() []float64 {
+	return this.scores
+}
+
+// Threshold returns the factor above which a row is flagged
+func (this *LOF) Threshold() float64 {
+	return this.threshold
+}
+
+// Stringify
+func (this *LOF) String() string {
+	return fmt.Sprintf("lof{ k=%v contamination=%v threshold=%.4f }", this.k(), this.config.Contamination, this.threshold)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *LOF) k() int {
+	if this.config.K == 0 {
+		return DEFAULT_NEIGHBOURS
+	}
+	return int(this.config.K)
+}
+
+// neighbours returns the k nearest fitted rows to a row, in order of
+// distance, excluding the fitted row with index self. Rows at the same
+// distance as the k'th neighbour are also included
+func (this *LOF) neighbours(row []float64, self int) []neighbour {
+	all := make([]neighbour, 0, len(this.rows))
+	for j, other := range this.rows {
+		if j != self {
+			all = append(all, neighbour{j, math.Sqrt(squared(row, other))})
+		}
+	}
+	sort.SliceStable(all, func(a, b int) bool { return all[a].distance < all[b].distance })
+	k := this.k()
+	if k > len(all) {
+		k = len(all)
+	}
+	for k < len(all) && all[k].distance == all[k-1].distance {
+		k++
+	}
+	return all[:k]
+}
+
+// reachability returns the local reachability density given the
+// neighbours of a row, where the reachability distance to a neighbour is
+// at least the k-distance of the neighbour
+func (this *LOF) reachability(neighbours []neighbour) float64 {
+	var sum float64
+	for _, n := range neighbours {
+		sum += math.Max(n.distance, this.distance[n.index])
+	}
+	if sum == 0 {
+		return math.Inf(1)
+	}
+	return float64(len(neighbours)) / sum
+}
+
+// factor returns the mean density of the neighbours over the density of a
+// row, which is one when duplicate rows make both densities infinite
+func (this *LOF) factor(neighbours []neighbour, density float64) float64 {
+	var sum float64
+	for _, n := range neighbours {
+		if math.IsInf(this.density[n.index], 1) && math.IsInf(density, 1) {
+			sum++
+		} else {
+			sum += this.density[n.index] / density
+		}
+	}
+	return sum / float64(len(neighbours))
+}
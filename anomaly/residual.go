@@ -0,0 +1,103 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/djthorpe/MachineLearning/forecast"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ResidualConfig is the configuration for the residual detector
+type ResidualConfig struct {
+	// Threshold is the number of standard deviations of the residuals
+	// above which a value is flagged
+	Threshold float64
+}
+
+// Residual scores each value of a time series by the error of its
+// one-step-ahead forecast, divided by a robust estimate of the standard
+// deviation of the errors, so that values which break from the trend or
+// season of the series are flagged
+type Residual struct {
+	config ResidualConfig
+	model  forecast.Model
+	scores []float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewResidual returns a residual detector using a forecasting model
+func NewResidual(model forecast.Model, config ResidualConfig) *Residual {
+	return &Residual{model: model, config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit fits the forecasting model to the values of a series which are not
+// missing, and scores each value. Missing values, and values with no
+// forecast at the start of the series, have a score of NaN
+func (this *Residual) Fit(series []float64) error {
+	if this.model == nil {
+		return fmt.Errorf("%v: Missing forecasting model", ErrBadParameter)
+	} else if this.config.Threshold < 0 {
+		return fmt.Errorf("%v: Threshold cannot be negative", ErrBadParameter)
+	}
+	values, index := make([]float64, 0, len(series)), make([]int, 0, len(series))
+	for i, value := range series {
+		if math.IsNaN(value) == false {
+			values, index = append(values, value), append(index, i)
+		}
+	}
+	if len(values) == 0 {
+		return ErrEmpty
+	} else if err := this.model.Fit(values); err != nil {
+		return err
+	}
+
+	// Standardise the residuals using the median absolute deviation
+	fitted := this.model.Fitted()
+	residuals := make([]float64, len(values))
+	for i := range values {
+		residuals[i] = values[i] - fitted[i]
+	}
+	centre, unit := mad(residuals)
+	this.scores = make([]float64, len(series))
+	for i := range this.scores {
+		this.scores[i] = math.NaN()
+	}
+	for i, residual := range residuals {
+		switch {
+		case math.IsNaN(residual):
+			continue
+		case unit > 0:
+			this.scores[index[i]] = math.Abs(residual-centre) / unit
+		case residual == centre:
+			this.scores[index[i]] = 0
+		default:
+			this.scores[index[i]] = math.Inf(1)
+		}
+	}
+	return nil
+}
+
+// Scores returns the score of each value in the fitted series
+func (this *Residual) Scores() []float64 {
+	return this.scores
+}
+
+// Threshold returns the number of standard deviations above which a value
+// is flagged
+func (this *Residual) Threshold() float64 {
+	if this.config.Threshold == 0 {
+		return DEFAULT_ZSCORE_THRESHOLD
+	}
+	return this.config.Threshold
+}
+
+// Stringify
+func (this *Residual) String() string {
+	return fmt.Sprintf("residual{ threshold=%v model=%v }", this.Threshold(), this.model)
+}
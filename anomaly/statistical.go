@@ -0,0 +1,216 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ZScoreConfig is the configuration for the z-score detector
+type ZScoreConfig struct {
+	// Threshold is the number of standard deviations from the mean above
+	// which a value is flagged
+	Threshold float64
+
+	// Robust uses the median and the median absolute deviation instead of
+	// the mean and standard deviation, so that the anomalies themselves
+	// do not hide other anomalies
+	Robust bool
+}
+
+// ZScore scores each row by the largest number of standard deviations of
+// any of its values from the mean of the feature
+type ZScore struct {
+	config       ZScoreConfig
+	centre, unit []float64
+	scores       []float64
+}
+
+// IQRConfig is the configuration for the interquartile range detector
+type IQRConfig struct {
+	// K is the number of interquartile ranges beyond the quartiles above
+	// which a value is flagged
+	K float64
+}
+
+// IQR scores each row by the largest distance of any of its values beyond
+// the lower or upper quartile of the feature, measured in interquartile
+// ranges, so that values between the quartiles score zero. This is the
+// rule used for the outliers of a box plot
+type IQR struct {
+	config       IQRConfig
+	lower, upper []float64
+	scores       []float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_ZSCORE_THRESHOLD = 3.0
+	DEFAULT_IQR_K            = 1.5
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewZScore returns a z-score detector with the configuration
+func NewZScore(config ZScoreConfig) *ZScore {
+	return &ZScore{config: config}
+}
+
+// NewIQR returns an interquartile range detector with the configuration
+func NewIQR(config IQRConfig) *IQR {
+	return &IQR{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Z-SCORE
+
+// Fit estimates the centre and spread of each feature, with one row for
+// each sample and ignoring missing values, and scores the rows
+func (this *ZScore) Fit(x mat.Matrix) error {
+	rows, cols := x.Dims()
+	if rows == 0 || cols == 0 {
+		return ErrEmpty
+	} else if this.config.Threshold < 0 {
+		return fmt.Errorf("%v: Threshold cannot be negative", ErrBadParameter)
+	}
+	this.centre, this.unit = make([]float64, cols), make([]float64, cols)
+	for j := 0; j < cols; j++ {
+		values := mat.Col(nil, j, x)
+		if this.config.Robust {
+			this.centre[j], this.unit[j] = mad(values)
+		} else {
+			this.centre[j], this.unit[j] = stat.MeanStdDev(finite(values), nil)
+		}
+	}
+	scores, err := this.Score(x)
+	this.scores = scores
+	return err
+}
+
+// Score returns the score of each row, or NaN when all values in the row
+// are missing
+func (this *ZScore) Score(x mat.Matrix) ([]float64, error) {
+	if this.centre == nil {
+		return nil, ErrNotFitted
+	}
+	return score(x, len(this.centre), func(j int, value float64) float64 {
+		if this.unit[j] == 0 || math.IsNaN(this.unit[j]) {
+			if value == this.centre[j] {
+				return 0
+			}
+			return math.Inf(1)
+		}
+		return math.Abs(value-this.centre[j]) / this.unit[j]
+	})
+}
+
+// Scores returns the score of each fitted row
+func (this *ZScore) Scores() []float64 {
+	return this.scores
+}
+
+// Threshold returns the number of standard deviations above which a row
+// is flagged
+func (this *ZScore) Threshold() float64 {
+	if this.config.Threshold == 0 {
+		return DEFAULT_ZSCORE_THRESHOLD
+	}
+	return this.config.Threshold
+}
+
+// Stringify
+func (this *ZScore) String() string {
+	return fmt.Sprintf("zscore{ threshold=%v robust=%v centre=%.4f unit=%.4f }", this.Threshold(), this.config.Robust, this.centre, this.unit)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// INTERQUARTILE RANGE
+
+// Fit estimates the quartiles of each feature, with one row for each
+// sample and ignoring missing values, and scores the rows
+func (this *IQR) Fit(x mat.Matrix) error {
+	rows, cols := x.Dims()
+	if rows == 0 || cols == 0 {
+		return ErrEmpty
+	} else if this.config.K < 0 {
+		return fmt.Errorf("%v: K cannot be negative", ErrBadParameter)
+	}
+	this.lower, this.upper = make([]float64, cols), make([]float64, cols)
+	for j := 0; j < cols; j++ {
+		values := mat.Col(nil, j, x)
+		this.lower[j], this.upper[j] = quantile(0.25, values), quantile(0.75, values)
+	}
+	scores, err := this.Score(x)
+	this.scores = scores
+	return err
+}
+
+// Score returns the score of each row, or NaN when all values in the row
+// are missing
+func (this *IQR) Score(x mat.Matrix) ([]float64, error) {
+	if this.lower == nil {
+		return nil, ErrNotFitted
+	}
+	return score(x, len(this.lower), func(j int, value float64) float64 {
+		iqr := this.upper[j] - this.lower[j]
+		beyond := math.Max(this.lower[j]-value, value-this.upper[j])
+		switch {
+		case beyond <= 0:
+			return 0
+		case iqr == 0:
+			return math.Inf(1)
+		default:
+			return beyond / iqr
+		}
+	})
+}
+
+// Scores returns the score of each fitted row
+func (this *IQR) Scores() []float64 {
+	return this.scores
+}
+
+// Threshold returns the number of interquartile ranges above which a row
+// is flagged
+func (this *IQR) Threshold() float64 {
+	if this.config.K == 0 {
+		return DEFAULT_IQR_K
+	}
+	return this.config.K
+}
+
+// Stringify
+func (this *IQR) String() string {
+	return fmt.Sprintf("iqr{ k=%v lower=%.4f upper=%.4f }", this.Threshold(), this.lower, this.upper)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// score returns the largest score of the values in each row, ignoring
+// missing values, or NaN when all values are missing or the row has no
+// valid score
+func score(x mat.Matrix, features int, f func(j int, value float64) float64) ([]float64, error) {
+	rows, cols := x.Dims()
+	if cols != features {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, features)
+	}
+	scores := make([]float64, rows)
+	for i := range scores {
+		scores[i] = math.NaN()
+		for j := 0; j < cols; j++ {
+			value := x.At(i, j)
+			if math.IsNaN(value) {
+				continue
+			}
+			if s := f(j, value); math.IsNaN(s) == false && (math.IsNaN(scores[i]) || s > scores[i]) {
+				scores[i] = s
+			}
+		}
+	}
+	return scores, nil
+}
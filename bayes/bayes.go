@@ -0,0 +1,312 @@
+/*
+	Package bayes implements naive Bayes classifiers, which predict the
+	probability of each label from the prior probability of the label and
+	the likelihood of each feature given the label, assuming the features
+	are independent. Gaussian naive Bayes is for continuous features, and
+	multinomial and Bernoulli naive Bayes are for counts and binary
+	features. Classifiers can be fitted incrementally, one batch of rows at
+	a time, and missing values are ignored.
+*/
+package bayes
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Config is the configuration for a classifier
+type Config struct {
+	// Alpha is the additive (Laplace) smoothing for multinomial and
+	// Bernoulli naive Bayes
+	Alpha float64
+
+	// Binarize is the threshold above which a feature is one for
+	// Bernoulli naive Bayes
+	Binarize float64
+
+	// VarSmoothing is the proportion of the largest feature variance
+	// added to every variance for Gaussian naive Bayes
+	VarSmoothing float64
+}
+
+// Gaussian assumes each feature is normally distributed for each label
+type Gaussian struct {
+	naive
+}
+
+// Multinomial assumes the features are counts drawn from a multinomial
+// distribution for each label
+type Multinomial struct {
+	naive
+}
+
+// Bernoulli assumes each feature is binary, and is one when greater
+// than the threshold
+type Bernoulli struct {
+	naive
+}
+
+// naive is common to all classifiers, with a distribution of the
+// features for each label and for all labels
+type naive struct {
+	config   Config
+	features []string
+	classes  []string
+	counts   map[string]float64
+	dists    map[string]distribution
+	total    distribution
+	samples  float64
+	new      func() distribution
+}
+
+// distribution is the distribution of features for a label. Rows are
+// checked before they are added, so that add cannot fail part way through
+// a batch
+type distribution interface {
+	check(row []float64) error
+	add(row []float64)
+	logLikelihood(row []float64) float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_ALPHA         = 1.0
+	DEFAULT_VAR_SMOOTHING = 1e-9
+)
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewGaussian returns a Gaussian naive Bayes classifier
+func NewGaussian(config Config) *Gaussian {
+	this := &Gaussian{naive: naive{config: config}}
+	this.new = func() distribution {
+		return &gaussian{model: this}
+	}
+	this.reset()
+	return this
+}
+
+// NewMultinomial returns a multinomial naive Bayes classifier
+func NewMultinomial(config Config) *Multinomial {
+	this := &Multinomial{naive: naive{config: config}}
+	this.new = func() distribution {
+		return &multinomial{alpha: this.alpha()}
+	}
+	this.reset()
+	return this
+}
+
+// NewBernoulli returns a Bernoulli naive Bayes classifier
+func NewBernoulli(config Config) *Bernoulli {
+	this := &Bernoulli{naive: naive{config: config}}
+	this.new = func() distribution {
+		return &bernoulli{alpha: this.alpha(), binarize: config.Binarize}
+	}
+	this.reset()
+	return this
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// NAIVE BAYES
+
+// Fit fits the classifier from the feature columns of the table to predict
+// the labels in the target column, discarding anything fitted before
+func (this *naive) Fit(table *util.Table, features []string, target string) error {
+	this.reset()
+	return this.PartialFit(table, features, target)
+}
+
+// PartialFit updates the classifier with more rows, so that data can be
+// fitted one batch at a time. The features must be the same for every
+// batch, and labels which were not seen before are added. Rows with a
+// missing label are ignored, and missing feature values are not counted
+func (this *naive) PartialFit(table *util.Table, features []string, target string) error {
+	if len(features) == 0 {
+		return fmt.Errorf("%v: Expected at least one feature", ErrBadParameter)
+	} else if this.features != nil && equals(this.features, features) == false {
+		return fmt.Errorf("%v: Features differ from previous fit", ErrBadParameter)
+	}
+	x, err := matrix(table, features)
+	if err != nil {
+		return err
+	}
+	labels, err := table.StringColumn(target, "")
+	if err != nil {
+		return err
+	}
+	// Check the whole batch before any row is added, so that the
+	// classifier is unchanged when an error is returned
+	for i, label := range labels {
+		if label == "" {
+			continue
+		}
+		if err := this.total.check(x[i]); err != nil {
+			return fmt.Errorf("Row %v: %v", i, err)
+		}
+	}
+	if this.features == nil {
+		this.features = append([]string(nil), features...)
+	}
+	for i, label := range labels {
+		if label == "" {
+			continue
+		}
+		if this.dists[label] == nil {
+			this.dists[label] = this.new()
+		}
+		this.dists[label].add(x[i])
+		this.total.add(x[i])
+		this.counts[label]++
+		this.samples++
+	}
+	this.classes = make([]string, 0, len(this.counts))
+	for label := range this.counts {
+		this.classes = append(this.classes, label)
+	}
+	sort.Strings(this.classes)
+	return nil
+}
+
+// Classes returns the labels in sorted order, which are the columns
+// returned by PredictProba
+func (this *naive) Classes() []string {
+	return this.classes
+}
+
+// Features returns the names of the features
+func (this *naive) Features() []string {
+	return this.features
+}
+
+// Predict returns the most probable label for each row
+func (this *naive) Predict(table *util.Table) ([]string, error) {
+	proba, err := this.PredictProba(table)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := proba.Dims()
+	predicted := make([]string, rows)
+	for i := range predicted {
+		predicted[i] = this.classes[argmax(proba.RawRowView(i))]
+	}
+	return predicted, nil
+}
+
+// PredictProba returns the probability of each label for each row. The
+// columns are in the order returned by Classes
+func (this *naive) PredictProba(table *util.Table) (*mat.Dense, error) {
+	if this.samples == 0 {
+		return nil, ErrNotFitted
+	}
+	x, err := matrix(table, this.features)
+	if err != nil {
+		return nil, err
+	}
+	proba := mat.NewDense(len(x), len(this.classes), nil)
+	joint := make([]float64, len(this.classes))
+	for i, row := range x {
+		for c, label := range this.classes {
+			joint[c] = math.Log(this.counts[label]/this.samples) + this.dists[label].logLikelihood(row)
+		}
+		// Normalise the joint probabilities, subtracting the largest to
+		// avoid underflow
+		max := joint[argmax(joint)]
+		var sum float64
+		for c := range joint {
+			joint[c] = math.Exp(joint[c] - max)
+			sum += joint[c]
+		}
+		for c := range joint {
+			proba.Set(i, c, joint[c]/sum)
+		}
+	}
+	return proba, nil
+}
+
+// Stringify
+func (this *naive) String() string {
+	return fmt.Sprintf("naive_bayes{ features=%v classes=%v samples=%v alpha=%v binarize=%v var_smoothing=%v }", len(this.features), len(this.classes), this.samples, this.alpha(), this.config.Binarize, this.varSmoothing())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *naive) reset() {
+	this.features, this.classes, this.samples = nil, nil, 0
+	this.counts = make(map[string]float64)
+	this.dists = make(map[string]distribution)
+	this.total = this.new()
+}
+
+func (this *naive) alpha() float64 {
+	if this.config.Alpha == 0 {
+		return DEFAULT_ALPHA
+	}
+	return this.config.Alpha
+}
+
+func (this *naive) varSmoothing() float64 {
+	if this.config.VarSmoothing == 0 {
+		return DEFAULT_VAR_SMOOTHING
+	}
+	return this.config.VarSmoothing
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// matrix returns the feature values for each row of the table, with
+// missing values as NaN
+func matrix(table *util.Table, features []string) ([][]float64, error) {
+	x := make([][]float64, len(table.Rows))
+	for i := range x {
+		x[i] = make([]float64, len(features))
+	}
+	for j, feature := range features {
+		if values, err := table.FloatColumn(feature, math.NaN()); err != nil {
+			return nil, fmt.Errorf("%v: %v", feature, err)
+		} else {
+			for i, value := range values {
+				x[i][j] = value
+			}
+		}
+	}
+	return x, nil
+}
+
+// equals returns true if two slices of strings are the same
+func equals(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// argmax returns the index of the largest value, or the first index when
+// values are equal
+func argmax(values []float64) int {
+	best := 0
+	for i, value := range values {
+		if value > values[best] {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,157 @@
+package bayes
+
+import (
+	"fmt"
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// gaussian is the mean and variance of each feature, updated one row at a
+// time using Welford's algorithm
+type gaussian struct {
+	model       *Gaussian
+	n, mean, m2 []float64
+}
+
+// multinomial is the total of each feature
+type multinomial struct {
+	alpha  float64
+	counts []float64
+	total  float64
+}
+
+// bernoulli is the number of rows where each feature is one, and the
+// number of rows with a value for each feature
+type bernoulli struct {
+	alpha    float64
+	binarize float64
+	n, ones  []float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GAUSSIAN
+
+func (this *gaussian) check(row []float64) error {
+	return nil
+}
+
+func (this *gaussian) add(row []float64) {
+	if this.n == nil {
+		this.n, this.mean, this.m2 = make([]float64, len(row)), make([]float64, len(row)), make([]float64, len(row))
+	}
+	for j, value := range row {
+		if math.IsNaN(value) {
+			continue
+		}
+		this.n[j]++
+		delta := value - this.mean[j]
+		this.mean[j] += delta / this.n[j]
+		this.m2[j] += delta * (value - this.mean[j])
+	}
+}
+
+func (this *gaussian) logLikelihood(row []float64) float64 {
+	epsilon := this.model.epsilon()
+	var ll float64
+	for j, value := range row {
+		if math.IsNaN(value) || this.n[j] == 0 {
+			continue
+		}
+		variance := this.m2[j]/this.n[j] + epsilon
+		ll -= 0.5*math.Log(2*math.Pi*variance) + (value-this.mean[j])*(value-this.mean[j])/(2*variance)
+	}
+	return ll
+}
+
+// epsilon returns the variance added to every feature so that features
+// with zero variance for a label do not have infinite likelihood
+func (this *Gaussian) epsilon() float64 {
+	var max float64
+	total := this.total.(*gaussian)
+	for j := range total.n {
+		if total.n[j] > 0 {
+			max = math.Max(max, total.m2[j]/total.n[j])
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+	return this.varSmoothing() * max
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// MULTINOMIAL
+
+// check returns an error if any count is negative
+func (this *multinomial) check(row []float64) error {
+	for _, value := range row {
+		if value < 0 {
+			return fmt.Errorf("%v: Negative count", ErrBadParameter)
+		}
+	}
+	return nil
+}
+
+func (this *multinomial) add(row []float64) {
+	if this.counts == nil {
+		this.counts = make([]float64, len(row))
+	}
+	for j, value := range row {
+		if math.IsNaN(value) {
+			continue
+		}
+		this.counts[j] += value
+		this.total += value
+	}
+}
+
+func (this *multinomial) logLikelihood(row []float64) float64 {
+	var ll float64
+	denominator := this.total + this.alpha*float64(len(this.counts))
+	for j, value := range row {
+		if math.IsNaN(value) || value == 0 {
+			continue
+		}
+		ll += value * math.Log((this.counts[j]+this.alpha)/denominator)
+	}
+	return ll
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// BERNOULLI
+
+func (this *bernoulli) check(row []float64) error {
+	return nil
+}
+
+func (this *bernoulli) add(row []float64) {
+	if this.n == nil {
+		this.n, this.ones = make([]float64, len(row)), make([]float64, len(row))
+	}
+	for j, value := range row {
+		if math.IsNaN(value) {
+			continue
+		}
+		this.n[j]++
+		if value > this.binarize {
+			this.ones[j]++
+		}
+	}
+}
+
+func (this *bernoulli) logLikelihood(row []float64) float64 {
+	var ll float64
+	for j, value := range row {
+		if math.IsNaN(value) {
+			continue
+		}
+		p := (this.ones[j] + this.alpha) / (this.n[j] + 2*this.alpha)
+		if value > this.binarize {
+			ll += math.Log(p)
+		} else {
+			ll += math.Log(1 - p)
+		}
+	}
+	return ll
+}
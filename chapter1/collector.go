@@ -0,0 +1,96 @@
+// Usage:
+//  go run chapter1/collector.go -db citibike.sqlite
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/gbfs"
+	"github.com/djthorpe/MachineLearning/stationdb"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	FlagDatabasePath = flag.String("db", "", "Path to the database")
+	FlagURL          = flag.String("url", gbfs.CITIBIKE_URL, "URL of the gbfs.json discovery file")
+	FlagMinInterval  = flag.Duration("min_interval", 10*time.Second, "Minimum interval between polls")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Collect fetches the station status and stores a snapshot, returning
+// the time to wait until the next poll
+func Collect(client *gbfs.Client, db *stationdb.DB) (time.Duration, error) {
+	// Update the station information, which is cached by the client
+	if info, err := client.StationInformation(); err != nil {
+		log.Println("Unable to fetch station information:", err)
+	} else if err := db.WriteStationInformation(info); err != nil {
+		return 0, err
+	}
+
+	// Write the station status
+	status, err := client.StationStatus()
+	if err != nil {
+		return 0, err
+	}
+	if readings, err := db.WriteSnapshot(status); err != nil {
+		return 0, err
+	} else {
+		log.Printf("last_updated=%v stations=%v readings=%v", status.LastUpdated.Time, len(status.Data.Stations), readings)
+	}
+
+	// Return the time until the feed expires
+	return status.TTL.Duration, nil
+}
+
+func RunMain() int {
+	// Check database flag
+	if *FlagDatabasePath == "" {
+		log.Println("Expected -db flag")
+		return -1
+	}
+	// Open the database
+	db, err := stationdb.Open(*FlagDatabasePath)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	defer db.Close()
+
+	// Stop on interrupt
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	// Poll the feed until stopped
+	client := gbfs.NewClient(gbfs.Config{URL: *FlagURL})
+	for {
+		interval, err := Collect(client, db)
+		if err != nil {
+			log.Println(err)
+		}
+		if interval < *FlagMinInterval {
+			interval = *FlagMinInterval
+		}
+		select {
+		case <-stop:
+			log.Println("Stopping")
+			return 0
+		case <-time.After(interval):
+			continue
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,256 @@
+// Usage:
+//  go run chapter1/completion.go chapter1/data.csv
+//  go run chapter1/completion.go -year 2018 -out predicted.csv chapter1/data.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/regression"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Trend is a straight line fitted to the remaining values over time, with
+// the statistics required for a confidence band around the line
+type Trend struct {
+	Intercept, Slope float64
+
+	// Residual standard error, number of samples, mean time and the sum
+	// of squared deviations of time from the mean
+	S, N, Mean, Sxx float64
+
+	// Critical value of Student's t distribution
+	T float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagYear   = flag.Int("year", 2018, "Year of the first timestamp, which do not include the year")
+	flagLevel  = flag.Float64("level", 0.95, "Confidence level of the band around the completion time")
+	flagOutput = flag.String("out", "", "Write the table with the predicted columns to a CSV file")
+)
+
+const (
+	TIME_COLUMN            = "Date/Time"
+	TIME_LAYOUT            = "2 Jan 15:04"
+	FILES_COLUMN           = "Files Remaining"
+	PREDICTED_FILES_COLUMN = "Predicted Files"
+	DATA_COLUMN            = "Data Remaining (MB)"
+	PREDICTED_DATA_COLUMN  = "Predicted Data Remaining (MB)"
+	OUTPUT_LAYOUT          = "Mon 2 Jan 2006 15:04"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Times returns the timestamps with the year set, which is incremented
+// whenever a timestamp is earlier than the one before
+func Times(table *util.Table, year int) ([]time.Time, error) {
+	times, err := table.TimeColumn(TIME_COLUMN)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range times {
+		if t.IsZero() {
+			return nil, fmt.Errorf("Line %v: Missing timestamp", i+2)
+		}
+		times[i] = t.AddDate(year-t.Year(), 0, 0)
+		if i > 0 && times[i].Before(times[i-1]) {
+			year++
+			times[i] = times[i].AddDate(1, 0, 0)
+		}
+	}
+	return times, nil
+}
+
+// NewTrend fits a straight line to the values which are not NaN, where
+// x is the number of hours since the first timestamp
+func NewTrend(hours, values []float64, level float64) (*Trend, error) {
+	x, y := make([]float64, 0, len(values)), make([]float64, 0, len(values))
+	for i := range values {
+		if math.IsNaN(values[i]) == false {
+			x = append(x, hours[i])
+			y = append(y, values[i])
+		}
+	}
+	if len(x) < 3 {
+		return nil, fmt.Errorf("Expected at least three values to fit a trend")
+	}
+	model := regression.NewLinear(0)
+	if err := model.Fit(mat.NewDense(len(x), 1, x), y); err != nil {
+		return nil, err
+	}
+	this := &Trend{Intercept: model.Intercept, Slope: model.Coefficients[0], N: float64(len(x))}
+	var sse float64
+	for i := range x {
+		this.Mean += x[i] / this.N
+		sse += math.Pow(y[i]-this.Predict(x[i]), 2)
+	}
+	for i := range x {
+		this.Sxx += (x[i] - this.Mean) * (x[i] - this.Mean)
+	}
+	this.S = math.Sqrt(sse / (this.N - 2))
+	this.T = distuv.StudentsT{Mu: 0, Sigma: 1, Nu: this.N - 2}.Quantile(0.5 + level/2)
+	return this, nil
+}
+
+// Predict returns the value of the trend at x
+func (this *Trend) Predict(x float64) float64 {
+	return this.Intercept + this.Slope*x
+}
+
+// Band returns the lower and upper bounds of the confidence band for the
+// trend at x
+func (this *Trend) Band(x float64) (float64, float64) {
+	width := this.T * this.S * math.Sqrt(1/this.N+(x-this.Mean)*(x-this.Mean)/this.Sxx)
+	return this.Predict(x) - width, this.Predict(x) + width
+}
+
+// Completion returns the time when the trend reaches zero, and the
+// earliest and latest times when the confidence band reaches zero. The
+// latest time is +Inf if the upper bound never reaches zero
+func (this *Trend) Completion() (float64, float64, float64, error) {
+	if this.Slope >= 0 {
+		return 0, 0, 0, fmt.Errorf("Remaining values are not decreasing")
+	}
+	x := -this.Intercept / this.Slope
+	lower := func(x float64) float64 { l, _ := this.Band(x); return l }
+	upper := func(x float64) float64 { _, u := this.Band(x); return u }
+	return x, bisect(lower, this.Mean, x), bisect(upper, x, math.Inf(1)), nil
+}
+
+// bisect returns x between a and b where f(x) crosses zero from positive to
+// negative. When b is +Inf, the interval is extended until f(b) is negative
+func bisect(f func(float64) float64, a, b float64) float64 {
+	if math.IsInf(b, 1) {
+		step := math.Max(1, math.Abs(a))
+		for b = a + step; f(b) > 0; b = a + step {
+			if step *= 2; step > 1e9 {
+				return math.Inf(1)
+			}
+		}
+	}
+	if f(a) <= 0 {
+		return a
+	}
+	for i := 0; i < 100 && b-a > 1e-6; i++ {
+		if m := (a + b) / 2; f(m) > 0 {
+			a = m
+		} else {
+			b = m
+		}
+	}
+	return (a + b) / 2
+}
+
+// Estimate fills the predicted column from the trend of the remaining
+// column, and outputs the estimated completion time
+func Estimate(table *util.Table, first time.Time, hours []float64, remaining, predicted string) error {
+	values, err := table.FloatColumn(remaining, math.NaN())
+	if err != nil {
+		return fmt.Errorf("%v: %v", remaining, err)
+	}
+	trend, err := NewTrend(hours, values, *flagLevel)
+	if err != nil {
+		return fmt.Errorf("%v: %v", remaining, err)
+	}
+	for i, x := range hours {
+		// Rows after the trend reaches zero are left empty
+		value := math.Round(trend.Predict(x))
+		if value < 0 {
+			value = math.NaN()
+		}
+		if err := table.SetFloat(i, predicted, value); err != nil {
+			return err
+		}
+	}
+	if x, earliest, latest, err := trend.Completion(); err != nil {
+		return fmt.Errorf("%v: %v", remaining, err)
+	} else {
+		fmt.Printf("%v: %.2f per hour, complete at %v\n", remaining, -trend.Slope, Format(first, x))
+		fmt.Printf("  %.0f%% confidence between %v and %v\n", *flagLevel*100, Format(first, earliest), Format(first, latest))
+	}
+	return nil
+}
+
+// Format returns the time a number of hours after first
+func Format(first time.Time, hours float64) string {
+	if math.IsInf(hours, 1) {
+		return "never"
+	}
+	return first.Add(time.Duration(hours * float64(time.Hour))).Format(OUTPUT_LAYOUT)
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+	if *flagLevel <= 0 || *flagLevel >= 1 {
+		log.Println("Expected -level to be between zero and one")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	table.SetTimeLayouts(TIME_LAYOUT)
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	if err := table.RemoveThousandsSeparator(",", FILES_COLUMN, PREDICTED_FILES_COLUMN, DATA_COLUMN, PREDICTED_DATA_COLUMN); err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	// Compute the number of hours since the first timestamp
+	times, err := Times(table, *flagYear)
+	if err != nil {
+		log.Println(err)
+		return -1
+	} else if len(times) == 0 {
+		log.Println("No rows")
+		return -1
+	}
+	hours := make([]float64, len(times))
+	for i, t := range times {
+		hours[i] = t.Sub(times[0]).Hours()
+	}
+
+	// Fit the trends and fill the predicted columns
+	if err := Estimate(table, times[0], hours, FILES_COLUMN, PREDICTED_FILES_COLUMN); err != nil {
+		log.Println(err)
+		return -1
+	}
+	if err := Estimate(table, times[0], hours, DATA_COLUMN, PREDICTED_DATA_COLUMN); err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	if *flagOutput != "" {
+		if err := table.WriteCSV(*flagOutput); err != nil {
+			log.Println(err)
+			return -1
+		}
+	} else {
+		fmt.Println(table)
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
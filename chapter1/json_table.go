@@ -0,0 +1,94 @@
+// Usage:
+//  go run chapter1/json_table.go -path data.stations -time last_reported https://gbfs.citibikenyc.com/gbfs/en/station_status.json
+//  go run chapter1/json_table.go -describe -path data.stations https://gbfs.citibikenyc.com/gbfs/en/station_information.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	FlagPath     = flag.String("path", "", "Dotted path to the array of objects, such as data.stations")
+	FlagTime     = flag.String("time", "", "Comma-separated columns containing unix timestamps")
+	FlagDuration = flag.String("duration", "", "Comma-separated columns containing durations in seconds")
+	FlagDescribe = flag.Bool("describe", false, "Describe the table rather than output all rows")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Open returns a reader for a URL or a file
+func Open(location string) (io.ReadCloser, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		if response, err := http.Get(location); err != nil {
+			return nil, err
+		} else if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("%v: %v", location, response.Status)
+		} else {
+			return response.Body, nil
+		}
+	}
+	return os.Open(location)
+}
+
+// Decoders returns the decoders for the columns set by the flags
+func Decoders() map[string]util.JSONDecoder {
+	decoders := make(map[string]util.JSONDecoder)
+	for _, column := range strings.Split(*FlagTime, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			decoders[column] = util.JSONUnixTime
+		}
+	}
+	for _, column := range strings.Split(*FlagDuration, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			decoders[column] = util.JSONDuration
+		}
+	}
+	return decoders
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected URL or file argument")
+		return -1
+	}
+
+	reader, err := Open(flag.Arg(0))
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	defer reader.Close()
+
+	if table, err := util.ReadJSON(reader, util.JSONOptions{Path: *FlagPath, Decoders: Decoders()}); err != nil {
+		log.Println("Unable to read JSON:", err)
+		return -1
+	} else if *FlagDescribe == false {
+		fmt.Println(table)
+	} else if description, err := table.Describe(); err != nil {
+		log.Println(err)
+		return -1
+	} else {
+		fmt.Println(description)
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,100 @@
+// Usage:
+//  go run chapter1/sql_table.go -db citibike.sqlite -query "SELECT * FROM station_status"
+//  go run chapter1/sql_table.go -db test.sqlite -write iris chapter2/iris.csv
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/util"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	FlagDatabasePath = flag.String("db", "", "Path to the database")
+	FlagQuery        = flag.String("query", "", "Query to read into a table")
+	FlagDescribe     = flag.Bool("describe", false, "Describe the table rather than output all rows")
+	FlagWrite        = flag.String("write", "", "Name of the table to write the CSV file to")
+	FlagReplace      = flag.Bool("replace", false, "Replace an existing table when writing")
+	FlagAppend       = flag.Bool("append", false, "Append to an existing table when writing")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func ReadTable(db *sql.DB) error {
+	if table, err := util.ReadSQL(db, *FlagQuery); err != nil {
+		return err
+	} else if *FlagDescribe == false {
+		fmt.Println(table)
+	} else if description, err := table.Describe(); err != nil {
+		return err
+	} else {
+		fmt.Println(description)
+	}
+	return nil
+}
+
+func WriteTable(db *sql.DB, filename string) error {
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(filename, false, true, true); err != nil {
+		return err
+	}
+	if err := table.WriteSQL(db, *FlagWrite, util.SQLOptions{Replace: *FlagReplace, Append: *FlagAppend}); err != nil {
+		return err
+	}
+	fmt.Printf("Written %v rows to %v\n", len(table.Rows), *FlagWrite)
+	return nil
+}
+
+func RunMain() int {
+	// Check flags
+	if *FlagDatabasePath == "" {
+		log.Println("Expected -db flag")
+		return -1
+	}
+	if *FlagWrite != "" && flag.NArg() != 1 {
+		log.Println("Expected file argument with -write flag")
+		return -1
+	}
+	if *FlagWrite == "" && *FlagQuery == "" {
+		log.Println("Expected -query or -write flag")
+		return -1
+	}
+
+	// Open the database
+	db, err := sql.Open("sqlite3", *FlagDatabasePath)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	defer db.Close()
+
+	// Write the CSV file and then read the query
+	if *FlagWrite != "" {
+		if err := WriteTable(db, flag.Arg(0)); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	if *FlagQuery != "" {
+		if err := ReadTable(db); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
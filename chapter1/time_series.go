@@ -0,0 +1,163 @@
+// Usage:
+//  go run chapter1/time_series.go -time "Date/Time" -layout "2 Jan 15:04" -columns "Files Remaining" -resample 24h -aggregate min chapter1/data.csv
+//  go run chapter1/time_series.go -time "Date/Time" -layout "2 Jan 15:04" -columns "Files Remaining" -window 3 -diff 1 -pct 1 chapter1/data.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagTime      = flag.String("time", "", "Name of the time column")
+	flagLayout    = flag.String("layout", "", "Layout of the time column, such as \"2 Jan 15:04\"")
+	flagColumns   = flag.String("columns", "", "Comma-separated columns, or all numeric columns if empty")
+	flagResample  = flag.Duration("resample", 0, "Resample to a fixed interval, such as 1h")
+	flagAggregate = flag.String("aggregate", "mean", "Aggregation when resampling (mean, sum, min, max, std, first, last, count)")
+	flagWindow    = flag.Uint("window", 0, "Number of rows in a rolling window")
+	flagRolling   = flag.String("rolling", "mean,std,min,max", "Comma-separated aggregations for the rolling window")
+	flagLag       = flag.Int("lag", 0, "Append a column lagged by a number of rows, or leading if negative")
+	flagDiff      = flag.Uint("diff", 0, "Append the difference with the value a number of rows earlier")
+	flagPct       = flag.Uint("pct", 0, "Append the percentage change from the value a number of rows earlier")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func ParseAggregation(value string) (util.Aggregation, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "mean":
+		return util.AGGREGATE_MEAN, nil
+	case "sum":
+		return util.AGGREGATE_SUM, nil
+	case "min":
+		return util.AGGREGATE_MIN, nil
+	case "max":
+		return util.AGGREGATE_MAX, nil
+	case "std":
+		return util.AGGREGATE_STD, nil
+	case "first":
+		return util.AGGREGATE_FIRST, nil
+	case "last":
+		return util.AGGREGATE_LAST, nil
+	case "count":
+		return util.AGGREGATE_COUNT, nil
+	default:
+		return 0, fmt.Errorf("Invalid aggregation: %v", value)
+	}
+}
+
+func Columns(table *util.Table) []string {
+	columns := make([]string, 0)
+	for _, column := range strings.Split(*flagColumns, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return table.NumericColumns()
+	}
+	return columns
+}
+
+// Derive appends the rolling windows, lags, differences and percentage
+// changes for each column
+func Derive(table *util.Table, columns []string) error {
+	for _, column := range columns {
+		if *flagWindow > 0 {
+			for _, name := range strings.Split(*flagRolling, ",") {
+				if aggregation, err := ParseAggregation(name); err != nil {
+					return err
+				} else if err := table.AppendRolling(fmt.Sprintf("%v %v(%v)", column, strings.TrimSpace(name), *flagWindow), column, *flagWindow, aggregation); err != nil {
+					return err
+				}
+			}
+		}
+		if *flagLag > 0 {
+			if err := table.AppendLag(fmt.Sprintf("%v lag(%v)", column, *flagLag), column, *flagLag); err != nil {
+				return err
+			}
+		} else if *flagLag < 0 {
+			if err := table.AppendLead(fmt.Sprintf("%v lead(%v)", column, -*flagLag), column, -*flagLag); err != nil {
+				return err
+			}
+		}
+		if *flagDiff > 0 {
+			if err := table.AppendDiff(fmt.Sprintf("%v diff(%v)", column, *flagDiff), column, *flagDiff); err != nil {
+				return err
+			}
+		}
+		if *flagPct > 0 {
+			if err := table.AppendPercentChange(fmt.Sprintf("%v pct(%v)", column, *flagPct), column, *flagPct); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+	if *flagTime == "" {
+		log.Println("Expected -time flag")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if *flagLayout != "" {
+		table.SetTimeLayouts(*flagLayout)
+	}
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	if err := table.SortByTime(*flagTime); err != nil {
+		log.Println(*flagTime, ":", err)
+		return -1
+	}
+
+	// Select the columns, and resample if required
+	columns := Columns(table)
+	if *flagResample > 0 {
+		if aggregation, err := ParseAggregation(*flagAggregate); err != nil {
+			log.Println(err)
+			return -1
+		} else {
+			aggregations := make(map[string]util.Aggregation, len(columns))
+			for _, column := range columns {
+				aggregations[column] = aggregation
+			}
+			if resampled, err := table.Resample(*flagTime, *flagResample, aggregations); err != nil {
+				log.Println("Unable to resample:", err)
+				return -1
+			} else {
+				table = resampled
+			}
+		}
+	}
+
+	if err := Derive(table, columns); err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	fmt.Println(table)
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
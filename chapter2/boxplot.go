@@ -0,0 +1,91 @@
+// Usage:
+//  go get -u gonum.org/v1/plot/...
+//  go run chapter2/boxplot.go -group Name -violin chapter2/iris.csv
+//  open iris.csv_boxplots.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagColumns = flag.String("columns", "", "Comma-separated columns to plot, defaults to all numeric columns")
+	flagGroup   = flag.String("group", "", "Optional categorical column to group by")
+	flagViolin  = flag.Bool("violin", false, "Draw violin plots around the boxes")
+	flagFormat  = flag.String("format", "png", "Output format (png, svg, pdf)")
+	flagOut     = flag.String("out", "", "Output filename, overrides -format")
+	flagHeight  = flag.Float64("height", 4, "Height of the plot in inches")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	filename := flag.Arg(0)
+	if err := table.ReadCSV(filename, false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+
+	var columns []string
+	if *flagColumns != "" {
+		for _, column := range strings.Split(*flagColumns, ",") {
+			columns = append(columns, strings.TrimSpace(column))
+		}
+	}
+
+	out := *flagOut
+	if out == "" {
+		out = path.Base(filename) + "_boxplots." + *flagFormat
+	}
+
+	// Make the plot wider when there are more columns
+	count := len(columns)
+	if count == 0 {
+		count = len(table.NumericColumns())
+	}
+	width := 4 * vg.Inch
+	if w := vg.Length(count) * 1.5 * vg.Inch; w > width {
+		width = w
+	}
+
+	if p, err := plots.BoxPlot(table, plots.BoxPlotOptions{
+		Columns: columns,
+		Group:   *flagGroup,
+		Violin:  *flagViolin,
+	}); err != nil {
+		log.Println("Unable to create box plot:", err)
+		return -1
+	} else if err := plots.Save(p, width, vg.Length(*flagHeight)*vg.Inch, out); err != nil {
+		log.Println("Unable to save box plot:", err)
+		return -1
+	} else {
+		fmt.Println("Written", out)
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,129 @@
+// Usage:
+//  go get -u gonum.org/v1/plot/...
+//  go run chapter2/histogram.go -column PetalLength -strategy fd -group Name -kde chapter2/iris.csv
+//  open iris.csv_PetalLength_hist.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagColumn   = flag.String("column", "", "Name of the column to plot")
+	flagGroup    = flag.String("group", "", "Optional categorical column to group by")
+	flagStrategy = flag.String("strategy", "fixed", "Bin strategy (fixed, sturges, fd, edges)")
+	flagBins     = flag.Uint("bins", 16, "Number of bins for the fixed strategy")
+	flagEdges    = flag.String("edges", "", "Comma-separated bin edges for the edges strategy")
+	flagKDE      = flag.Bool("kde", false, "Overlay a kernel density estimate")
+	flagDensity  = flag.Bool("density", false, "Normalize the histogram to a density")
+	flagFormat   = flag.String("format", "png", "Output format (png, svg, pdf)")
+	flagOut      = flag.String("out", "", "Output filename, overrides -format")
+	flagWidth    = flag.Float64("width", 4, "Width of the plot in inches")
+	flagHeight   = flag.Float64("height", 4, "Height of the plot in inches")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func ParseStrategy(value string) (plots.BinStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "fixed":
+		return plots.BIN_FIXED, nil
+	case "sturges":
+		return plots.BIN_STURGES, nil
+	case "fd", "freedman-diaconis":
+		return plots.BIN_FREEDMAN_DIACONIS, nil
+	case "edges":
+		return plots.BIN_EDGES, nil
+	default:
+		return 0, fmt.Errorf("Invalid bin strategy: %v", value)
+	}
+}
+
+func ParseEdges(value string) ([]float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	edges := make([]float64, 0)
+	for _, field := range strings.Split(value, ",") {
+		if edge, err := strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+			return nil, err
+		} else {
+			edges = append(edges, edge)
+		}
+	}
+	return edges, nil
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+	if *flagColumn == "" {
+		log.Println("Expected -column flag")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	filename := flag.Arg(0)
+	if err := table.ReadCSV(filename, false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+
+	strategy, err := ParseStrategy(*flagStrategy)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	edges, err := ParseEdges(*flagEdges)
+	if err != nil {
+		log.Println("Invalid -edges flag:", err)
+		return -1
+	}
+
+	out := *flagOut
+	if out == "" {
+		out = path.Base(filename) + "_" + *flagColumn + "_hist." + *flagFormat
+	}
+
+	if p, err := plots.Histogram(table, plots.HistogramOptions{
+		Column:   *flagColumn,
+		Group:    *flagGroup,
+		Strategy: strategy,
+		Bins:     *flagBins,
+		Edges:    edges,
+		Density:  *flagDensity,
+		KDE:      *flagKDE,
+	}); err != nil {
+		log.Println("Unable to create histogram:", err)
+		return -1
+	} else if err := plots.Save(p, vg.Length(*flagWidth)*vg.Inch, vg.Length(*flagHeight)*vg.Inch, out); err != nil {
+		log.Println("Unable to save histogram:", err)
+		return -1
+	} else {
+		fmt.Println("Written", out)
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
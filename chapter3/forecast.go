@@ -0,0 +1,194 @@
+// Usage:
+//  go run chapter3/forecast.go -model arima -order 1,1,0 -test 5 -column "Files Remaining" chapter1/data.csv
+//  go run chapter3/forecast.go -model holt -test 5 -acf 10 -column observation chapter3/time_series.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/forecast"
+	"github.com/djthorpe/MachineLearning/metrics"
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagColumn         = flag.String("column", "", "Column to forecast, or the first numeric column if empty")
+	flagModel          = flag.String("model", "ses", "Model (ma, ses, holt, hw, arima)")
+	flagTest           = flag.Uint("test", 10, "Number of values at the end of the series to forecast and evaluate")
+	flagLevel          = flag.Float64("level", 0.95, "Confidence level of the prediction intervals")
+	flagWindow         = flag.Uint("window", 3, "Window for the moving average model")
+	flagAlpha          = flag.Float64("alpha", 0, "Smoothing parameter for the level, or zero to estimate")
+	flagBeta           = flag.Float64("beta", 0, "Smoothing parameter for the trend, or zero to estimate")
+	flagGamma          = flag.Float64("gamma", 0, "Smoothing parameter for the season, or zero to estimate")
+	flagPeriod         = flag.Uint("period", 12, "Number of values in each season for the hw model")
+	flagMultiplicative = flag.Bool("multiplicative", false, "Use multiplicative seasonality for the hw model")
+	flagOrder          = flag.String("order", "1,0,0", "Order p,d,q for the arima model")
+	flagACF            = flag.Uint("acf", 0, "Output the autocorrelation and partial autocorrelation up to a number of lags")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func ParseOrder(value string) (uint, uint, uint, error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != 3 {
+		return 0, 0, 0, fmt.Errorf("Invalid order: %v", value)
+	}
+	order := make([]uint, 3)
+	for i, field := range fields {
+		if v, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32); err != nil {
+			return 0, 0, 0, fmt.Errorf("Invalid order: %v", value)
+		} else {
+			order[i] = uint(v)
+		}
+	}
+	return order[0], order[1], order[2], nil
+}
+
+func NewModel(name string) (forecast.Model, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "ma":
+		return &forecast.MovingAverage{Window: *flagWindow}, nil
+	case "ses":
+		return &forecast.SimpleExponentialSmoothing{Alpha: *flagAlpha}, nil
+	case "holt":
+		return &forecast.Holt{Alpha: *flagAlpha, Beta: *flagBeta}, nil
+	case "hw", "holt-winters":
+		return &forecast.HoltWinters{Alpha: *flagAlpha, Beta: *flagBeta, Gamma: *flagGamma, Period: *flagPeriod, Multiplicative: *flagMultiplicative}, nil
+	case "arima":
+		if p, d, q, err := ParseOrder(*flagOrder); err != nil {
+			return nil, err
+		} else {
+			return forecast.NewARIMA(p, d, q), nil
+		}
+	default:
+		return nil, fmt.Errorf("Invalid model: %v", name)
+	}
+}
+
+// Series returns the values in a column, ignoring missing values
+func Series(table *util.Table, column string) ([]float64, error) {
+	if column == "" {
+		if columns := table.NumericColumns(); len(columns) == 0 {
+			return nil, fmt.Errorf("No numeric columns")
+		} else {
+			column = columns[0]
+		}
+	}
+	values, err := table.FloatColumn(column, math.NaN())
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", column, err)
+	}
+	series := make([]float64, 0, len(values))
+	for _, value := range values {
+		if math.IsNaN(value) == false {
+			series = append(series, value)
+		}
+	}
+	return series, nil
+}
+
+// Correlations returns a table of the autocorrelation and partial
+// autocorrelation for each lag
+func Correlations(series []float64, lags uint) *util.Table {
+	table, _ := util.NewTable("Lag", "ACF", "PACF", "Significant")
+	acf, pacf := forecast.ACF(series, lags), forecast.PACF(series, lags)
+	bound := forecast.ConfidenceBound(len(series), *flagLevel)
+	for lag := uint(1); lag <= lags; lag++ {
+		significant := ""
+		if math.Abs(acf[lag]) > bound {
+			significant = "ACF"
+		}
+		if math.Abs(pacf[lag]) > bound {
+			significant = strings.TrimPrefix(significant+",PACF", ",")
+		}
+		table.AppendStringRow([]string{fmt.Sprint(lag), fmt.Sprintf("%0.3f", acf[lag]), fmt.Sprintf("%0.3f", pacf[lag]), significant}, false)
+	}
+	return table
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	series, err := Series(table, *flagColumn)
+	if err != nil {
+		log.Println(err)
+		return -1
+	} else if int(*flagTest) >= len(series) {
+		log.Println("Expected -test to be less than the number of values", len(series))
+		return -1
+	}
+
+	// Output the autocorrelations
+	if *flagACF > 0 {
+		fmt.Println(Correlations(series, *flagACF))
+	}
+
+	// Fit the model on the values before the test values
+	train, test := series[:len(series)-int(*flagTest)], series[len(series)-int(*flagTest):]
+	model, err := NewModel(*flagModel)
+	if err != nil {
+		log.Println(err)
+		return -1
+	} else if err := model.Fit(train); err != nil {
+		log.Println("Unable to fit model:", err)
+		return -1
+	} else {
+		fmt.Println(model)
+	}
+	if len(test) == 0 {
+		return 0
+	}
+
+	// Forecast the test values
+	result, err := model.Forecast(uint(len(test)), *flagLevel)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	output, _ := util.NewTable("Step", "Observed", "Forecast", "Lower", "Upper")
+	for i := range test {
+		output.AppendStringRow([]string{
+			fmt.Sprint(i + 1), fmt.Sprint(test[i]),
+			fmt.Sprintf("%0.2f", result.Mean[i]), fmt.Sprintf("%0.2f", result.Lower[i]), fmt.Sprintf("%0.2f", result.Upper[i]),
+		}, false)
+	}
+	fmt.Println(output)
+
+	// Evaluate the forecasts
+	if mae, err := metrics.MeanAbsoluteError(test, result.Mean); err != nil {
+		log.Println(err)
+		return -1
+	} else if mse, err := metrics.MeanSquaredError(test, result.Mean); err != nil {
+		log.Println(err)
+		return -1
+	} else {
+		fmt.Printf("MAE = %0.2f\n", mae)
+		fmt.Printf("MSE = %0.2f\n", mse)
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
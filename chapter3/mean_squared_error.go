@@ -1,5 +1,4 @@
 // Usage:
-//  go get -u gonum.org/v1/gonum/stat
 //  go run chapter3/mean_squared_error.go chapter3/time_series.csv
 package main
 
@@ -11,8 +10,8 @@ import (
 	"os"
 
 	// Utilities for reading data
+	"github.com/djthorpe/MachineLearning/metrics"
 	"github.com/djthorpe/MachineLearning/util"
-	"gonum.org/v1/gonum/stat"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -37,23 +36,20 @@ func RunMain() int {
 	} else if predicted, err := table.FloatColumn(table.Columns[1], math.NaN()); err != nil {
 		log.Println(err)
 		return -1
-	} else if len(observed) != len(predicted) {
-		log.Println("Observed and predicted samples mismatch")
+	} else if mae, err := metrics.MeanAbsoluteError(observed, predicted); err != nil {
+		log.Println(err)
+		return -1
+	} else if mse, err := metrics.MeanSquaredError(observed, predicted); err != nil {
+		log.Println(err)
+		return -1
+	} else if r2, err := metrics.RSquared(observed, predicted); err != nil {
+		log.Println(err)
 		return -1
 	} else {
-		var mAE float64
-		var mSE float64
-		for i := range observed {
-			mAE += math.Abs(observed[i]-predicted[i]) / float64(len(observed))
-			mSE += math.Pow(observed[i]-predicted[i], 2) / float64(len(observed))
-		}
-		// Output the MAE and MSE value to standard out.
-		fmt.Printf("MAE = %0.2f\n", mAE)
-		fmt.Printf("MSE = %0.2f\n", mSE)
-
-		// Calculate R squared
-		fmt.Printf("R^2 = %0.2f\n", stat.RSquaredFrom(observed, predicted, nil))
-
+		// Output the MAE, MSE and R squared values to standard out.
+		fmt.Printf("MAE = %0.2f\n", mae)
+		fmt.Printf("MSE = %0.2f\n", mse)
+		fmt.Printf("R^2 = %0.2f\n", r2)
 	}
 	return 0
 }
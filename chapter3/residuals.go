@@ -0,0 +1,76 @@
+// Usage:
+//  go get -u gonum.org/v1/plot/...
+//  go run chapter3/residuals.go chapter3/time_series.csv
+//  open time_series.csv_residuals.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	// Utilities for reading data
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagObserved  = flag.String("observed", "", "Name of the observed column, defaults to the first column")
+	flagPredicted = flag.String("predicted", "", "Name of the predicted column, defaults to the second column")
+	flagFormat    = flag.String("format", "png", "Output format (png, svg, pdf)")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	filename := flag.Arg(0)
+	if err := table.ReadCSV(filename, false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	if table.NumberOfColumns() < 2 {
+		log.Println("Expected observed and predicted columns")
+		return -1
+	}
+
+	observed, predicted := *flagObserved, *flagPredicted
+	if observed == "" {
+		observed = table.Columns[0]
+	}
+	if predicted == "" {
+		predicted = table.Columns[1]
+	}
+
+	// Plot residuals vs fitted, residual histogram, Q-Q plot and
+	// predicted vs observed
+	out := path.Base(filename) + "_residuals." + *flagFormat
+	if grid, err := plots.DiagnosticsForTable(table, observed, predicted); err != nil {
+		log.Println("Unable to create diagnostics:", err)
+		return -1
+	} else if err := plots.SaveGrid(grid, 8*vg.Inch, 8*vg.Inch, out); err != nil {
+		log.Println("Unable to save diagnostics:", err)
+		return -1
+	} else {
+		fmt.Println("Written", out)
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,115 @@
+// Usage:
+//  go get -u gonum.org/v1/plot/...
+//  go run chapter3/roc.go chapter3/scores.csv
+//  open scores.csv_roc.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	// Utilities for reading data
+	"github.com/djthorpe/MachineLearning/metrics"
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagObserved  = flag.String("observed", "", "Name of the observed label column, defaults to the first column")
+	flagScore     = flag.String("score", "", "Name of the predicted score column, defaults to the second column")
+	flagPositive  = flag.String("positive", "1", "Label of the positive class")
+	flagCriterion = flag.String("criterion", "youden", "Optimal threshold criterion (youden, f1)")
+	flagFormat    = flag.String("format", "png", "Output format (png, svg, pdf)")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func ParseCriterion(value string) (metrics.Criterion, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "youden", "j":
+		return metrics.YOUDEN_J, nil
+	case "f1":
+		return metrics.F1, nil
+	default:
+		return 0, fmt.Errorf("Invalid criterion: %v", value)
+	}
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	filename := flag.Arg(0)
+	if err := table.ReadCSV(filename, false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	if table.NumberOfColumns() < 2 {
+		log.Println("Expected observed and score columns")
+		return -1
+	}
+
+	observed, score := *flagObserved, *flagScore
+	if observed == "" {
+		observed = table.Columns[0]
+	}
+	if score == "" {
+		score = table.Columns[1]
+	}
+	criterion, err := ParseCriterion(*flagCriterion)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	// Sweep the thresholds and find the optimal one
+	curve, err := metrics.NewCurveFromTable(table, observed, score, *flagPositive)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	optimal, value, err := curve.OptimalThreshold(criterion)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	fmt.Printf("ROC AUC = %0.3f\n", curve.AUC())
+	fmt.Printf("Average precision = %0.3f\n", curve.AveragePrecision())
+	fmt.Printf("Optimal threshold = %0.4f (%v=%0.3f)\n", curve.Thresholds[optimal], criterion, value)
+	fmt.Printf("  tpr=%0.2f fpr=%0.2f precision=%0.2f\n", curve.TPR[optimal], curve.FPR[optimal], curve.Precision[optimal])
+
+	// Plot the ROC and precision-recall curves side by side
+	out := path.Base(filename) + "_roc." + *flagFormat
+	if roc, err := plots.ROCCurve(curve, optimal); err != nil {
+		log.Println(err)
+		return -1
+	} else if pr, err := plots.PrecisionRecallCurve(curve, optimal); err != nil {
+		log.Println(err)
+		return -1
+	} else if err := plots.SaveGrid(plots.Grid{[]*plot.Plot{roc, pr}}, 8*vg.Inch, 4*vg.Inch, out); err != nil {
+		log.Println("Unable to save curves:", err)
+		return -1
+	} else {
+		fmt.Println("Written", out)
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
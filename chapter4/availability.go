@@ -0,0 +1,384 @@
+// Usage:
+//  go run chapter4/availability.go -db citibike.sqlite -hours 3
+//  go run chapter4/availability.go -db citibike.sqlite -per_station -station 72,79
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/metrics"
+	"github.com/djthorpe/MachineLearning/regression"
+	"github.com/djthorpe/MachineLearning/stationdb"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Series is the number of bikes available at a station at each interval,
+// which is NaN before the first reading
+type Series struct {
+	StationId string
+	Values    []float64
+}
+
+// Samples are the features and targets for forecasting a number of
+// intervals ahead. Origin is the interval of the most recent value used
+// as a feature, and Last is that value
+type Samples struct {
+	Features [][]float64
+	Target   []float64
+	Origin   []int
+	Last     []float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	FlagDatabasePath = flag.String("db", "", "Path to the database")
+	FlagStation      = flag.String("station", "", "Comma-separated station identifiers, or all stations if empty")
+	FlagInterval     = flag.Duration("interval", 15*time.Minute, "Interval between samples, which should divide one hour")
+	FlagHours        = flag.Uint("hours", 3, "Number of hours ahead to forecast")
+	FlagLags         = flag.Uint("lags", 4, "Number of previous intervals to use as features")
+	FlagTest         = flag.Float64("test", 0.2, "Fraction of the history, at the end, used to evaluate the forecasts")
+	FlagLambda       = flag.Float64("lambda", 1, "Ridge penalty for the regression model")
+	FlagPerStation   = flag.Bool("per_station", false, "Train a model for each station rather than one for all stations")
+	FlagLocation     = flag.String("location", "America/New_York", "Time zone used for the time of day and day of week")
+)
+
+const (
+	// The query for every reading, with the time of the snapshot in
+	// which it was stored
+	HISTORY_QUERY = `SELECT r.station_id, s.last_updated, r.bikes_available, r.is_renting
+		FROM readings r JOIN snapshots s ON r.snapshot_id = s.snapshot_id
+		ORDER BY r.station_id, s.last_updated`
+	// The query for the times of the first and last snapshots
+	RANGE_QUERY = `SELECT MIN(last_updated), MAX(last_updated) FROM snapshots`
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ReadHistory reads the readings for all stations, or the stations
+// provided, into a table
+func ReadHistory(db *sql.DB, stations []string) (*util.Table, error) {
+	if len(stations) == 0 {
+		return util.ReadSQL(db, HISTORY_QUERY)
+	}
+	placeholders := make([]string, len(stations))
+	args := make([]interface{}, len(stations))
+	for i, station := range stations {
+		placeholders[i] = "?"
+		args[i] = station
+	}
+	query := strings.Replace(HISTORY_QUERY, "ORDER BY", "WHERE r.station_id IN ("+strings.Join(placeholders, ",")+") ORDER BY", 1)
+	return util.ReadSQL(db, query, args...)
+}
+
+// ReadRange returns the times of the first and last snapshots
+func ReadRange(db *sql.DB) (time.Time, time.Time, error) {
+	var first, last sql.NullInt64
+	if err := db.QueryRow(RANGE_QUERY).Scan(&first, &last); err != nil {
+		return time.Time{}, time.Time{}, err
+	} else if first.Valid == false || last.Valid == false {
+		return time.Time{}, time.Time{}, fmt.Errorf("No snapshots have been collected")
+	} else {
+		return time.Unix(first.Int64, 0), time.Unix(last.Int64, 0), nil
+	}
+}
+
+// Resample returns a series for each station with a value for every
+// interval between start and end. Readings are only stored when the status
+// changes, so each value is the most recent reading at or before the
+// interval. Stations which are not renting have no bikes available
+func Resample(table *util.Table, start time.Time, intervals int, interval time.Duration) ([]*Series, error) {
+	stations, err := table.StringColumn("station_id", "")
+	if err != nil {
+		return nil, err
+	}
+	updated, err := table.FloatColumn("last_updated", math.NaN())
+	if err != nil {
+		return nil, err
+	}
+	bikes, err := table.FloatColumn("bikes_available", math.NaN())
+	if err != nil {
+		return nil, err
+	}
+	renting, err := table.UintColumn("is_renting", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are ordered by station and then time
+	series := make([]*Series, 0)
+	var current *Series
+	for i := range stations {
+		if current == nil || current.StationId != stations[i] {
+			current = &Series{StationId: stations[i], Values: make([]float64, intervals)}
+			for j := range current.Values {
+				current.Values[j] = math.NaN()
+			}
+			series = append(series, current)
+		}
+		if math.IsNaN(updated[i]) || math.IsNaN(bikes[i]) {
+			continue
+		}
+		value := bikes[i]
+		if renting[i] == 0 {
+			value = 0
+		}
+
+		// Set the value from the interval at or after the reading until
+		// the end, which is overwritten by any later reading
+		next := int(math.Ceil(float64(time.Unix(int64(updated[i]), 0).Sub(start)) / float64(interval)))
+		for j := next; j < intervals; j++ {
+			current.Values[j] = value
+		}
+	}
+
+	// Return success
+	return series, nil
+}
+
+// Features returns the features for a forecast of the value at time t,
+// which are the time of day and day of week encoded as points on a circle
+// so that midnight is close to 23:59 and Sunday is close to Monday, a
+// weekend indicator, and the most recent values
+func Features(t time.Time, lags []float64) []float64 {
+	hour := float64(t.Hour()) + float64(t.Minute())/60
+	day := float64(t.Weekday()) + hour/24
+	weekend := 0.0
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		weekend = 1
+	}
+	features := []float64{
+		math.Sin(2 * math.Pi * hour / 24), math.Cos(2 * math.Pi * hour / 24),
+		math.Sin(2 * math.Pi * day / 7), math.Cos(2 * math.Pi * day / 7),
+		weekend,
+	}
+	return append(features, lags...)
+}
+
+// NewSamples returns the samples for forecasting a series steps intervals
+// ahead, for every interval where the lagged values and the target value
+// exist
+func NewSamples(series *Series, start time.Time, interval time.Duration, steps, lags int) *Samples {
+	samples := new(Samples)
+	for origin := lags - 1; origin+steps < len(series.Values); origin++ {
+		values := series.Values[origin-lags+1 : origin+1]
+		target := series.Values[origin+steps]
+		if math.IsNaN(target) || math.IsNaN(values[0]) {
+			continue
+		}
+		// Lagged values with the most recent first
+		lagged := make([]float64, lags)
+		for i := range lagged {
+			lagged[i] = values[lags-1-i]
+		}
+		t := start.Add(interval * time.Duration(origin+steps))
+		samples.Features = append(samples.Features, Features(t, lagged))
+		samples.Target = append(samples.Target, target)
+		samples.Origin = append(samples.Origin, origin)
+		samples.Last = append(samples.Last, lagged[0])
+	}
+	return samples
+}
+
+// Split returns the samples where the target is before the cutoff for
+// training, and the samples where the origin is at or after the cutoff for
+// testing, so that no test values are used in training
+func (this *Samples) Split(cutoff, steps int) (*Samples, *Samples) {
+	train, test := new(Samples), new(Samples)
+	for i, origin := range this.Origin {
+		if origin+steps < cutoff {
+			train.Append(this, i)
+		} else if origin >= cutoff {
+			test.Append(this, i)
+		}
+	}
+	return train, test
+}
+
+// Append appends a sample from other
+func (this *Samples) Append(other *Samples, i int) {
+	this.Features = append(this.Features, other.Features[i])
+	this.Target = append(this.Target, other.Target[i])
+	this.Origin = append(this.Origin, other.Origin[i])
+	this.Last = append(this.Last, other.Last[i])
+}
+
+// Matrix returns the features as a matrix with one row for each sample
+func (this *Samples) Matrix() *mat.Dense {
+	if len(this.Features) == 0 {
+		return nil
+	}
+	x := mat.NewDense(len(this.Features), len(this.Features[0]), nil)
+	for i, row := range this.Features {
+		x.SetRow(i, row)
+	}
+	return x
+}
+
+// Forecast trains a model on the training samples and returns the
+// predictions for the test samples
+func Forecast(train, test *Samples) ([]float64, error) {
+	if len(train.Target) == 0 || len(test.Target) == 0 {
+		return nil, fmt.Errorf("Not enough history to train and test the model")
+	}
+	model := regression.NewLinear(*FlagLambda)
+	if err := model.Fit(train.Matrix(), train.Target); err != nil {
+		return nil, err
+	} else if predicted, err := model.Predict(test.Matrix()); err != nil {
+		return nil, err
+	} else {
+		// The number of bikes cannot be negative
+		for i := range predicted {
+			predicted[i] = math.Max(predicted[i], 0)
+		}
+		return predicted, nil
+	}
+}
+
+// Evaluate appends rows to the results table with the errors of the model
+// and of a persistence forecast, which predicts that the number of bikes
+// does not change
+func Evaluate(results *util.Table, hours uint, observed, predicted, last []float64) error {
+	for _, forecast := range []struct {
+		name      string
+		predicted []float64
+	}{{"Regression", predicted}, {"Persistence", last}} {
+		if mae, err := metrics.MeanAbsoluteError(observed, forecast.predicted); err != nil {
+			return err
+		} else if rmse, err := metrics.RootMeanSquaredError(observed, forecast.predicted); err != nil {
+			return err
+		} else if r2, err := metrics.RSquared(observed, forecast.predicted); err != nil {
+			return err
+		} else if err := results.AppendStringRow([]string{
+			fmt.Sprint(hours), forecast.name, fmt.Sprint(len(observed)),
+			fmt.Sprintf("%0.2f", mae), fmt.Sprintf("%0.2f", rmse), fmt.Sprintf("%0.2f", r2),
+		}, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func RunMain() int {
+	if *FlagDatabasePath == "" {
+		log.Println("Expected -db flag")
+		return -1
+	}
+	if *FlagInterval <= 0 || time.Hour%*FlagInterval != 0 {
+		log.Println("Expected -interval to divide one hour")
+		return -1
+	}
+	if *FlagHours == 0 || *FlagLags == 0 {
+		log.Println("Expected -hours and -lags to be greater than zero")
+		return -1
+	}
+	if *FlagTest <= 0 || *FlagTest >= 1 {
+		log.Println("Expected -test to be between zero and one")
+		return -1
+	}
+	location, err := time.LoadLocation(*FlagLocation)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	stations := make([]string, 0)
+	for _, station := range strings.Split(*FlagStation, ",") {
+		if station = strings.TrimSpace(station); station != "" {
+			stations = append(stations, station)
+		}
+	}
+
+	db, err := stationdb.Open(*FlagDatabasePath)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	defer db.Close()
+
+	// Read and resample the history
+	start, end, err := ReadRange(db.SQL())
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	start = start.In(location).Truncate(*FlagInterval)
+	intervals := int(end.Sub(start)/(*FlagInterval)) + 1
+	table, err := ReadHistory(db.SQL(), stations)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	series, err := Resample(table, start, intervals, *FlagInterval)
+	if err != nil {
+		log.Println(err)
+		return -1
+	} else if len(series) == 0 {
+		log.Println("No readings for the stations")
+		return -1
+	}
+	fmt.Printf("%v stations from %v to %v\n", len(series), start.Format(time.RFC1123), end.In(location).Format(time.RFC1123))
+
+	// Train and evaluate a model for each number of hours ahead
+	cutoff := int(float64(intervals) * (1 - *FlagTest))
+	results, _ := util.NewTable("Hours", "Model", "Samples", "MAE", "RMSE", "R^2")
+	for hours := uint(1); hours <= *FlagHours; hours++ {
+		steps := int(time.Duration(hours) * time.Hour / *FlagInterval)
+		var observed, predicted, last []float64
+		if *FlagPerStation {
+			for _, s := range series {
+				samples := NewSamples(s, start, *FlagInterval, steps, int(*FlagLags))
+				train, test := samples.Split(cutoff, steps)
+				if forecast, err := Forecast(train, test); err != nil {
+					log.Println(s.StationId, ":", err)
+					continue
+				} else {
+					observed = append(observed, test.Target...)
+					predicted = append(predicted, forecast...)
+					last = append(last, test.Last...)
+				}
+			}
+		} else {
+			all := new(Samples)
+			for _, s := range series {
+				samples := NewSamples(s, start, *FlagInterval, steps, int(*FlagLags))
+				for i := range samples.Target {
+					all.Append(samples, i)
+				}
+			}
+			train, test := all.Split(cutoff, steps)
+			if forecast, err := Forecast(train, test); err != nil {
+				log.Println(err)
+				return -1
+			} else {
+				observed, predicted, last = test.Target, forecast, test.Last
+			}
+		}
+		if err := Evaluate(results, hours, observed, predicted, last); err != nil {
+			log.Println(hours, "hours:", err)
+			return -1
+		}
+	}
+
+	fmt.Println(results)
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -1,5 +1,7 @@
 // Usage:
-//  go run chapter4/gradient_descent.go chapter4/advertising.csv
+//  go run chapter4/gradient_descent.go -diagnostics chapter4/advertising.csv
+//  go run chapter4/gradient_descent.go -save advertising.model chapter4/advertising.csv
+//  go run chapter4/gradient_descent.go -load advertising.model chapter4/advertising.csv
 package main
 
 import (
@@ -10,8 +12,12 @@ import (
 	"math"
 	"os"
 	"path"
+	"strconv"
 
 	// Frameworks
+	"github.com/djthorpe/MachineLearning/persist"
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/regression"
 	"github.com/djthorpe/MachineLearning/util"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -22,6 +28,14 @@ const (
 	LEARNING_RATE = 0.001
 )
 
+var (
+	flagEpochs      = flag.Uint("epochs", 1000, "Number of training epochs")
+	flagVerbose     = flag.Bool("verbose", false, "Print coefficients and error for every epoch")
+	flagDiagnostics = flag.Bool("diagnostics", false, "Write loss curve and residual plots")
+	flagSave        = flag.String("save", "", "Save the trained coefficients to a file")
+	flagLoad        = flag.String("load", "", "Load the coefficients from a file instead of training")
+)
+
 ///////////////////////////////////////////////////////////////////////////////
 
 func RunMain() int {
@@ -29,6 +43,10 @@ func RunMain() int {
 		log.Println("Expected file argument", flag.NArg())
 		return -1
 	}
+	if *flagEpochs == 0 {
+		log.Println("Expected -epochs to be greater than zero")
+		return -1
+	}
 
 	table, _ := util.NewTable()
 	filename := flag.Arg(0)
@@ -56,10 +74,32 @@ func RunMain() int {
 		plot.X.Label.Text = x_column
 		plot.Y.Label.Text = y_column
 
+		var b, m float64
+		var history []float64
+		if *flagLoad != "" {
+			var load_err error
+			if b, m, load_err = load_model(*flagLoad, x_column, y_column); load_err != nil {
+				log.Println("Unable to load model:", load_err)
+				return -1
+			}
+			history = []float64{calculate_error(x_data, y_data, b, m)}
+		} else {
+			b, m, history = gradient_descent(x_data, y_data, LEARNING_RATE, *flagEpochs)
+		}
+		fmt.Println("b=", b, "m=", m, "err=", history[len(history)-1])
+
+		// Save the coefficients
+		if *flagSave != "" {
+			if err := save_model(*flagSave, x_column, y_column, b, m); err != nil {
+				log.Println("Unable to save model:", err)
+				return -1
+			}
+		}
+
 		if scatter, err := plotter.NewScatter(plot_points(x_data, y_data)); err != nil {
 			log.Println("Unable to create plot:", err)
 			return -1
-		} else if line, err := plotter.NewLine(line_points(x_data, y_data)); err != nil {
+		} else if line, err := plotter.NewLine(line_points(x_data, b, m)); err != nil {
 			log.Println("Unable to create plot:", err)
 			return -1
 		} else {
@@ -70,12 +110,63 @@ func RunMain() int {
 				return -1
 			}
 		}
+
+		// Write the training diagnostics
+		if *flagDiagnostics {
+			if err := write_diagnostics(path.Base(filename)+"_"+x_column+"_"+y_column, x_data, y_data, b, m, history); err != nil {
+				log.Println("Unable to create diagnostics:", err)
+				return -1
+			}
+		}
 	}
-	//
 
 	return 0
 }
 
+// Save the coefficients as a linear model, with the columns and training
+// parameters as properties
+func save_model(filename, x_column, y_column string, b, m float64) error {
+	model := &regression.Linear{Intercept: b, Coefficients: []float64{m}}
+	return persist.Save(filename, model, map[string]string{
+		"x":             x_column,
+		"y":             y_column,
+		"epochs":        fmt.Sprint(*flagEpochs),
+		"learning_rate": strconv.FormatFloat(LEARNING_RATE, 'g', -1, 64),
+	})
+}
+
+// Load the coefficients from a linear model, checking that the model was
+// trained on the same columns
+func load_model(filename, x_column, y_column string) (float64, float64, error) {
+	model := new(regression.Linear)
+	if metadata, err := persist.Load(filename, model); err != nil {
+		return 0, 0, err
+	} else if metadata.Properties["x"] != x_column || metadata.Properties["y"] != y_column {
+		return 0, 0, fmt.Errorf("Model was trained on %v and %v", metadata.Properties["x"], metadata.Properties["y"])
+	} else if len(model.Coefficients) != 1 {
+		return 0, 0, fmt.Errorf("Expected one coefficient")
+	}
+	return model.Intercept, model.Coefficients[0], nil
+}
+
+// Write the loss curve and residual plots
+func write_diagnostics(prefix string, x, y []float64, b, m float64, history []float64) error {
+	predicted := make([]float64, len(x))
+	for i := range x {
+		predicted[i] = m*x[i] + b
+	}
+	if loss, err := plots.LossCurve(history); err != nil {
+		return err
+	} else if err := plots.Save(loss, 4*vg.Inch, 4*vg.Inch, prefix+"_loss.png"); err != nil {
+		return err
+	} else if grid, err := plots.Diagnostics(y, predicted); err != nil {
+		return err
+	} else if err := plots.SaveGrid(grid, 8*vg.Inch, 8*vg.Inch, prefix+"_residuals.png"); err != nil {
+		return err
+	}
+	return nil
+}
+
 // Return plot points
 func plot_points(x, y []float64) plotter.XYs {
 	pts := make(plotter.XYs, len(x))
@@ -87,8 +178,7 @@ func plot_points(x, y []float64) plotter.XYs {
 }
 
 // Return line points
-func line_points(x, y []float64) plotter.XYs {
-	b, m := gradient_descent(x, y, LEARNING_RATE, 1000)
+func line_points(x []float64, b, m float64) plotter.XYs {
 	pts := make(plotter.XYs, len(x))
 	for i := range pts {
 		pts[i].X = x[i]
@@ -117,15 +207,19 @@ func step(x, y []float64, b, m float64, rate float64) (float64, float64) {
 	return b - (rate * b_gradient), m - (rate * m_gradient)
 }
 
-// Return values of b and m
-func gradient_descent(x, y []float64, rate float64, epochs uint) (float64, float64) {
+// Return values of b and m, and the error for each epoch
+func gradient_descent(x, y []float64, rate float64, epochs uint) (float64, float64, []float64) {
 	var b, m float64
+	history := make([]float64, 0, epochs)
 	for i := uint(0); i < epochs; i++ {
 		b, m = step(x, y, b, m, rate)
-		fmt.Println("epoch=", i, "b=", b, "m=", m, "err=", calculate_error(x, y, b, m))
-
+		err := calculate_error(x, y, b, m)
+		history = append(history, err)
+		if *flagVerbose {
+			fmt.Println("epoch=", i, "b=", b, "m=", m, "err=", err)
+		}
 	}
-	return b, m
+	return b, m, history
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,84 @@
+// Usage:
+//  go run chapter4/pairplot.go chapter4/advertising.csv
+//  go run chapter4/pairplot.go -label Name chapter2/iris.csv
+//  open iris.csv_pairplot.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagColumns = flag.String("columns", "", "Comma-separated columns to plot, defaults to all numeric columns")
+	flagLabel   = flag.String("label", "", "Optional categorical column used to colour points")
+	flagBins    = flag.Uint("bins", 0, "Number of histogram bins, defaults to Sturges' formula")
+	flagFormat  = flag.String("format", "png", "Output format (png, svg, pdf)")
+	flagOut     = flag.String("out", "", "Output filename, overrides -format")
+	flagSize    = flag.Float64("size", 2.5, "Size of each plot in inches")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	filename := flag.Arg(0)
+	if err := table.ReadCSV(filename, false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+
+	var columns []string
+	if *flagColumns != "" {
+		for _, column := range strings.Split(*flagColumns, ",") {
+			columns = append(columns, strings.TrimSpace(column))
+		}
+	}
+
+	out := *flagOut
+	if out == "" {
+		out = path.Base(filename) + "_pairplot." + *flagFormat
+	}
+
+	if grid, err := plots.PairPlot(table, plots.PairPlotOptions{
+		Columns: columns,
+		Label:   *flagLabel,
+		Bins:    *flagBins,
+	}); err != nil {
+		log.Println("Unable to create pair plot:", err)
+		return -1
+	} else {
+		size := vg.Length(*flagSize) * vg.Inch
+		if err := plots.SaveGrid(grid, size*vg.Length(len(grid)), size*vg.Length(len(grid)), out); err != nil {
+			log.Println("Unable to save pair plot:", err)
+			return -1
+		} else {
+			fmt.Println("Written", out)
+		}
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,240 @@
+// Usage:
+//  go run chapter5/ensemble.go chapter2/iris.csv
+//  go run chapter5/ensemble.go -model boosting -validation 0.2 -loss iris_loss.png chapter2/iris.csv
+//  go run chapter5/ensemble.go -model boosting -target PetalWidth -regression chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/ensemble"
+	"github.com/djthorpe/MachineLearning/metrics"
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/tree"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagModel        = flag.String("model", "forest", "Ensemble model (forest, boosting)")
+	flagTarget       = flag.String("target", "", "Column to predict, defaults to the last column")
+	flagFeatures     = flag.String("features", "", "Comma-separated feature columns, defaults to all other columns")
+	flagRegression   = flag.Bool("regression", false, "Predict a numeric target rather than a label")
+	flagTrees        = flag.Uint("trees", ensemble.DEFAULT_TREES, "Number of trees in a forest")
+	flagWorkers      = flag.Uint("workers", 0, "Number of trees grown in parallel, defaults to the number of CPUs")
+	flagRounds       = flag.Uint("rounds", ensemble.DEFAULT_ROUNDS, "Maximum number of boosting rounds")
+	flagLearningRate = flag.Float64("learning_rate", ensemble.DEFAULT_LEARNING_RATE, "Shrinkage for each boosting round")
+	flagSubsample    = flag.Float64("subsample", 1, "Fraction of rows used in each boosting round")
+	flagValidation   = flag.Float64("validation", 0, "Fraction of training rows held back to stop boosting early")
+	flagPatience     = flag.Uint("patience", ensemble.DEFAULT_PATIENCE, "Boosting rounds without improvement before stopping")
+	flagMaxDepth     = flag.Uint("max_depth", 0, "Maximum depth of each tree")
+	flagMaxFeatures  = flag.Uint("max_features", 0, "Number of candidate features for each split")
+	flagLoss         = flag.String("loss", "", "Write the boosting loss curve to a file")
+	flagTest         = flag.Float64("test", 0.2, "Fraction of rows held back for testing")
+	flagSeed         = flag.Int64("seed", 1, "Seed used to shuffle the rows")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+type Model interface {
+	Features() []string
+}
+
+type Forest interface {
+	Importances() []float64
+	OOBError() float64
+}
+
+type Boosting interface {
+	Loss() ([]float64, []float64)
+}
+
+type Classifier interface {
+	Model
+	Fit(table *util.Table, features []string, target string) error
+	Predict(table *util.Table) ([]string, error)
+}
+
+type Regressor interface {
+	Model
+	Fit(table *util.Table, features []string, target string) error
+	Predict(table *util.Table) ([]float64, error)
+}
+
+func Features(table *util.Table, target string) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.Columns {
+			if column != target {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+func Classify(model Classifier, train, test *util.Table, features []string, target string) error {
+	if err := model.Fit(train, features, target); err != nil {
+		return err
+	}
+	observed, err := test.StringColumn(target, "")
+	if err != nil {
+		return err
+	}
+	predicted, err := model.Predict(test)
+	if err != nil {
+		return err
+	}
+	correct := 0
+	for i := range observed {
+		if observed[i] == predicted[i] {
+			correct++
+		}
+	}
+	fmt.Println(model)
+	fmt.Printf("Accuracy: %.2f%% (%v of %v)\n", 100*float64(correct)/float64(len(observed)), correct, len(observed))
+	return Output(model)
+}
+
+func Regress(model Regressor, train, test *util.Table, features []string, target string) error {
+	if err := model.Fit(train, features, target); err != nil {
+		return err
+	}
+	observed, err := test.FloatColumn(target, 0)
+	if err != nil {
+		return err
+	}
+	predicted, err := model.Predict(test)
+	if err != nil {
+		return err
+	}
+	fmt.Println(model)
+	if mae, err := metrics.MeanAbsoluteError(observed, predicted); err != nil {
+		return err
+	} else if rmse, err := metrics.RootMeanSquaredError(observed, predicted); err != nil {
+		return err
+	} else if r2, err := metrics.RSquared(observed, predicted); err != nil {
+		return err
+	} else {
+		fmt.Printf("MAE=%.4f RMSE=%.4f R2=%.4f (%v samples)\n", mae, rmse, r2, len(observed))
+	}
+	return Output(model)
+}
+
+// Output writes the out-of-bag error and importances for a forest, and
+// the loss curve for boosting
+func Output(model Model) error {
+	if forest, ok := model.(Forest); ok {
+		fmt.Printf("Out-of-bag error: %.4f\n", forest.OOBError())
+		fmt.Println()
+		importances := forest.Importances()
+		for i, feature := range model.Features() {
+			fmt.Printf("  %-20s importance=%.4f\n", feature, importances[i])
+		}
+	}
+	if boosting, ok := model.(Boosting); ok && *flagLoss != "" {
+		train, validation := boosting.Loss()
+		p, err := plots.LossCurve(train)
+		if err != nil {
+			return err
+		}
+		p.X.Label.Text = "Round"
+		if len(validation) > 0 {
+			pts := make(plotter.XYs, len(validation))
+			for i, loss := range validation {
+				pts[i].X, pts[i].Y = float64(i), loss
+			}
+			if line, err := plotter.NewLine(pts); err != nil {
+				return err
+			} else {
+				line.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+				p.Add(line)
+				p.Legend.Add("validation", line)
+			}
+		}
+		if err := plots.Save(p, 4*vg.Inch, 4*vg.Inch, *flagLoss); err != nil {
+			return err
+		}
+		fmt.Println("Written", *flagLoss)
+	}
+	return nil
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+
+	target := *flagTarget
+	if target == "" {
+		target = table.Columns[len(table.Columns)-1]
+	}
+	features := Features(table, target)
+	config := tree.Config{MaxDepth: *flagMaxDepth, MaxFeatures: *flagMaxFeatures}
+
+	// Hold back rows for testing
+	train, test, err := table.Split(1-*flagTest, *flagSeed)
+	if err != nil {
+		log.Println("Unable to split rows:", err)
+		return -1
+	}
+
+	switch strings.ToLower(*flagModel) {
+	case "forest":
+		config := ensemble.ForestConfig{Trees: *flagTrees, Tree: config, Workers: *flagWorkers, Seed: *flagSeed}
+		if *flagRegression {
+			err = Regress(ensemble.NewForestRegressor(config), train, test, features, target)
+		} else {
+			err = Classify(ensemble.NewForestClassifier(config), train, test, features, target)
+		}
+	case "boosting":
+		config := ensemble.BoostingConfig{
+			Rounds:       *flagRounds,
+			LearningRate: *flagLearningRate,
+			Subsample:    *flagSubsample,
+			Tree:         config,
+			Validation:   *flagValidation,
+			Patience:     *flagPatience,
+			Seed:         *flagSeed,
+		}
+		if *flagRegression {
+			err = Regress(ensemble.NewBoostingRegressor(config), train, test, features, target)
+		} else {
+			err = Classify(ensemble.NewBoostingClassifier(config), train, test, features, target)
+		}
+	default:
+		err = fmt.Errorf("Invalid model: %v", *flagModel)
+	}
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,250 @@
+// Usage:
+//  go run chapter5/knn.go chapter2/iris.csv
+//  go run chapter5/knn.go -k 3 -metric manhattan -weights distance -index kdtree chapter2/iris.csv
+//  go run chapter5/knn.go -scale standard chapter2/iris.csv
+//  go run chapter5/knn.go -target PetalWidth -regression chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/knn"
+	"github.com/djthorpe/MachineLearning/metrics"
+	"github.com/djthorpe/MachineLearning/pipeline"
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagTarget     = flag.String("target", "", "Column to predict, defaults to the last column")
+	flagFeatures   = flag.String("features", "", "Comma-separated feature columns, defaults to all other numeric columns")
+	flagRegression = flag.Bool("regression", false, "Predict a numeric target rather than a label")
+	flagK          = flag.Uint("k", knn.DEFAULT_K, "Number of neighbours")
+	flagMetric     = flag.String("metric", "euclidean", "Distance metric (euclidean, manhattan, minkowski, cosine)")
+	flagP          = flag.Float64("p", 3, "Power for the minkowski distance")
+	flagWeights    = flag.String("weights", "uniform", "Neighbour weights (uniform, distance)")
+	flagIndex      = flag.String("index", "brute", "Neighbour index (brute, kdtree, balltree)")
+	flagScale      = flag.String("scale", "", "Scale the features using the training rows (standard, minmax)")
+	flagTest       = flag.Float64("test", 0.2, "Fraction of rows held back for testing")
+	flagSeed       = flag.Int64("seed", 1, "Seed used to shuffle the rows")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func ParseMetric(value string) (knn.Metric, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "euclidean":
+		return knn.METRIC_EUCLIDEAN, nil
+	case "manhattan":
+		return knn.METRIC_MANHATTAN, nil
+	case "minkowski":
+		return knn.METRIC_MINKOWSKI, nil
+	case "cosine":
+		return knn.METRIC_COSINE, nil
+	default:
+		return 0, fmt.Errorf("Invalid metric: %v", value)
+	}
+}
+
+func ParseWeights(value string) (knn.Weights, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "uniform":
+		return knn.WEIGHTS_UNIFORM, nil
+	case "distance":
+		return knn.WEIGHTS_DISTANCE, nil
+	default:
+		return 0, fmt.Errorf("Invalid weights: %v", value)
+	}
+}
+
+func ParseIndex(value string) (knn.Index, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "brute":
+		return knn.INDEX_BRUTE, nil
+	case "kdtree":
+		return knn.INDEX_KDTREE, nil
+	case "balltree":
+		return knn.INDEX_BALLTREE, nil
+	default:
+		return 0, fmt.Errorf("Invalid index: %v", value)
+	}
+}
+
+// Transformers returns a scaler for numeric features if the scale flag is
+// set, and a one-hot encoder for categorical features
+func Transformers() ([]pipeline.Transformer, error) {
+	encoder := pipeline.NewOneHotEncoder(pipeline.EncoderConfig{})
+	switch strings.ToLower(strings.TrimSpace(*flagScale)) {
+	case "":
+		return []pipeline.Transformer{encoder}, nil
+	case "standard":
+		return []pipeline.Transformer{pipeline.NewScaler(pipeline.ScalerConfig{Scaling: pipeline.SCALING_STANDARD}), encoder}, nil
+	case "minmax":
+		return []pipeline.Transformer{pipeline.NewScaler(pipeline.ScalerConfig{Scaling: pipeline.SCALING_MINMAX}), encoder}, nil
+	default:
+		return nil, fmt.Errorf("Invalid scaling: %v", *flagScale)
+	}
+}
+
+func Features(table *util.Table, target string) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.NumericColumns() {
+			if column != target {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+func Classify(config knn.Config, transformers []pipeline.Transformer, train, test *util.Table, features []string, target string) error {
+	classifier := knn.NewClassifier(config)
+	model := pipeline.NewPipeline(pipeline.NewClassifier(classifier), transformers...)
+	if err := model.Fit(train, features, target); err != nil {
+		return err
+	}
+
+	observed, err := test.StringColumn(target, "")
+	if err != nil {
+		return err
+	}
+	predicted, err := model.Predict(test)
+	if err != nil {
+		return err
+	}
+
+	// Report the accuracy for each class
+	correct := 0
+	for i := range observed {
+		if observed[i] == predicted[i] {
+			correct++
+		}
+	}
+	fmt.Println(model)
+	fmt.Printf("Accuracy: %.2f%% (%v of %v)\n", 100*float64(correct)/float64(len(observed)), correct, len(observed))
+	for _, class := range classifier.Classes() {
+		var tp, fp, fn int
+		for i := range observed {
+			switch {
+			case observed[i] == class && predicted[i] == class:
+				tp++
+			case predicted[i] == class:
+				fp++
+			case observed[i] == class:
+				fn++
+			}
+		}
+		precision, recall := float64(tp)/float64(tp+fp), float64(tp)/float64(tp+fn)
+		fmt.Printf("  %-20s precision=%.2f recall=%.2f\n", class, precision, recall)
+	}
+	return nil
+}
+
+func Regress(config knn.Config, transformers []pipeline.Transformer, train, test *util.Table, features []string, target string) error {
+	model := pipeline.NewRegressionPipeline(pipeline.NewRegressor(knn.NewRegressor(config)), transformers...)
+	if err := model.Fit(train, features, target); err != nil {
+		return err
+	}
+
+	observed, err := test.FloatColumn(target, 0)
+	if err != nil {
+		return err
+	}
+	predicted, err := model.Predict(test)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(model)
+	if mae, err := metrics.MeanAbsoluteError(observed, predicted); err != nil {
+		return err
+	} else if rmse, err := metrics.RootMeanSquaredError(observed, predicted); err != nil {
+		return err
+	} else if r2, err := metrics.RSquared(observed, predicted); err != nil {
+		return err
+	} else {
+		fmt.Printf("MAE=%.4f RMSE=%.4f R2=%.4f (%v samples)\n", mae, rmse, r2, len(observed))
+	}
+	return nil
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+
+	target := *flagTarget
+	if target == "" {
+		target = table.Columns[len(table.Columns)-1]
+	}
+	features := Features(table, target)
+	if len(features) == 0 {
+		log.Println("Expected at least one feature column")
+		return -1
+	}
+
+	config := knn.Config{K: *flagK, P: *flagP}
+	if metric, err := ParseMetric(*flagMetric); err != nil {
+		log.Println(err)
+		return -1
+	} else if weights, err := ParseWeights(*flagWeights); err != nil {
+		log.Println(err)
+		return -1
+	} else if index, err := ParseIndex(*flagIndex); err != nil {
+		log.Println(err)
+		return -1
+	} else {
+		config.Metric, config.Weights, config.Index = metric, weights, index
+	}
+
+	transformers, err := Transformers()
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	// Hold back rows for testing, which are transformed using the
+	// statistics of the training rows
+	train, test, err := table.Split(1-*flagTest, *flagSeed)
+	if err != nil {
+		log.Println("Unable to split rows:", err)
+		return -1
+	}
+
+	if *flagRegression {
+		err = Regress(config, transformers, train, test, features, target)
+	} else {
+		err = Classify(config, transformers, train, test, features, target)
+	}
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,175 @@
+// Usage:
+//  go run chapter5/naive_bayes.go chapter2/iris.csv
+//  go run chapter5/naive_bayes.go -batch 20 chapter2/iris.csv
+//  go run chapter5/naive_bayes.go -model bernoulli -target observed chapter3/labeled.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/bayes"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagModel        = flag.String("model", "gaussian", "Naive Bayes model (gaussian, multinomial, bernoulli)")
+	flagTarget       = flag.String("target", "", "Column to predict, defaults to the last column")
+	flagFeatures     = flag.String("features", "", "Comma-separated feature columns, defaults to all other numeric columns")
+	flagAlpha        = flag.Float64("alpha", bayes.DEFAULT_ALPHA, "Additive smoothing for multinomial and bernoulli models")
+	flagBinarize     = flag.Float64("binarize", 0, "Threshold above which a feature is one for the bernoulli model")
+	flagVarSmoothing = flag.Float64("var_smoothing", bayes.DEFAULT_VAR_SMOOTHING, "Proportion of the largest variance added to all variances for the gaussian model")
+	flagBatch        = flag.Uint("batch", 0, "Fit the training rows incrementally in batches of this size")
+	flagTest         = flag.Float64("test", 0.2, "Fraction of rows held back for testing")
+	flagSeed         = flag.Int64("seed", 1, "Seed used to shuffle the rows")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+type Classifier interface {
+	Fit(table *util.Table, features []string, target string) error
+	PartialFit(table *util.Table, features []string, target string) error
+	Classes() []string
+	Predict(table *util.Table) ([]string, error)
+	PredictProba(table *util.Table) (*mat.Dense, error)
+}
+
+func NewClassifier(model string, config bayes.Config) (Classifier, error) {
+	switch strings.ToLower(strings.TrimSpace(model)) {
+	case "gaussian":
+		return bayes.NewGaussian(config), nil
+	case "multinomial":
+		return bayes.NewMultinomial(config), nil
+	case "bernoulli":
+		return bayes.NewBernoulli(config), nil
+	default:
+		return nil, fmt.Errorf("Invalid model: %v", model)
+	}
+}
+
+func Features(table *util.Table, target string) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.NumericColumns() {
+			if column != target {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+// Fit fits the classifier to all the rows at once, or one batch at a
+// time to show how rows can be streamed into the classifier
+func Fit(classifier Classifier, train *util.Table, features []string, target string) error {
+	if *flagBatch == 0 {
+		return classifier.Fit(train, features, target)
+	}
+	batch := int(*flagBatch)
+	for start := 0; start < len(train.Rows); start += batch {
+		rows := make([]int, 0, batch)
+		for i := start; i < start+batch && i < len(train.Rows); i++ {
+			rows = append(rows, i)
+		}
+		if sample, err := train.Subsample(rows); err != nil {
+			return err
+		} else if err := classifier.PartialFit(sample, features, target); err != nil {
+			return err
+		} else {
+			fmt.Printf("Fitted rows %v to %v, classes=%v\n", start, start+len(rows)-1, classifier.Classes())
+		}
+	}
+	return nil
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+
+	target := *flagTarget
+	if target == "" {
+		target = table.Columns[len(table.Columns)-1]
+	}
+	features := Features(table, target)
+	classifier, err := NewClassifier(*flagModel, bayes.Config{
+		Alpha:        *flagAlpha,
+		Binarize:     *flagBinarize,
+		VarSmoothing: *flagVarSmoothing,
+	})
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	// Hold back rows for testing
+	train, test, err := table.Split(1-*flagTest, *flagSeed)
+	if err != nil {
+		log.Println("Unable to split rows:", err)
+		return -1
+	}
+	if err := Fit(classifier, train, features, target); err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	observed, err := test.StringColumn(target, "")
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	proba, err := classifier.PredictProba(test)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	predicted, err := classifier.Predict(test)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	// Report the accuracy and the mean probability of the observed label
+	correct, classes := 0, classifier.Classes()
+	var likelihood float64
+	for i := range observed {
+		if observed[i] == predicted[i] {
+			correct++
+		}
+		for c, class := range classes {
+			if class == observed[i] {
+				likelihood += proba.At(i, c) / float64(len(observed))
+			}
+		}
+	}
+	fmt.Println(classifier)
+	fmt.Printf("Accuracy: %.2f%% (%v of %v)\n", 100*float64(correct)/float64(len(observed)), correct, len(observed))
+	fmt.Printf("Mean probability of observed label: %.4f\n", likelihood)
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,197 @@
+// Usage:
+//  go run chapter5/svm.go chapter2/iris.csv
+//  go run chapter5/svm.go -kernel polynomial -degree 2 -c 10 -probability chapter2/iris.csv
+//  go run chapter5/svm.go -solver pegasos -epochs 200 chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/pipeline"
+	"github.com/djthorpe/MachineLearning/svm"
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagTarget      = flag.String("target", "", "Column to predict, defaults to the last column")
+	flagFeatures    = flag.String("features", "", "Comma-separated feature columns, defaults to all other numeric columns")
+	flagSolver      = flag.String("solver", "smo", "Solver (smo, pegasos)")
+	flagKernel      = flag.String("kernel", "rbf", "Kernel for the smo solver (rbf, linear, polynomial, sigmoid)")
+	flagC           = flag.Float64("c", svm.DEFAULT_C, "Penalty for samples inside the margin")
+	flagGamma       = flag.Float64("gamma", 0, "Kernel gamma, defaults to one divided by the number of features")
+	flagDegree      = flag.Uint("degree", svm.DEFAULT_DEGREE, "Degree of the polynomial kernel")
+	flagCoef0       = flag.Float64("coef0", 0, "Constant term of the polynomial and sigmoid kernels")
+	flagEpochs      = flag.Uint("epochs", svm.DEFAULT_EPOCHS, "Number of epochs for the pegasos solver")
+	flagProbability = flag.Bool("probability", false, "Calibrate and output probabilities")
+	flagScale       = flag.Bool("scale", true, "Standardise the features using the training rows")
+	flagTest        = flag.Float64("test", 0.2, "Fraction of rows held back for testing")
+	flagSeed        = flag.Int64("seed", 1, "Seed used to shuffle the rows")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func ParseKernel(value string) (svm.Kernel, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "rbf":
+		return svm.KERNEL_RBF, nil
+	case "linear":
+		return svm.KERNEL_LINEAR, nil
+	case "polynomial", "poly":
+		return svm.KERNEL_POLYNOMIAL, nil
+	case "sigmoid":
+		return svm.KERNEL_SIGMOID, nil
+	default:
+		return 0, fmt.Errorf("Invalid kernel: %v", value)
+	}
+}
+
+func Features(table *util.Table, target string) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.NumericColumns() {
+			if column != target {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+
+	target := *flagTarget
+	if target == "" {
+		target = table.Columns[len(table.Columns)-1]
+	}
+	features := Features(table, target)
+	if len(features) == 0 {
+		log.Println("Expected at least one feature column")
+		return -1
+	}
+
+	config := svm.Config{
+		C:           *flagC,
+		Gamma:       *flagGamma,
+		Degree:      *flagDegree,
+		Coef0:       *flagCoef0,
+		Probability: *flagProbability,
+		Seed:        *flagSeed,
+	}
+	var classifier *svm.Classifier
+	switch strings.ToLower(*flagSolver) {
+	case "smo":
+		if kernel, err := ParseKernel(*flagKernel); err != nil {
+			log.Println(err)
+			return -1
+		} else {
+			config.Kernel = kernel
+			classifier = svm.NewKernel(config)
+		}
+	case "pegasos":
+		config.MaxIter = *flagEpochs
+		classifier = svm.NewLinear(config)
+	default:
+		log.Println("Invalid solver:", *flagSolver)
+		return -1
+	}
+
+	// Hold back rows for testing
+	train, test, err := table.Split(1-*flagTest, *flagSeed)
+	if err != nil {
+		log.Println("Unable to split rows:", err)
+		return -1
+	}
+
+	// Scale both sets of rows with the statistics of the training rows
+	if *flagScale {
+		scaler := pipeline.NewScaler(pipeline.ScalerConfig{Scaling: pipeline.SCALING_STANDARD})
+		if err := scaler.Fit(train, features); err != nil {
+			log.Println(err)
+			return -1
+		} else if train, err = scaler.Transform(train); err != nil {
+			log.Println(err)
+			return -1
+		} else if test, err = scaler.Transform(test); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	train_x, err := train.Matrix(features...)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	test_x, err := test.Matrix(features...)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	train_y, _ := train.StringColumn(target, "")
+	observed, _ := test.StringColumn(target, "")
+
+	if err := classifier.Fit(train_x, train_y); err != nil {
+		log.Println(err)
+		return -1
+	}
+	predicted, err := classifier.Predict(test_x)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	correct := 0
+	for i := range observed {
+		if observed[i] == predicted[i] {
+			correct++
+		}
+	}
+	fmt.Println(classifier)
+	fmt.Printf("Accuracy: %.2f%% (%v of %v)\n", 100*float64(correct)/float64(len(observed)), correct, len(observed))
+
+	// Output the mean probability of the observed label
+	if *flagProbability {
+		proba, err := classifier.PredictProba(test_x)
+		if err != nil {
+			log.Println(err)
+			return -1
+		}
+		var likelihood float64
+		for i := range observed {
+			for c, class := range classifier.Classes() {
+				if class == observed[i] {
+					likelihood += proba.At(i, c) / float64(len(observed))
+				}
+			}
+		}
+		fmt.Printf("Mean probability of observed label: %.4f\n", likelihood)
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,217 @@
+// Usage:
+//  go run chapter5/tree.go chapter2/iris.csv
+//  go run chapter5/tree.go -criterion entropy -max_depth 3 -dot iris.dot chapter2/iris.csv
+//  dot -Tpng -o iris.png iris.dot
+//  go run chapter5/tree.go -target PetalWidth -regression -alpha 0.001 chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/metrics"
+	"github.com/djthorpe/MachineLearning/tree"
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagTarget     = flag.String("target", "", "Column to predict, defaults to the last column")
+	flagFeatures   = flag.String("features", "", "Comma-separated feature columns, defaults to all other columns")
+	flagRegression = flag.Bool("regression", false, "Predict a numeric target rather than a label")
+	flagCriterion  = flag.String("criterion", "", "Split criterion (gini, entropy, mse), defaults to gini or mse")
+	flagMaxDepth   = flag.Uint("max_depth", 0, "Maximum depth of the tree, or zero for no limit")
+	flagMinSplit   = flag.Uint("min_split", tree.DEFAULT_MIN_SAMPLES_SPLIT, "Minimum number of samples to split a node")
+	flagMinLeaf    = flag.Uint("min_leaf", tree.DEFAULT_MIN_SAMPLES_LEAF, "Minimum number of samples in a leaf")
+	flagAlpha      = flag.Float64("alpha", 0, "Cost-complexity pruning parameter")
+	flagAlphas     = flag.Bool("alphas", false, "Output the effective alphas for pruning")
+	flagDOT        = flag.String("dot", "", "Write the tree in Graphviz DOT format to a file")
+	flagTest       = flag.Float64("test", 0.2, "Fraction of rows held back for testing")
+	flagSeed       = flag.Int64("seed", 1, "Seed used to shuffle the rows")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+type Tree interface {
+	Features() []string
+	Importances() []float64
+	Alphas() []float64
+	Text() string
+	WriteDOT(w io.Writer) error
+}
+
+func ParseCriterion(value string) (tree.Criterion, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return tree.CRITERION_DEFAULT, nil
+	case "gini":
+		return tree.CRITERION_GINI, nil
+	case "entropy":
+		return tree.CRITERION_ENTROPY, nil
+	case "mse":
+		return tree.CRITERION_MSE, nil
+	default:
+		return 0, fmt.Errorf("Invalid criterion: %v", value)
+	}
+}
+
+func Features(table *util.Table, target string) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.Columns {
+			if column != target {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+func Classify(config tree.Config, train, test *util.Table, features []string, target string) error {
+	classifier := tree.NewClassifier(config)
+	if err := classifier.Fit(train, features, target); err != nil {
+		return err
+	}
+	observed, err := test.StringColumn(target, "")
+	if err != nil {
+		return err
+	}
+	predicted, err := classifier.Predict(test)
+	if err != nil {
+		return err
+	}
+
+	correct := 0
+	for i := range observed {
+		if observed[i] == predicted[i] {
+			correct++
+		}
+	}
+	fmt.Println(classifier)
+	fmt.Printf("Accuracy: %.2f%% (%v of %v)\n", 100*float64(correct)/float64(len(observed)), correct, len(observed))
+	return Output(classifier)
+}
+
+func Regress(config tree.Config, train, test *util.Table, features []string, target string) error {
+	regressor := tree.NewRegressor(config)
+	if err := regressor.Fit(train, features, target); err != nil {
+		return err
+	}
+	observed, err := test.FloatColumn(target, 0)
+	if err != nil {
+		return err
+	}
+	predicted, err := regressor.Predict(test)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(regressor)
+	if mae, err := metrics.MeanAbsoluteError(observed, predicted); err != nil {
+		return err
+	} else if rmse, err := metrics.RootMeanSquaredError(observed, predicted); err != nil {
+		return err
+	} else if r2, err := metrics.RSquared(observed, predicted); err != nil {
+		return err
+	} else {
+		fmt.Printf("MAE=%.4f RMSE=%.4f R2=%.4f (%v samples)\n", mae, rmse, r2, len(observed))
+	}
+	return Output(regressor)
+}
+
+// Output writes the importances, the rules and the DOT file for a tree
+func Output(model Tree) error {
+	fmt.Println()
+	importances := model.Importances()
+	for i, feature := range model.Features() {
+		fmt.Printf("  %-20s importance=%.4f\n", feature, importances[i])
+	}
+	if *flagAlphas {
+		fmt.Println()
+		fmt.Println("Alphas:", model.Alphas())
+	}
+	fmt.Println()
+	fmt.Print(model.Text())
+
+	if *flagDOT != "" {
+		if fh, err := os.Create(*flagDOT); err != nil {
+			return err
+		} else {
+			defer fh.Close()
+			if err := model.WriteDOT(fh); err != nil {
+				return err
+			}
+			fmt.Println()
+			fmt.Println("Written", *flagDOT)
+		}
+	}
+	return nil
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+
+	target := *flagTarget
+	if target == "" {
+		target = table.Columns[len(table.Columns)-1]
+	}
+	features := Features(table, target)
+
+	config := tree.Config{
+		MaxDepth:        *flagMaxDepth,
+		MinSamplesSplit: *flagMinSplit,
+		MinSamplesLeaf:  *flagMinLeaf,
+		Alpha:           *flagAlpha,
+	}
+	if criterion, err := ParseCriterion(*flagCriterion); err != nil {
+		log.Println(err)
+		return -1
+	} else {
+		config.Criterion = criterion
+	}
+
+	// Hold back rows for testing
+	train, test, err := table.Split(1-*flagTest, *flagSeed)
+	if err != nil {
+		log.Println("Unable to split rows:", err)
+		return -1
+	}
+
+	if *flagRegression {
+		err = Regress(config, train, test, features, target)
+	} else {
+		err = Classify(config, train, test, features, target)
+	}
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
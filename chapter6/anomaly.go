@@ -0,0 +1,190 @@
+// Usage:
+//  go run chapter6/anomaly.go -model iqr -columns "Files Remaining" chapter1/data.csv
+//  go run chapter6/anomaly.go -model residual -forecast holt -columns "Files Remaining" chapter1/data.csv
+//  go run chapter6/anomaly.go -model iforest -contamination 0.05 -label Name chapter2/iris.csv
+//  go run chapter6/anomaly.go -model lof -k 10 -out iris_anomalies.csv chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/anomaly"
+	"github.com/djthorpe/MachineLearning/forecast"
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagColumns       = flag.String("columns", "", "Comma-separated columns to score, defaults to all numeric columns")
+	flagLabel         = flag.String("label", "", "Column which is not scored when columns are not set")
+	flagModel         = flag.String("model", "zscore", "Model (zscore, iqr, iforest, lof, residual)")
+	flagThreshold     = flag.Float64("threshold", 0, "Score above which a row is flagged, or zero for the default")
+	flagRobust        = flag.Bool("robust", false, "Use the median and median absolute deviation for zscore")
+	flagContamination = flag.Float64("contamination", 0, "Expected fraction of anomalies for iforest and lof")
+	flagTrees         = flag.Uint("trees", anomaly.DEFAULT_TREES, "Number of trees for iforest")
+	flagSample        = flag.Uint("sample", anomaly.DEFAULT_SAMPLE_SIZE, "Number of rows sampled for each tree for iforest")
+	flagK             = flag.Uint("k", anomaly.DEFAULT_NEIGHBOURS, "Number of neighbours for lof")
+	flagForecast      = flag.String("forecast", "holt", "Forecasting model for residual (ses, holt, arima)")
+	flagOrder         = flag.String("order", "1,1,0", "Order p,d,q for the arima forecasting model")
+	flagScore         = flag.String("score", "Score", "Name of the column appended with the score of each row")
+	flagFlag          = flag.String("flag", "Anomaly", "Name of the column appended with the flag of each row")
+	flagOut           = flag.String("out", "", "Write the rows with the score and flag columns appended to a CSV file")
+	flagSeed          = flag.Int64("seed", 1, "Seed used to build the trees for iforest")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func Columns(table *util.Table) []string {
+	columns := make([]string, 0)
+	if *flagColumns != "" {
+		for _, column := range strings.Split(*flagColumns, ",") {
+			columns = append(columns, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.NumericColumns() {
+			if column != *flagLabel {
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
+
+func NewForecast(name string) (forecast.Model, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "ses":
+		return &forecast.SimpleExponentialSmoothing{}, nil
+	case "holt":
+		return &forecast.Holt{}, nil
+	case "arima":
+		fields := strings.Split(*flagOrder, ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("Invalid order: %v", *flagOrder)
+		}
+		order := make([]uint, 3)
+		for i, field := range fields {
+			if v, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32); err != nil {
+				return nil, fmt.Errorf("Invalid order: %v", *flagOrder)
+			} else {
+				order[i] = uint(v)
+			}
+		}
+		return forecast.NewARIMA(order[0], order[1], order[2]), nil
+	default:
+		return nil, fmt.Errorf("Invalid forecasting model: %v", name)
+	}
+}
+
+// Fit returns a fitted detector for the columns of the table
+func Fit(table *util.Table, columns []string) (anomaly.Detector, error) {
+	if strings.ToLower(*flagModel) == "residual" {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("Expected one column for the residual model")
+		}
+		model, err := NewForecast(*flagForecast)
+		if err != nil {
+			return nil, err
+		}
+		series, err := table.FloatColumn(columns[0], math.NaN())
+		if err != nil {
+			return nil, err
+		}
+		detector := anomaly.NewResidual(model, anomaly.ResidualConfig{Threshold: *flagThreshold})
+		return detector, detector.Fit(series)
+	}
+
+	x, err := table.Matrix(columns...)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(*flagModel) {
+	case "zscore":
+		detector := anomaly.NewZScore(anomaly.ZScoreConfig{Threshold: *flagThreshold, Robust: *flagRobust})
+		return detector, detector.Fit(x)
+	case "iqr":
+		detector := anomaly.NewIQR(anomaly.IQRConfig{K: *flagThreshold})
+		return detector, detector.Fit(x)
+	case "iforest":
+		detector := anomaly.NewIsolationForest(anomaly.IsolationForestConfig{Trees: *flagTrees, SampleSize: *flagSample, Contamination: *flagContamination, Seed: *flagSeed})
+		return detector, detector.Fit(x)
+	case "lof":
+		detector := anomaly.NewLOF(anomaly.LOFConfig{K: *flagK, Threshold: *flagThreshold, Contamination: *flagContamination})
+		return detector, detector.Fit(x)
+	default:
+		return nil, fmt.Errorf("Invalid model: %v", *flagModel)
+	}
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	if err := table.RemoveThousandsSeparator(",", table.Columns...); err != nil {
+		log.Println(err)
+		return -1
+	}
+	columns := Columns(table)
+	if len(columns) == 0 {
+		log.Println("Expected at least one numeric column")
+		return -1
+	}
+
+	detector, err := Fit(table, columns)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	fmt.Println(detector)
+
+	// Append the score and flag columns, and output the flagged rows
+	if err := anomaly.Append(table, detector, *flagScore, *flagFlag); err != nil {
+		log.Println(err)
+		return -1
+	}
+	flagged := make([]int, 0)
+	for i, flag := range anomaly.Flags(detector) {
+		if flag {
+			flagged = append(flagged, i)
+		}
+	}
+	if len(flagged) > 0 {
+		if rows, err := table.Subsample(flagged); err != nil {
+			log.Println(err)
+			return -1
+		} else {
+			fmt.Println(rows)
+		}
+	}
+	fmt.Printf("Flagged %v of %v rows\n", len(flagged), len(table.Rows))
+
+	if *flagOut != "" {
+		if err := table.WriteCSV(*flagOut); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
@@ -0,0 +1,140 @@
+// Usage:
+//  go run chapter6/dbscan.go -epsilon 0.5 -min_samples 5 -label Name chapter2/iris.csv
+//  go run chapter6/dbscan.go -model hdbscan -min_cluster_size 10 -label Name chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/cluster"
+	"github.com/djthorpe/MachineLearning/pipeline"
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagFeatures       = flag.String("features", "", "Comma-separated feature columns, defaults to all numeric columns")
+	flagModel          = flag.String("model", "dbscan", "Model (dbscan, hdbscan)")
+	flagEpsilon        = flag.Float64("epsilon", 0.5, "Radius of the neighbourhood of a row for dbscan")
+	flagMinSamples     = flag.Uint("min_samples", cluster.DEFAULT_MIN_SAMPLES, "Number of rows in the neighbourhood of a core row")
+	flagMinClusterSize = flag.Uint("min_cluster_size", cluster.DEFAULT_MIN_CLUSTER_SIZE, "Smallest number of rows in a cluster for hdbscan")
+	flagLabel          = flag.String("label", "", "Column of known labels to compare with the clusters")
+	flagColumn         = flag.String("column", "Cluster", "Name of the column appended with the cluster of each row")
+	flagOut            = flag.String("out", "", "Write the rows with the cluster column appended to a CSV file")
+	flagScale          = flag.Bool("scale", false, "Standardise the features before clustering")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func Features(table *util.Table) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.NumericColumns() {
+			if column != *flagLabel {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	features := Features(table)
+	if len(features) == 0 {
+		log.Println("Expected at least one feature column")
+		return -1
+	}
+	scaled := table
+	if *flagScale {
+		scaler := pipeline.NewScaler(pipeline.ScalerConfig{Scaling: pipeline.SCALING_STANDARD})
+		if err := scaler.Fit(table, features); err != nil {
+			log.Println(err)
+			return -1
+		} else if scaled, err = scaler.Transform(table); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	x, err := scaled.Matrix(features...)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	var clusters []int
+	switch strings.ToLower(*flagModel) {
+	case "dbscan":
+		model := cluster.NewDBSCAN(cluster.DBSCANConfig{Epsilon: *flagEpsilon, MinSamples: *flagMinSamples})
+		if err := model.Fit(x); err != nil {
+			log.Println(err)
+			return -1
+		}
+		clusters = model.Clusters()
+		fmt.Println(model)
+	case "hdbscan":
+		model := cluster.NewHDBSCAN(cluster.HDBSCANConfig{MinClusterSize: *flagMinClusterSize, MinSamples: *flagMinSamples})
+		if err := model.Fit(x); err != nil {
+			log.Println(err)
+			return -1
+		}
+		clusters = model.Clusters()
+		fmt.Println(model)
+	default:
+		log.Println("Invalid model:", *flagModel)
+		return -1
+	}
+
+	if silhouette, _, err := cluster.Silhouette(x, clusters); err == nil {
+		fmt.Printf("Silhouette: %.4f (excluding noise)\n", silhouette)
+	}
+
+	// Append the cluster column, which is empty for noise, and compare
+	// with known labels
+	if err := cluster.AppendClusters(table, *flagColumn, clusters); err != nil {
+		log.Println(err)
+		return -1
+	}
+	if *flagLabel != "" {
+		if crosstab, err := cluster.Crosstab(table, *flagColumn, *flagLabel); err != nil {
+			log.Println(err)
+			return -1
+		} else {
+			fmt.Println(crosstab)
+		}
+	}
+	if *flagOut != "" {
+		if err := table.WriteCSV(*flagOut); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
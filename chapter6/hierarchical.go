@@ -0,0 +1,169 @@
+// Usage:
+//  go run chapter6/hierarchical.go -k 3 -label Name chapter2/iris.csv
+//  go run chapter6/hierarchical.go -linkage average -threshold 1.5 -dendrogram iris_dendrogram.png chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/cluster"
+	"github.com/djthorpe/MachineLearning/pipeline"
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagFeatures   = flag.String("features", "", "Comma-separated feature columns, defaults to all numeric columns")
+	flagLinkage    = flag.String("linkage", "ward", "Linkage between clusters (ward, single, complete, average)")
+	flagK          = flag.Uint("k", 3, "Number of clusters")
+	flagThreshold  = flag.Float64("threshold", 0, "Distance above which clusters are not merged, instead of -k")
+	flagLabel      = flag.String("label", "", "Column of known labels to compare with the clusters")
+	flagColumn     = flag.String("column", "Cluster", "Name of the column appended with the cluster of each row")
+	flagOut        = flag.String("out", "", "Write the rows with the cluster column appended to a CSV file")
+	flagDendrogram = flag.String("dendrogram", "", "Write a dendrogram to a PNG, SVG or PDF file")
+	flagScale      = flag.Bool("scale", false, "Standardise the features before clustering")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func ParseLinkage(value string) (cluster.Linkage, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "ward":
+		return cluster.LINKAGE_WARD, nil
+	case "single":
+		return cluster.LINKAGE_SINGLE, nil
+	case "complete":
+		return cluster.LINKAGE_COMPLETE, nil
+	case "average":
+		return cluster.LINKAGE_AVERAGE, nil
+	default:
+		return 0, fmt.Errorf("Invalid linkage: %v", value)
+	}
+}
+
+func Features(table *util.Table) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.NumericColumns() {
+			if column != *flagLabel {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	features := Features(table)
+	if len(features) == 0 {
+		log.Println("Expected at least one feature column")
+		return -1
+	}
+	scaled := table
+	if *flagScale {
+		scaler := pipeline.NewScaler(pipeline.ScalerConfig{Scaling: pipeline.SCALING_STANDARD})
+		if err := scaler.Fit(table, features); err != nil {
+			log.Println(err)
+			return -1
+		} else if scaled, err = scaler.Transform(table); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	x, err := scaled.Matrix(features...)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	linkage, err := ParseLinkage(*flagLinkage)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	model := cluster.NewAgglomerative(cluster.AgglomerativeConfig{
+		Linkage:   linkage,
+		K:         *flagK,
+		Threshold: *flagThreshold,
+	})
+	if err := model.Fit(x); err != nil {
+		log.Println(err)
+		return -1
+	}
+	clusters := model.Clusters()
+	fmt.Println(model)
+
+	// Output the distances of the last merges, which show where to cut
+	merges := model.Merges()
+	last, _ := util.NewTable("Clusters", "Distance", "Size")
+	for i := len(merges) - 1; i >= 0 && i >= len(merges)-10; i-- {
+		last.AppendStringRow([]string{fmt.Sprint(len(merges) - i), fmt.Sprintf("%.4f", merges[i].Distance), fmt.Sprint(merges[i].Size)}, false)
+	}
+	fmt.Println(last)
+
+	if silhouette, _, err := cluster.Silhouette(x, clusters); err == nil {
+		fmt.Printf("Silhouette: %.4f\n", silhouette)
+	}
+
+	// Append the cluster column and compare with known labels
+	if err := cluster.AppendClusters(table, *flagColumn, clusters); err != nil {
+		log.Println(err)
+		return -1
+	}
+	if *flagLabel != "" {
+		if crosstab, err := cluster.Crosstab(table, *flagColumn, *flagLabel); err != nil {
+			log.Println(err)
+			return -1
+		} else {
+			fmt.Println(crosstab)
+		}
+	}
+	if *flagOut != "" {
+		if err := table.WriteCSV(*flagOut); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	if *flagDendrogram != "" {
+		p, err := plots.Dendrogram(model, plots.DendrogramOptions{Threshold: *flagThreshold})
+		if err != nil {
+			log.Println(err)
+			return -1
+		}
+		if err := plots.Save(p, 8*vg.Inch, 4*vg.Inch, *flagDendrogram); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
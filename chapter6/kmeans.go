@@ -0,0 +1,210 @@
+// Usage:
+//  go run chapter6/kmeans.go -k 3 chapter2/iris.csv
+//  go run chapter6/kmeans.go -elbow 2-8 chapter2/iris.csv
+//  go run chapter6/kmeans.go -k 3 -batch 30 -label Name -out iris_clusters.csv chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/cluster"
+	"github.com/djthorpe/MachineLearning/pipeline"
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagFeatures = flag.String("features", "", "Comma-separated feature columns, defaults to all numeric columns")
+	flagK        = flag.Uint("k", 3, "Number of clusters")
+	flagRestarts = flag.Uint("restarts", cluster.DEFAULT_RESTARTS, "Number of runs with different initial centroids")
+	flagMaxIter  = flag.Uint("iterations", cluster.DEFAULT_MAX_ITER, "Maximum number of iterations for each run")
+	flagBatch    = flag.Uint("batch", 0, "Mini-batch size, or zero to use all rows in each iteration")
+	flagElbow    = flag.String("elbow", "", "Range of k to compare by inertia and silhouette, for example 2-10")
+	flagLabel    = flag.String("label", "", "Column of known labels to compare with the clusters")
+	flagColumn   = flag.String("column", "Cluster", "Name of the column appended with the cluster of each row")
+	flagOut      = flag.String("out", "", "Write the rows with the cluster column appended to a CSV file")
+	flagPlot     = flag.String("plot", "", "Write a pair plot coloured by cluster to a PNG, SVG or PDF file")
+	flagScale    = flag.Bool("scale", false, "Standardise the features before clustering")
+	flagSeed     = flag.Int64("seed", 1, "Seed used to choose the initial centroids")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func Features(table *util.Table) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.NumericColumns() {
+			if column != *flagLabel {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+// ParseRange returns the values in a range such as 2-10
+func ParseRange(value string) ([]uint, error) {
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("Invalid range: %v", value)
+	}
+	lo, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid range: %v", value)
+	}
+	hi, err := strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 32)
+	if err != nil || hi < lo || lo < 2 {
+		return nil, fmt.Errorf("Invalid range: %v", value)
+	}
+	k := make([]uint, 0, hi-lo+1)
+	for i := lo; i <= hi; i++ {
+		k = append(k, uint(i))
+	}
+	return k, nil
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	features := Features(table)
+	if len(features) == 0 {
+		log.Println("Expected at least one feature column")
+		return -1
+	}
+	// Cluster on standardised features, but keep the original values in
+	// the table for the output and the plot
+	scaled := table
+	if *flagScale {
+		scaler := pipeline.NewScaler(pipeline.ScalerConfig{Scaling: pipeline.SCALING_STANDARD})
+		if err := scaler.Fit(table, features); err != nil {
+			log.Println(err)
+			return -1
+		} else if scaled, err = scaler.Transform(table); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	x, err := scaled.Matrix(features...)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	config := cluster.KMeansConfig{
+		K:         *flagK,
+		Restarts:  *flagRestarts,
+		MaxIter:   *flagMaxIter,
+		BatchSize: *flagBatch,
+		Seed:      *flagSeed,
+	}
+
+	// Compare the inertia and silhouette over a range of k
+	if *flagElbow != "" {
+		k, err := ParseRange(*flagElbow)
+		if err != nil {
+			log.Println(err)
+			return -1
+		}
+		points, err := cluster.Elbow(x, config, k...)
+		if err != nil {
+			log.Println(err)
+			return -1
+		}
+		elbow, _ := util.NewTable("K", "Inertia", "Silhouette")
+		for _, point := range points {
+			elbow.AppendStringRow([]string{fmt.Sprint(point.K), fmt.Sprintf("%.4f", point.Inertia), fmt.Sprintf("%.4f", point.Silhouette)}, false)
+		}
+		fmt.Println(elbow)
+		return 0
+	}
+
+	model := cluster.NewKMeans(config)
+	if err := model.Fit(x); err != nil {
+		log.Println(err)
+		return -1
+	}
+	clusters, err := model.Predict(x)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	fmt.Println(model)
+
+	// Output the centroids
+	centroids, _ := util.NewTable(append([]string{*flagColumn}, features...)...)
+	rows, _ := model.Centroids().Dims()
+	for i := 0; i < rows; i++ {
+		row := []string{fmt.Sprint(i)}
+		for _, value := range model.Centroids().RawRowView(i) {
+			row = append(row, fmt.Sprintf("%.4f", value))
+		}
+		centroids.AppendStringRow(row, false)
+	}
+	fmt.Println(centroids)
+
+	if silhouette, _, err := cluster.Silhouette(x, clusters); err == nil {
+		fmt.Printf("Silhouette: %.4f\n", silhouette)
+	}
+
+	// Append the cluster column and compare with known labels
+	if err := cluster.AppendClusters(table, *flagColumn, clusters); err != nil {
+		log.Println(err)
+		return -1
+	}
+	if *flagLabel != "" {
+		if crosstab, err := cluster.Crosstab(table, *flagColumn, *flagLabel); err != nil {
+			log.Println(err)
+			return -1
+		} else {
+			fmt.Println(crosstab)
+		}
+	}
+	if *flagOut != "" {
+		if err := table.WriteCSV(*flagOut); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	if *flagPlot != "" {
+		grid, err := plots.PairPlot(table, plots.PairPlotOptions{Columns: features, Label: *flagColumn})
+		if err != nil {
+			log.Println(err)
+			return -1
+		}
+		size := vg.Length(len(features)) * 2 * vg.Inch
+		if err := plots.SaveGrid(grid, size, size, *flagPlot); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
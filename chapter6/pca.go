@@ -0,0 +1,186 @@
+// Usage:
+//  go run chapter6/pca.go -scale chapter2/iris.csv
+//  go run chapter6/pca.go -scale -components 2 -biplot iris_biplot.png -label Name chapter2/iris.csv
+//  go run chapter6/pca.go -model svd -components 2 -out iris_scores.csv chapter2/iris.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/decomposition"
+	"github.com/djthorpe/MachineLearning/pipeline"
+	"github.com/djthorpe/MachineLearning/plots"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	flagFeatures   = flag.String("features", "", "Comma-separated feature columns, defaults to all numeric columns")
+	flagModel      = flag.String("model", "pca", "Model (pca, svd)")
+	flagComponents = flag.Uint("components", 0, "Number of components, defaults to all components for pca and two for svd")
+	flagVariance   = flag.Float64("variance", 0, "Keep enough components to explain this fraction of the variance for pca")
+	flagWhiten     = flag.Bool("whiten", false, "Scale the components to unit variance for pca")
+	flagLabel      = flag.String("label", "", "Column of labels used to colour the biplot")
+	flagOut        = flag.String("out", "", "Write the component scores to a CSV file")
+	flagBiplot     = flag.String("biplot", "", "Write a biplot to a PNG, SVG or PDF file")
+	flagScale      = flag.Bool("scale", false, "Standardise the features before the decomposition")
+	flagSeed       = flag.Int64("seed", 1, "Seed for the random directions for svd")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Model is a decomposition with components
+type Model interface {
+	Fit(x mat.Matrix) error
+	Transform(x mat.Matrix) (*mat.Dense, error)
+	InverseTransform(scores mat.Matrix) (*mat.Dense, error)
+	Components() *mat.Dense
+	ExplainedVarianceRatio() []float64
+}
+
+func Features(table *util.Table) []string {
+	features := make([]string, 0)
+	if *flagFeatures != "" {
+		for _, column := range strings.Split(*flagFeatures, ",") {
+			features = append(features, strings.TrimSpace(column))
+		}
+	} else {
+		for _, column := range table.NumericColumns() {
+			if column != *flagLabel {
+				features = append(features, column)
+			}
+		}
+	}
+	return features
+}
+
+// squares returns the square of each element of a matrix
+func squares(a *mat.Dense) *mat.Dense {
+	var result mat.Dense
+	result.MulElem(a, a)
+	return &result
+}
+
+func RunMain() int {
+	if flag.NArg() != 1 {
+		log.Println("Expected file argument")
+		return -1
+	}
+
+	table, _ := util.NewTable()
+	if err := table.ReadCSV(flag.Arg(0), false, true, true); err != nil {
+		log.Println("Unable to read CSV:", err)
+		return -1
+	}
+	features := Features(table)
+	if len(features) == 0 {
+		log.Println("Expected at least one feature column")
+		return -1
+	}
+	if *flagScale {
+		scaler := pipeline.NewScaler(pipeline.ScalerConfig{Scaling: pipeline.SCALING_STANDARD})
+		if err := scaler.Fit(table, features); err != nil {
+			log.Println(err)
+			return -1
+		} else if table, err = scaler.Transform(table); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	x, err := table.Matrix(features...)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+
+	var model Model
+	switch strings.ToLower(*flagModel) {
+	case "pca":
+		model = decomposition.NewPCA(decomposition.PCAConfig{Components: *flagComponents, Variance: *flagVariance, Whiten: *flagWhiten})
+	case "svd":
+		model = decomposition.NewTruncatedSVD(decomposition.SVDConfig{Components: *flagComponents, Seed: *flagSeed})
+	default:
+		log.Println("Invalid model:", *flagModel)
+		return -1
+	}
+	if err := model.Fit(x); err != nil {
+		log.Println(err)
+		return -1
+	}
+	fmt.Println(model)
+
+	// Output the loadings and the variance explained by each component
+	loadings, _ := util.NewTable(append([]string{"Component", "Ratio", "Cumulative"}, features...)...)
+	components := model.Components()
+	var cumulative float64
+	for i, ratio := range model.ExplainedVarianceRatio() {
+		cumulative += ratio
+		row := []string{fmt.Sprint("PC", i+1), fmt.Sprintf("%.4f", ratio), fmt.Sprintf("%.4f", cumulative)}
+		for _, value := range components.RawRowView(i) {
+			row = append(row, fmt.Sprintf("%.4f", value))
+		}
+		loadings.AppendStringRow(row, false)
+	}
+	fmt.Println(loadings)
+
+	// Output the mean squared error of the reconstructed features
+	scores, err := model.Transform(x)
+	if err != nil {
+		log.Println(err)
+		return -1
+	}
+	if reconstructed, err := model.InverseTransform(scores); err != nil {
+		log.Println(err)
+		return -1
+	} else {
+		var diff mat.Dense
+		diff.Sub(x, reconstructed)
+		rows, cols := diff.Dims()
+		fmt.Printf("Reconstruction error: %.6f\n", mat.Sum(squares(&diff))/float64(rows*cols))
+	}
+
+	if *flagOut != "" {
+		if result, err := decomposition.Scores(model, table, features, "PC"); err != nil {
+			log.Println(err)
+			return -1
+		} else if err := result.WriteCSV(*flagOut); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+	if *flagBiplot != "" {
+		var labels []string
+		if *flagLabel != "" {
+			if labels, err = table.StringColumn(*flagLabel, ""); err != nil {
+				log.Println(err)
+				return -1
+			}
+		}
+		p, err := plots.Biplot(scores, components, plots.BiplotOptions{Features: features, Labels: labels})
+		if err != nil {
+			log.Println(err)
+			return -1
+		}
+		if err := plots.Save(p, 6*vg.Inch, 6*vg.Inch, *flagBiplot); err != nil {
+			log.Println(err)
+			return -1
+		}
+	}
+
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	flag.Parse()
+	os.Exit(RunMain())
+}
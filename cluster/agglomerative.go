@@ -0,0 +1,263 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Linkage determines the distance between two clusters from the distances
+// between their samples
+type Linkage int
+
+// AgglomerativeConfig is the configuration for agglomerative clustering
+type AgglomerativeConfig struct {
+	// Linkage between clusters
+	Linkage Linkage
+
+	// K is the number of clusters returned by Clusters, unless Threshold
+	// is set, in which case clusters are not merged above the threshold
+	// distance
+	K         uint
+	Threshold float64
+}
+
+// Agglomerative clusters samples bottom up, starting with each sample in
+// its own cluster and merging the two closest clusters until there is one
+// cluster. The merges form a tree which can be cut into any number of
+// clusters
+type Agglomerative struct {
+	config  AgglomerativeConfig
+	samples int
+	merges  []Merge
+}
+
+// Merge is the merge of two clusters, A and B, into one cluster with Size
+// samples. Clusters numbered less than the number of samples are single
+// samples, and cluster n+i is the cluster created by the i'th merge
+type Merge struct {
+	A, B     int
+	Distance float64
+	Size     int
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// Ward linkage merges the clusters which least increase the total
+	// within-cluster variance
+	LINKAGE_WARD Linkage = iota
+	// Single linkage is the distance between the closest samples
+	LINKAGE_SINGLE
+	// Complete linkage is the distance between the furthest samples
+	LINKAGE_COMPLETE
+	// Average linkage is the mean distance between samples
+	LINKAGE_AVERAGE
+)
+
+const (
+	DEFAULT_CLUSTERS = 2
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewAgglomerative returns agglomerative clustering with the configuration
+func NewAgglomerative(config AgglomerativeConfig) *Agglomerative {
+	return &Agglomerative{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit merges the samples, with one row for each sample, into a tree of
+// clusters. The distances between all pairs of samples are kept in memory,
+// and each merge searches all pairs, so this is suitable for thousands of
+// samples rather than millions
+func (this *Agglomerative) Fit(x mat.Matrix) error {
+	points, err := rows(x)
+	if err != nil {
+		return err
+	} else if this.config.Threshold < 0 {
+		return fmt.Errorf("%v: Threshold cannot be negative", ErrBadParameter)
+	}
+	n := len(points)
+	distance := make([][]float64, n)
+	for i := range distance {
+		distance[i] = make([]float64, n)
+		for j := 0; j < i; j++ {
+			distance[i][j] = euclidean(points[i], points[j])
+			distance[j][i] = distance[i][j]
+		}
+	}
+
+	// Each slot holds an active cluster, with its identifier and size
+	id, size, active := make([]int, n), make([]int, n), make([]bool, n)
+	for i := range id {
+		id[i], size[i], active[i] = i, 1, true
+	}
+	this.samples, this.merges = n, make([]Merge, 0, n-1)
+	for len(this.merges) < n-1 {
+		a, b, best := -1, -1, math.Inf(1)
+		for i := 0; i < n; i++ {
+			if active[i] == false {
+				continue
+			}
+			for j := i + 1; j < n; j++ {
+				if active[j] && distance[i][j] < best {
+					a, b, best = i, j, distance[i][j]
+				}
+			}
+		}
+
+		// Update the distances to the merged cluster, which replaces a
+		for k := 0; k < n; k++ {
+			if active[k] && k != a && k != b {
+				distance[a][k] = this.linkage(distance[a][k], distance[b][k], best, size[a], size[b], size[k])
+				distance[k][a] = distance[a][k]
+			}
+		}
+		merge := Merge{A: id[a], B: id[b], Distance: best, Size: size[a] + size[b]}
+		if merge.A > merge.B {
+			merge.A, merge.B = merge.B, merge.A
+		}
+		this.merges = append(this.merges, merge)
+		id[a], size[a], active[b] = n+len(this.merges)-1, merge.Size, false
+	}
+	return nil
+}
+
+// Merges returns the merges in the order they were made, which is in
+// increasing order of distance
+func (this *Agglomerative) Merges() []Merge {
+	return this.merges
+}
+
+// Clusters returns the cluster of each sample, cut at the threshold
+// distance when it is set, or otherwise into K clusters
+func (this *Agglomerative) Clusters() []int {
+	if this.config.Threshold > 0 {
+		return this.CutDistance(this.config.Threshold)
+	} else if this.config.K == 0 {
+		return this.Cut(DEFAULT_CLUSTERS)
+	} else {
+		return this.Cut(int(this.config.K))
+	}
+}
+
+// Cut returns the cluster of each sample when the tree is cut into k
+// clusters. Clusters are numbered in the order of their first sample
+func (this *Agglomerative) Cut(k int) []int {
+	if this.merges == nil || k < 1 {
+		return nil
+	}
+	if k > this.samples {
+		k = this.samples
+	}
+	return this.cut(this.samples - k)
+}
+
+// CutDistance returns the cluster of each sample when clusters are not
+// merged above a distance. Clusters are numbered in the order of their
+// first sample
+func (this *Agglomerative) CutDistance(distance float64) []int {
+	if this.merges == nil {
+		return nil
+	}
+	m := 0
+	for m < len(this.merges) && this.merges[m].Distance <= distance {
+		m++
+	}
+	return this.cut(m)
+}
+
+// Order returns the samples in the order they appear as leaves of the
+// tree, so that merged clusters are next to each other
+func (this *Agglomerative) Order() []int {
+	if this.merges == nil {
+		return nil
+	}
+	order := make([]int, 0, this.samples)
+	stack := []int{this.samples + len(this.merges) - 1}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node < this.samples {
+			order = append(order, node)
+		} else {
+			merge := this.merges[node-this.samples]
+			stack = append(stack, merge.B, merge.A)
+		}
+	}
+	return order
+}
+
+// Stringify
+func (this *Agglomerative) String() string {
+	return fmt.Sprintf("agglomerative{ linkage=%v samples=%v k=%v threshold=%v }", this.config.Linkage, this.samples, this.config.K, this.config.Threshold)
+}
+
+func (this Linkage) String() string {
+	switch this {
+	case LINKAGE_WARD:
+		return "LINKAGE_WARD"
+	case LINKAGE_SINGLE:
+		return "LINKAGE_SINGLE"
+	case LINKAGE_COMPLETE:
+		return "LINKAGE_COMPLETE"
+	case LINKAGE_AVERAGE:
+		return "LINKAGE_AVERAGE"
+	default:
+		return "[?? Invalid Linkage value]"
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// linkage returns the distance from cluster k to the cluster merged from a
+// and b using the Lance-Williams formula, given the distances from k to a
+// and b, the distance between a and b and the sizes of the clusters
+func (this *Agglomerative) linkage(ka, kb, ab float64, a, b, k int) float64 {
+	switch this.config.Linkage {
+	case LINKAGE_SINGLE:
+		return math.Min(ka, kb)
+	case LINKAGE_COMPLETE:
+		return math.Max(ka, kb)
+	case LINKAGE_AVERAGE:
+		return (float64(a)*ka + float64(b)*kb) / float64(a+b)
+	default:
+		na, nb, nk := float64(a), float64(b), float64(k)
+		return math.Sqrt(((nk+na)*ka*ka + (nk+nb)*kb*kb - nk*ab*ab) / (na + nb + nk))
+	}
+}
+
+// cut returns the cluster of each sample after the first m merges
+func (this *Agglomerative) cut(m int) []int {
+	parent := make([]int, this.samples+len(this.merges))
+	for i := range parent {
+		parent[i] = i
+	}
+	for i := 0; i < m; i++ {
+		node := this.samples + i
+		parent[this.merges[i].A], parent[this.merges[i].B] = node, node
+	}
+	root := func(node int) int {
+		for parent[node] != node {
+			node = parent[node]
+		}
+		return node
+	}
+	clusters := make([]int, this.samples)
+	number := make(map[int]int)
+	for i := range clusters {
+		r := root(i)
+		if _, exists := number[r]; exists == false {
+			number[r] = len(number)
+		}
+		clusters[i] = number[r]
+	}
+	return clusters
+}
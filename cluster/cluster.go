@@ -0,0 +1,184 @@
+/*
+	Package cluster implements unsupervised clustering of samples, where
+	each sample is assigned to a cluster of similar samples. k-means
+	partitions the samples into k clusters around centroids, and the
+	silhouette and inertia help choose the number of clusters.
+	Agglomerative clustering builds a tree of clusters which can be cut
+	at any number of clusters, and DBSCAN and HDBSCAN find clusters of
+	any shape from the density of samples, marking other samples as
+	noise with a cluster of -1.
+*/
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Silhouette returns the mean silhouette coefficient and the coefficient
+// for each sample, given the cluster of each sample. The coefficient
+// compares the mean distance to samples in the same cluster, a, with the
+// mean distance to samples in the nearest other cluster, b, as
+// (b - a) / max(a, b). It is between -1 and 1, where larger is better.
+// Samples in a cluster on their own, or with a negative cluster which
+// marks noise, have a coefficient of zero and noise is not included in
+// the mean
+func Silhouette(x mat.Matrix, clusters []int) (float64, []float64, error) {
+	points, err := rows(x)
+	if err != nil {
+		return 0, nil, err
+	} else if len(points) != len(clusters) {
+		return 0, nil, fmt.Errorf("%v: Samples and clusters mismatch", ErrBadParameter)
+	}
+	size := make(map[int]int)
+	for _, c := range clusters {
+		if c >= 0 {
+			size[c]++
+		}
+	}
+	if len(size) < 2 {
+		return 0, nil, fmt.Errorf("%v: Expected at least two clusters", ErrBadParameter)
+	}
+
+	scores := make([]float64, len(points))
+	var mean float64
+	var n int
+	for i, p := range points {
+		if clusters[i] < 0 {
+			continue
+		}
+		n++
+		if size[clusters[i]] == 1 {
+			continue
+		}
+		sum := make(map[int]float64, len(size))
+		for j, q := range points {
+			if i != j && clusters[j] >= 0 {
+				sum[clusters[j]] += euclidean(p, q)
+			}
+		}
+		a, b := sum[clusters[i]]/float64(size[clusters[i]]-1), math.Inf(1)
+		for c, total := range sum {
+			if c != clusters[i] {
+				b = math.Min(b, total/float64(size[c]))
+			}
+		}
+		scores[i] = (b - a) / math.Max(a, b)
+		mean += scores[i]
+	}
+	return mean / float64(n), scores, nil
+}
+
+// AppendClusters appends a column to the table with the cluster of each
+// row, where negative clusters are nil
+func AppendClusters(table *util.Table, column string, clusters []int) error {
+	values := make([]string, len(clusters))
+	for i, c := range clusters {
+		if c >= 0 {
+			values[i] = strconv.Itoa(c)
+		}
+	}
+	return table.AppendStringColumn(column, values)
+}
+
+// Crosstab returns a table with a row for each cluster in a column of the
+// table and a column for each known label, with the number of rows with
+// each label in each cluster. Rows which are not in a cluster are counted
+// in a row named Noise
+func Crosstab(table *util.Table, column, label string) (*util.Table, error) {
+	clusters, err := table.StringColumn(column, "")
+	if err != nil {
+		return nil, err
+	}
+	labels, err := table.StringColumn(label, "")
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]map[string]int)
+	columns := make([]string, 0)
+	for i, label := range labels {
+		if _, exists := counts[label]; exists == false {
+			counts[label] = make(map[string]int)
+			columns = append(columns, label)
+		}
+		counts[label][clusters[i]]++
+	}
+	sort.Strings(columns)
+	names := make(map[string]bool)
+	for _, c := range clusters {
+		names[c] = true
+	}
+	keys := make([]string, 0, len(names))
+	for c := range names {
+		keys = append(keys, c)
+	}
+	sort.Strings(keys)
+
+	result, err := util.NewTable(append([]string{column}, columns...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range keys {
+		row := []string{c}
+		if c == "" {
+			row[0] = "Noise"
+		}
+		for _, label := range columns {
+			row = append(row, fmt.Sprint(counts[label][c]))
+		}
+		if err := result.AppendStringRow(row, false); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// rows returns the rows of a matrix, returning an error if there are no
+// rows or any value is missing
+func rows(x mat.Matrix) ([][]float64, error) {
+	r, c := x.Dims()
+	if r == 0 || c == 0 {
+		return nil, ErrEmpty
+	}
+	points := make([][]float64, r)
+	for i := range points {
+		points[i] = mat.Row(nil, i, x)
+		for _, value := range points[i] {
+			if math.IsNaN(value) {
+				return nil, fmt.Errorf("%v: Missing value in row %v", ErrBadParameter, i)
+			}
+		}
+	}
+	return points, nil
+}
+
+// euclidean returns the straight line distance between a and b
+func euclidean(a, b []float64) float64 {
+	return math.Sqrt(squared(a, b))
+}
+
+// squared returns the squared euclidean distance between a and b
+func squared(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return sum
+}
@@ -0,0 +1,447 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// DBSCANConfig is the configuration for DBSCAN
+type DBSCANConfig struct {
+	// Epsilon is the radius of the neighbourhood of a sample
+	Epsilon float64
+
+	// MinSamples is the number of samples, including the sample itself,
+	// within the neighbourhood for a sample to be a core sample
+	MinSamples uint
+}
+
+// DBSCAN clusters samples which are densely packed together. A core
+// sample has at least MinSamples samples within Epsilon, and a cluster is
+// the core samples which are within Epsilon of each other together with
+// the samples within Epsilon of them. Other samples are noise, so clusters
+// can have any shape and the number of clusters does not need to be known
+type DBSCAN struct {
+	config   DBSCANConfig
+	clusters []int
+	core     []bool
+}
+
+// HDBSCANConfig is the configuration for HDBSCAN
+type HDBSCANConfig struct {
+	// MinClusterSize is the smallest number of samples in a cluster
+	MinClusterSize uint
+
+	// MinSamples is the number of samples, including the sample itself,
+	// used to estimate the density around a sample, which defaults to
+	// MinClusterSize
+	MinSamples uint
+}
+
+// HDBSCAN is a hierarchical DBSCAN, which finds clusters over all values
+// of epsilon and keeps the clusters which persist the longest, so that
+// clusters can have different densities
+type HDBSCAN struct {
+	config   HDBSCANConfig
+	clusters []int
+}
+
+// edge is an edge between two samples of a spanning tree
+type edge struct {
+	a, b     int
+	distance float64
+}
+
+// condensed is a cluster or sample leaving a parent cluster at lambda,
+// which is one divided by the distance
+type condensed struct {
+	parent, child int
+	lambda        float64
+	size          int
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_MIN_SAMPLES      = 5
+	DEFAULT_MIN_CLUSTER_SIZE = 5
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewDBSCAN returns DBSCAN with the configuration
+func NewDBSCAN(config DBSCANConfig) *DBSCAN {
+	return &DBSCAN{config: config}
+}
+
+// NewHDBSCAN returns HDBSCAN with the configuration
+func NewHDBSCAN(config HDBSCANConfig) *HDBSCAN {
+	return &HDBSCAN{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// DBSCAN
+
+// Fit assigns the samples, with one row for each sample, to clusters
+func (this *DBSCAN) Fit(x mat.Matrix) error {
+	points, err := rows(x)
+	if err != nil {
+		return err
+	} else if this.config.Epsilon <= 0 {
+		return fmt.Errorf("%v: Epsilon must be positive", ErrBadParameter)
+	}
+	n, min := len(points), this.minSamples()
+
+	// Find the neighbours of each sample, including the sample itself
+	neighbours := make([][]int, n)
+	for i := range points {
+		for j := range points {
+			if euclidean(points[i], points[j]) <= this.config.Epsilon {
+				neighbours[i] = append(neighbours[i], j)
+			}
+		}
+	}
+	this.core = make([]bool, n)
+	for i := range neighbours {
+		this.core[i] = len(neighbours[i]) >= min
+	}
+
+	// Expand a cluster from each core sample which is not yet assigned
+	this.clusters = make([]int, n)
+	for i := range this.clusters {
+		this.clusters[i] = -1
+	}
+	next := 0
+	for i := range points {
+		if this.core[i] == false || this.clusters[i] >= 0 {
+			continue
+		}
+		this.clusters[i] = next
+		queue := []int{i}
+		for len(queue) > 0 {
+			j := queue[0]
+			queue = queue[1:]
+			if this.core[j] == false {
+				continue
+			}
+			for _, k := range neighbours[j] {
+				if this.clusters[k] < 0 {
+					this.clusters[k] = next
+					queue = append(queue, k)
+				}
+			}
+		}
+		next++
+	}
+	return nil
+}
+
+// Clusters returns the cluster of each sample, or -1 for noise
+func (this *DBSCAN) Clusters() []int {
+	return this.clusters
+}
+
+// Core returns true for each sample which is a core sample
+func (this *DBSCAN) Core() []bool {
+	return this.core
+}
+
+// Stringify
+func (this *DBSCAN) String() string {
+	return fmt.Sprintf("dbscan{ epsilon=%v min_samples=%v clusters=%v noise=%v }", this.config.Epsilon, this.minSamples(), count(this.clusters), noise(this.clusters))
+}
+
+func (this *DBSCAN) minSamples() int {
+	if this.config.MinSamples == 0 {
+		return DEFAULT_MIN_SAMPLES
+	}
+	return int(this.config.MinSamples)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// HDBSCAN
+
+// Fit assigns the samples, with one row for each sample, to clusters
+func (this *HDBSCAN) Fit(x mat.Matrix) error {
+	points, err := rows(x)
+	if err != nil {
+		return err
+	}
+	n, size, min := len(points), this.minClusterSize(), this.minSamples()
+	if size < 2 {
+		return fmt.Errorf("%v: MinClusterSize must be at least two", ErrBadParameter)
+	} else if min > n {
+		return fmt.Errorf("%v: MinSamples is more than the number of samples", ErrBadParameter)
+	}
+
+	// The core distance of a sample is the distance to its min'th nearest
+	// sample, counting itself
+	distance := make([][]float64, n)
+	core := make([]float64, n)
+	for i := range points {
+		distance[i] = make([]float64, n)
+		for j := range points {
+			distance[i][j] = euclidean(points[i], points[j])
+		}
+		sorted := append([]float64(nil), distance[i]...)
+		sort.Float64s(sorted)
+		core[i] = sorted[min-1]
+	}
+
+	// Build the minimum spanning tree over the mutual reachability
+	// distance, which is the largest of the distance and the two core
+	// distances, using Prim's algorithm
+	edges := make([]edge, 0, n-1)
+	in, best, from := make([]bool, n), make([]float64, n), make([]int, n)
+	for i := range best {
+		best[i] = math.Inf(1)
+	}
+	current := 0
+	for len(edges) < n-1 {
+		in[current] = true
+		next := -1
+		for j := 0; j < n; j++ {
+			if in[j] {
+				continue
+			}
+			if d := math.Max(distance[current][j], math.Max(core[current], core[j])); d < best[j] {
+				best[j], from[j] = d, current
+			}
+			if next < 0 || best[j] < best[next] {
+				next = j
+			}
+		}
+		edges = append(edges, edge{from[next], next, best[next]})
+		current = next
+	}
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].distance < edges[j].distance })
+
+	// Condense the single linkage tree, and select the clusters with the
+	// most excess of mass
+	tree := condense(singleLinkage(n, edges), n, size)
+	this.clusters = label(tree, n, selectClusters(tree, n))
+	return nil
+}
+
+// Clusters returns the cluster of each sample, or -1 for noise
+func (this *HDBSCAN) Clusters() []int {
+	return this.clusters
+}
+
+// Stringify
+func (this *HDBSCAN) String() string {
+	return fmt.Sprintf("hdbscan{ min_cluster_size=%v min_samples=%v clusters=%v noise=%v }", this.minClusterSize(), this.minSamples(), count(this.clusters), noise(this.clusters))
+}
+
+func (this *HDBSCAN) minClusterSize() int {
+	if this.config.MinClusterSize == 0 {
+		return DEFAULT_MIN_CLUSTER_SIZE
+	}
+	return int(this.config.MinClusterSize)
+}
+
+func (this *HDBSCAN) minSamples() int {
+	if this.config.MinSamples == 0 {
+		return this.minClusterSize()
+	}
+	return int(this.config.MinSamples)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// singleLinkage returns the merges of the single linkage tree from the
+// edges of a spanning tree in increasing order of distance
+func singleLinkage(n int, edges []edge) []Merge {
+	parent := make([]int, 2*n-1)
+	for i := range parent {
+		parent[i] = i
+	}
+	root := func(node int) int {
+		for parent[node] != node {
+			parent[node] = parent[parent[node]]
+			node = parent[node]
+		}
+		return node
+	}
+	size := make([]int, 2*n-1)
+	for i := 0; i < n; i++ {
+		size[i] = 1
+	}
+	merges := make([]Merge, 0, n-1)
+	for _, e := range edges {
+		a, b := root(e.a), root(e.b)
+		node := n + len(merges)
+		parent[a], parent[b], size[node] = node, node, size[a]+size[b]
+		merges = append(merges, Merge{A: a, B: b, Distance: e.distance, Size: size[node]})
+	}
+	return merges
+}
+
+// condense walks the single linkage tree from the root, where a split only
+// creates two new clusters when both sides have at least size samples.
+// Otherwise samples on the smaller side fall out of the cluster. Clusters
+// in the condensed tree are numbered from n, where n is the root
+func condense(merges []Merge, n, size int) []condensed {
+	nodeSize := func(node int) int {
+		if node < n {
+			return 1
+		}
+		return merges[node-n].Size
+	}
+	lambda := func(distance float64) float64 {
+		if distance > 0 {
+			return 1 / distance
+		}
+		return math.Inf(1)
+	}
+	leaves := func(node int) []int {
+		result := make([]int, 0)
+		stack := []int{node}
+		for len(stack) > 0 {
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if node < n {
+				result = append(result, node)
+			} else {
+				stack = append(stack, merges[node-n].A, merges[node-n].B)
+			}
+		}
+		return result
+	}
+
+	tree := make([]condensed, 0)
+	next := n + 1
+	type item struct{ node, cluster int }
+	stack := []item{{n + len(merges) - 1, n}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if top.node < n {
+			continue
+		}
+		merge := merges[top.node-n]
+		l := lambda(merge.Distance)
+		a, b := merge.A, merge.B
+		big_a, big_b := nodeSize(a) >= size, nodeSize(b) >= size
+		switch {
+		case big_a && big_b:
+			for _, child := range []int{a, b} {
+				tree = append(tree, condensed{top.cluster, next, l, nodeSize(child)})
+				stack = append(stack, item{child, next})
+				next++
+			}
+		case big_a || big_b:
+			keep, drop := a, b
+			if big_b {
+				keep, drop = b, a
+			}
+			for _, sample := range leaves(drop) {
+				tree = append(tree, condensed{top.cluster, sample, l, 1})
+			}
+			stack = append(stack, item{keep, top.cluster})
+		default:
+			for _, sample := range leaves(top.node) {
+				tree = append(tree, condensed{top.cluster, sample, l, 1})
+			}
+		}
+	}
+	return tree
+}
+
+// selectClusters returns the clusters of the condensed tree which have
+// more stability than their descendants, where the stability of a cluster
+// is the sum over its samples of how long they remain in the cluster,
+// measured in lambda. The root is never selected
+func selectClusters(tree []condensed, n int) map[int]bool {
+	birth := map[int]float64{n: 0}
+	children := make(map[int][]int)
+	last := n
+	for _, entry := range tree {
+		if entry.child >= n {
+			birth[entry.child] = entry.lambda
+			children[entry.parent] = append(children[entry.parent], entry.child)
+			if entry.child > last {
+				last = entry.child
+			}
+		}
+	}
+	stability := make(map[int]float64)
+	for _, entry := range tree {
+		if entry.lambda > birth[entry.parent] {
+			stability[entry.parent] += (entry.lambda - birth[entry.parent]) * float64(entry.size)
+		}
+	}
+
+	// Children are numbered after their parents, so visit clusters in
+	// reverse order to see children first
+	selected := make(map[int]bool)
+	for cluster := last; cluster > n; cluster-- {
+		var subtree float64
+		for _, child := range children[cluster] {
+			subtree += stability[child]
+		}
+		if len(children[cluster]) > 0 && subtree > stability[cluster] {
+			stability[cluster] = subtree
+			continue
+		}
+		selected[cluster] = true
+		stack := append([]int(nil), children[cluster]...)
+		for len(stack) > 0 {
+			child := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			delete(selected, child)
+			stack = append(stack, children[child]...)
+		}
+	}
+	return selected
+}
+
+// label returns the selected cluster of each sample, numbered in the order
+// of their first sample, or -1 when a sample is not in a selected cluster
+func label(tree []condensed, n int, selected map[int]bool) []int {
+	parent := make(map[int]int)
+	for _, entry := range tree {
+		parent[entry.child] = entry.parent
+	}
+	clusters := make([]int, n)
+	number := make(map[int]int)
+	for i := range clusters {
+		clusters[i] = -1
+		for node, exists := parent[i]; exists; node, exists = parent[node] {
+			if selected[node] {
+				if _, exists := number[node]; exists == false {
+					number[node] = len(number)
+				}
+				clusters[i] = number[node]
+				break
+			}
+		}
+	}
+	return clusters
+}
+
+// count returns the number of clusters, not including noise
+func count(clusters []int) int {
+	seen := make(map[int]bool)
+	for _, c := range clusters {
+		if c >= 0 {
+			seen[c] = true
+		}
+	}
+	return len(seen)
+}
+
+// noise returns the number of samples which are noise
+func noise(clusters []int) int {
+	var result int
+	for _, c := range clusters {
+		if c < 0 {
+			result++
+		}
+	}
+	return result
+}
@@ -0,0 +1,359 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// KMeansConfig is the configuration for k-means
+type KMeansConfig struct {
+	// K is the number of clusters
+	K uint
+
+	// Restarts is the number of times k-means is run with different
+	// initial centroids, keeping the run with the lowest inertia
+	Restarts uint
+
+	// MaxIter is the maximum number of iterations for each run, and
+	// Tolerance is the movement of the centroids, relative to the mean
+	// variance of the features, below which a run has converged
+	MaxIter   uint
+	Tolerance float64
+
+	// BatchSize is the number of samples used to update the centroids in
+	// each iteration of mini-batch k-means, or zero to use all samples
+	BatchSize uint
+
+	// Seed for choosing the initial centroids and the mini-batches
+	Seed int64
+}
+
+// KMeans partitions samples into clusters, assigning each sample to the
+// cluster with the nearest centroid
+type KMeans struct {
+	config     KMeansConfig
+	centroids  [][]float64
+	inertia    float64
+	iterations int
+}
+
+// ElbowPoint is the inertia and mean silhouette for one value of k
+type ElbowPoint struct {
+	K          uint
+	Inertia    float64
+	Silhouette float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_K                = 8
+	DEFAULT_RESTARTS         = 10
+	DEFAULT_MAX_ITER         = 300
+	DEFAULT_KMEANS_TOLERANCE = 1e-4
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewKMeans returns k-means with the configuration
+func NewKMeans(config KMeansConfig) *KMeans {
+	return &KMeans{config: config}
+}
+
+// Elbow fits k-means for each value of k and returns the inertia and the
+// mean silhouette. The inertia always decreases as k increases, and the
+// best k is usually where it stops decreasing sharply (the elbow) or
+// where the silhouette is largest
+func Elbow(x mat.Matrix, config KMeansConfig, k ...uint) ([]ElbowPoint, error) {
+	result := make([]ElbowPoint, 0, len(k))
+	for _, value := range k {
+		config.K = value
+		model := NewKMeans(config)
+		if err := model.Fit(x); err != nil {
+			return nil, err
+		} else if clusters, err := model.Predict(x); err != nil {
+			return nil, err
+		} else if silhouette, _, err := Silhouette(x, clusters); err != nil {
+			return nil, err
+		} else {
+			result = append(result, ElbowPoint{value, model.Inertia(), silhouette})
+		}
+	}
+	return result, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit chooses the centroids from the samples, with one row for each
+// sample
+func (this *KMeans) Fit(x mat.Matrix) error {
+	points, err := rows(x)
+	if err != nil {
+		return err
+	}
+	k := this.k()
+	if k > len(points) {
+		return fmt.Errorf("%v: More clusters than samples", ErrBadParameter)
+	} else if this.config.Tolerance < 0 {
+		return fmt.Errorf("%v: Tolerance cannot be negative", ErrBadParameter)
+	}
+
+	// The tolerance is relative to the mean variance of the features
+	tolerance := this.tolerance() * variance(points)
+
+	r := rand.New(rand.NewSource(this.config.Seed))
+	this.centroids, this.inertia = nil, math.Inf(1)
+	for run := 0; run < this.restarts(); run++ {
+		centroids := plusplus(points, k, r)
+		var iterations int
+		if this.config.BatchSize > 0 {
+			iterations = minibatch(points, centroids, int(this.config.BatchSize), this.maxIter(), r)
+		} else {
+			iterations = lloyd(points, centroids, this.maxIter(), tolerance)
+		}
+		if inertia := inertia(points, centroids); inertia < this.inertia {
+			this.centroids, this.inertia, this.iterations = centroids, inertia, iterations
+		}
+	}
+	return nil
+}
+
+// Predict returns the cluster with the nearest centroid for each row, or
+// -1 when a row has a missing value
+func (this *KMeans) Predict(x mat.Matrix) ([]int, error) {
+	if this.centroids == nil {
+		return nil, ErrNotFitted
+	}
+	r, c := x.Dims()
+	if c != len(this.centroids[0]) {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, len(this.centroids[0]))
+	}
+	clusters := make([]int, r)
+	row := make([]float64, c)
+	for i := range clusters {
+		mat.Row(row, i, x)
+		if hasNaN(row) {
+			clusters[i] = -1
+		} else {
+			clusters[i], _ = nearest(row, this.centroids)
+		}
+	}
+	return clusters, nil
+}
+
+// Centroids returns the centroids with one row for each cluster
+func (this *KMeans) Centroids() *mat.Dense {
+	if this.centroids == nil {
+		return nil
+	}
+	result := mat.NewDense(len(this.centroids), len(this.centroids[0]), nil)
+	for i, centroid := range this.centroids {
+		result.SetRow(i, centroid)
+	}
+	return result
+}
+
+// Inertia returns the sum of squared distances from each sample to its
+// nearest centroid
+func (this *KMeans) Inertia() float64 {
+	return this.inertia
+}
+
+// Iterations returns the number of iterations for the best run
+func (this *KMeans) Iterations() int {
+	return this.iterations
+}
+
+// Stringify
+func (this *KMeans) String() string {
+	return fmt.Sprintf("kmeans{ k=%v restarts=%v max_iter=%v batch_size=%v inertia=%.4f iterations=%v }", this.k(), this.restarts(), this.maxIter(), this.config.BatchSize, this.inertia, this.iterations)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *KMeans) k() int {
+	if this.config.K == 0 {
+		return DEFAULT_K
+	}
+	return int(this.config.K)
+}
+
+func (this *KMeans) restarts() int {
+	if this.config.Restarts == 0 {
+		return DEFAULT_RESTARTS
+	}
+	return int(this.config.Restarts)
+}
+
+func (this *KMeans) maxIter() int {
+	if this.config.MaxIter == 0 {
+		return DEFAULT_MAX_ITER
+	}
+	return int(this.config.MaxIter)
+}
+
+func (this *KMeans) tolerance() float64 {
+	if this.config.Tolerance == 0 {
+		return DEFAULT_KMEANS_TOLERANCE
+	}
+	return this.config.Tolerance
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// plusplus chooses k initial centroids using k-means++, where the first
+// is a random sample and each other is a sample chosen with probability
+// proportional to its squared distance from the nearest centroid so far
+func plusplus(points [][]float64, k int, r *rand.Rand) [][]float64 {
+	centroids := make([][]float64, 0, k)
+	centroids = append(centroids, copyOf(points[r.Intn(len(points))]))
+	distances := make([]float64, len(points))
+	for len(centroids) < k {
+		var total float64
+		for i, p := range points {
+			_, distances[i] = nearest(p, centroids)
+			total += distances[i]
+		}
+		choice := 0
+		if total > 0 {
+			target := r.Float64() * total
+			for i, d := range distances {
+				if target -= d; target < 0 {
+					choice = i
+					break
+				}
+			}
+		} else {
+			choice = r.Intn(len(points))
+		}
+		centroids = append(centroids, copyOf(points[choice]))
+	}
+	return centroids
+}
+
+// lloyd moves each centroid to the mean of its samples until the
+// centroids move less than the tolerance, and returns the number of
+// iterations. A cluster with no samples is moved to the sample furthest
+// from its centroid
+func lloyd(points, centroids [][]float64, iterations int, tolerance float64) int {
+	k, d := len(centroids), len(points[0])
+	for iter := 1; iter <= iterations; iter++ {
+		sums, counts := make([][]float64, k), make([]int, k)
+		for c := range sums {
+			sums[c] = make([]float64, d)
+		}
+		furthest, distance := 0, -1.0
+		for i, p := range points {
+			c, dist := nearest(p, centroids)
+			counts[c]++
+			for j, value := range p {
+				sums[c][j] += value
+			}
+			if dist > distance {
+				furthest, distance = i, dist
+			}
+		}
+		var shift float64
+		for c := range centroids {
+			var next []float64
+			if counts[c] == 0 {
+				next = copyOf(points[furthest])
+			} else {
+				next = sums[c]
+				for j := range next {
+					next[j] /= float64(counts[c])
+				}
+			}
+			shift += squared(centroids[c], next)
+			centroids[c] = next
+		}
+		if shift <= tolerance {
+			return iter
+		}
+	}
+	return iterations
+}
+
+// minibatch updates the centroids from a random batch of samples in each
+// iteration, with a learning rate for each centroid which decreases as it
+// is assigned more samples, and returns the number of iterations
+func minibatch(points, centroids [][]float64, size, iterations int, r *rand.Rand) int {
+	counts := make([]float64, len(centroids))
+	batch := make([]int, size)
+	for iter := 0; iter < iterations; iter++ {
+		for b := range batch {
+			batch[b] = r.Intn(len(points))
+		}
+		assigned := make([]int, size)
+		for b, i := range batch {
+			assigned[b], _ = nearest(points[i], centroids)
+		}
+		for b, i := range batch {
+			c := assigned[b]
+			counts[c]++
+			eta := 1 / counts[c]
+			for j, value := range points[i] {
+				centroids[c][j] = (1-eta)*centroids[c][j] + eta*value
+			}
+		}
+	}
+	return iterations
+}
+
+// nearest returns the nearest centroid to a sample and the squared
+// distance to it
+func nearest(p []float64, centroids [][]float64) (int, float64) {
+	best, distance := 0, math.Inf(1)
+	for c, centroid := range centroids {
+		if d := squared(p, centroid); d < distance {
+			best, distance = c, d
+		}
+	}
+	return best, distance
+}
+
+// inertia returns the sum of squared distances to the nearest centroids
+func inertia(points, centroids [][]float64) float64 {
+	var sum float64
+	for _, p := range points {
+		_, d := nearest(p, centroids)
+		sum += d
+	}
+	return sum
+}
+
+// variance returns the mean variance of the features
+func variance(points [][]float64) float64 {
+	d := len(points[0])
+	mean := make([]float64, d)
+	for _, p := range points {
+		for j, value := range p {
+			mean[j] += value / float64(len(points))
+		}
+	}
+	var sum float64
+	for _, p := range points {
+		sum += squared(p, mean)
+	}
+	return sum / float64(len(points)*d)
+}
+
+func copyOf(values []float64) []float64 {
+	return append([]float64(nil), values...)
+}
+
+func hasNaN(values []float64) bool {
+	for _, value := range values {
+		if math.IsNaN(value) {
+			return true
+		}
+	}
+	return false
+}
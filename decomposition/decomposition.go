@@ -0,0 +1,113 @@
+/*
+	Package decomposition reduces the number of features by projecting
+	samples onto a smaller number of components. Principal component
+	analysis (PCA) finds the orthogonal directions of largest variance
+	using the singular value decomposition of the centred samples, and
+	truncated SVD finds the largest singular vectors without centring
+	using a randomised algorithm, which suits data with many features
+	which are mostly zero.
+*/
+package decomposition
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// transformer projects samples onto components
+type transformer interface {
+	Transform(x mat.Matrix) (*mat.Dense, error)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Scores returns a table with a column for each component, named with a
+// prefix and the component number counting from one, and the score of
+// each row of the table. Rows with a missing feature have missing scores
+func Scores(model transformer, table *util.Table, features []string, prefix string) (*util.Table, error) {
+	x, err := table.Matrix(features...)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := model.Transform(x)
+	if err != nil {
+		return nil, err
+	}
+	rows, cols := scores.Dims()
+	columns := make([]string, cols)
+	for j := range columns {
+		columns[j] = fmt.Sprint(prefix, j+1)
+	}
+	result, err := util.NewTable(columns...)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < rows; i++ {
+		values := make([]string, cols)
+		for j := range values {
+			if value := scores.At(i, j); math.IsNaN(value) == false {
+				values[j] = strconv.FormatFloat(value, 'g', -1, 64)
+			}
+		}
+		if err := result.AppendStringRow(values, true); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// centre returns the samples minus the mean of each feature, and the
+// means, returning an error if any value is missing
+func centre(x mat.Matrix) (*mat.Dense, []float64, error) {
+	n, d := x.Dims()
+	mean := make([]float64, d)
+	for i := 0; i < n; i++ {
+		for j := 0; j < d; j++ {
+			value := x.At(i, j)
+			if math.IsNaN(value) {
+				return nil, nil, fmt.Errorf("%v: Missing value in row %v", ErrBadParameter, i)
+			}
+			mean[j] += value / float64(n)
+		}
+	}
+	centred := mat.NewDense(n, d, nil)
+	centred.Apply(func(i, j int, value float64) float64 {
+		return value - mean[j]
+	}, x)
+	return centred, mean, nil
+}
+
+// flip changes the sign of each component so that its largest loading is
+// positive, which makes the components deterministic
+func flip(components *mat.Dense) {
+	k, d := components.Dims()
+	for i := 0; i < k; i++ {
+		largest := 0
+		for j := 0; j < d; j++ {
+			if math.Abs(components.At(i, j)) > math.Abs(components.At(i, largest)) {
+				largest = j
+			}
+		}
+		if components.At(i, largest) < 0 {
+			for j := 0; j < d; j++ {
+				components.Set(i, j, -components.At(i, j))
+			}
+		}
+	}
+}
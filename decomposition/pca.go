@@ -0,0 +1,193 @@
+package decomposition
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// PCAConfig is the configuration for principal component analysis
+type PCAConfig struct {
+	// Components is the number of components to keep, or zero to keep
+	// enough components to explain Variance, or all components when
+	// Variance is also zero
+	Components uint
+	Variance   float64
+
+	// Whiten scales the components to unit variance
+	Whiten bool
+}
+
+// PCA is principal component analysis, which projects samples onto the
+// directions of largest variance
+type PCA struct {
+	config     PCAConfig
+	mean       []float64
+	components *mat.Dense
+	variance   []float64
+	total      float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewPCA returns principal component analysis with the configuration
+func NewPCA(config PCAConfig) *PCA {
+	return &PCA{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit finds the components from the samples, with one row for each
+// sample
+func (this *PCA) Fit(x mat.Matrix) error {
+	n, d := x.Dims()
+	if n < 2 || d == 0 {
+		return ErrEmpty
+	} else if this.config.Variance < 0 || this.config.Variance > 1 {
+		return fmt.Errorf("%v: Variance should be between zero and one", ErrBadParameter)
+	}
+
+	// Centre the samples
+	centred, mean, err := centre(x)
+	if err != nil {
+		return err
+	}
+
+	// The right singular vectors are the components, and the variance
+	// explained by each is the square of the singular value over n-1
+	var svd mat.SVD
+	if ok := svd.Factorize(centred, mat.SVDThin); ok == false {
+		return fmt.Errorf("Singular value decomposition failed")
+	}
+	values := svd.Values(nil)
+	variance := make([]float64, len(values))
+	var total float64
+	for i, s := range values {
+		variance[i] = s * s / float64(n-1)
+		total += variance[i]
+	}
+
+	// Determine the number of components
+	k := len(values)
+	if this.config.Components > 0 {
+		if int(this.config.Components) > k {
+			return fmt.Errorf("%v: At most %v components", ErrBadParameter, k)
+		}
+		k = int(this.config.Components)
+	} else if this.config.Variance > 0 && total > 0 {
+		var sum float64
+		for i := range variance {
+			if sum += variance[i]; sum/total >= this.config.Variance-1e-12 {
+				k = i + 1
+				break
+			}
+		}
+	}
+
+	var v mat.Dense
+	svd.VTo(&v)
+	this.components = mat.NewDense(k, d, nil)
+	this.components.Copy(v.Slice(0, d, 0, k).T())
+	flip(this.components)
+	this.mean, this.variance, this.total = mean, variance[:k], total
+	return nil
+}
+
+// Transform returns the score of each row on each component
+func (this *PCA) Transform(x mat.Matrix) (*mat.Dense, error) {
+	if this.components == nil {
+		return nil, ErrNotFitted
+	}
+	rows, cols := x.Dims()
+	if cols != len(this.mean) {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, len(this.mean))
+	}
+	centred := mat.NewDense(rows, cols, nil)
+	centred.Apply(func(i, j int, value float64) float64 {
+		return value - this.mean[j]
+	}, x)
+	scores := new(mat.Dense)
+	scores.Mul(centred, this.components.T())
+	if this.config.Whiten {
+		scores.Apply(func(i, j int, value float64) float64 {
+			return value / this.scale(j)
+		}, scores)
+	}
+	return scores, nil
+}
+
+// InverseTransform returns the samples from their scores on the
+// components, which reconstructs the samples when all components are
+// kept and approximates them otherwise
+func (this *PCA) InverseTransform(scores mat.Matrix) (*mat.Dense, error) {
+	if this.components == nil {
+		return nil, ErrNotFitted
+	}
+	rows, cols := scores.Dims()
+	if k, _ := this.components.Dims(); cols != k {
+		return nil, fmt.Errorf("%v: Expected %v components", ErrBadParameter, k)
+	}
+	unscaled := mat.NewDense(rows, cols, nil)
+	unscaled.Apply(func(i, j int, value float64) float64 {
+		if this.config.Whiten {
+			return value * this.scale(j)
+		}
+		return value
+	}, scores)
+	x := new(mat.Dense)
+	x.Mul(unscaled, this.components)
+	x.Apply(func(i, j int, value float64) float64 {
+		return value + this.mean[j]
+	}, x)
+	return x, nil
+}
+
+// Components returns the components with one row for each component and
+// one column for each feature, in decreasing order of variance
+func (this *PCA) Components() *mat.Dense {
+	return this.components
+}
+
+// Mean returns the mean of each feature
+func (this *PCA) Mean() []float64 {
+	return this.mean
+}
+
+// ExplainedVariance returns the variance of the samples along each
+// component
+func (this *PCA) ExplainedVariance() []float64 {
+	return this.variance
+}
+
+// ExplainedVarianceRatio returns the fraction of the total variance of
+// the samples explained by each component
+func (this *PCA) ExplainedVarianceRatio() []float64 {
+	ratio := make([]float64, len(this.variance))
+	for i, value := range this.variance {
+		if this.total > 0 {
+			ratio[i] = value / this.total
+		}
+	}
+	return ratio
+}
+
+// Stringify
+func (this *PCA) String() string {
+	return fmt.Sprintf("pca{ components=%v features=%v whiten=%v explained_variance_ratio=%.4f }", len(this.variance), len(this.mean), this.config.Whiten, this.ExplainedVarianceRatio())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// scale returns the standard deviation along a component, used for
+// whitening
+func (this *PCA) scale(j int) float64 {
+	if this.variance[j] > 0 {
+		return math.Sqrt(this.variance[j])
+	}
+	return 1
+}
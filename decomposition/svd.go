@@ -0,0 +1,267 @@
+package decomposition
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// SVDConfig is the configuration for truncated SVD
+type SVDConfig struct {
+	// Components is the number of components to keep
+	Components uint
+
+	// Iterations is the number of power iterations, which improve the
+	// accuracy when the singular values decrease slowly, and Oversamples
+	// is the number of extra random directions sampled
+	Iterations  uint
+	Oversamples uint
+
+	// Seed for the random directions
+	Seed int64
+}
+
+// TruncatedSVD projects samples onto the largest right singular vectors
+// of the samples, without centring them first. The singular vectors are
+// found using the randomised algorithm of Halko, Martinsson and Tropp
+// (2011), which only needs the product of the samples with a few vectors
+type TruncatedSVD struct {
+	config     SVDConfig
+	components *mat.Dense
+	singular   []float64
+	variance   []float64
+	total      float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_COMPONENTS  = 2
+	DEFAULT_ITERATIONS  = 5
+	DEFAULT_OVERSAMPLES = 10
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewTruncatedSVD returns truncated SVD with the configuration
+func NewTruncatedSVD(config SVDConfig) *TruncatedSVD {
+	return &TruncatedSVD{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit finds the components from the samples, with one row for each
+// sample
+func (this *TruncatedSVD) Fit(x mat.Matrix) error {
+	n, d := x.Dims()
+	if n == 0 || d == 0 {
+		return ErrEmpty
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < d; j++ {
+			if math.IsNaN(x.At(i, j)) {
+				return fmt.Errorf("%v: Missing value in row %v", ErrBadParameter, i)
+			}
+		}
+	}
+	k := this.k()
+	if k > n || k > d {
+		return fmt.Errorf("%v: At most %v components", ErrBadParameter, int(math.Min(float64(n), float64(d))))
+	}
+
+	// Sample the range of the samples with random directions, and refine
+	// it with power iterations
+	l := k + this.oversamples()
+	if l > n {
+		l = n
+	}
+	if l > d {
+		l = d
+	}
+	r := rand.New(rand.NewSource(this.config.Seed))
+	omega := mat.NewDense(d, l, nil)
+	omega.Apply(func(i, j int, value float64) float64 {
+		return r.NormFloat64()
+	}, omega)
+	q, z := new(mat.Dense), new(mat.Dense)
+	q.Mul(x, omega)
+	orthonormalise(q)
+	for i := 0; i < this.iterations(); i++ {
+		z.Mul(x.T(), q)
+		orthonormalise(z)
+		q.Mul(x, z)
+		orthonormalise(q)
+	}
+
+	// The right singular vectors of the projection onto the range are
+	// the components
+	b := new(mat.Dense)
+	b.Mul(q.T(), x)
+	var svd mat.SVD
+	if ok := svd.Factorize(b, mat.SVDThin); ok == false {
+		return fmt.Errorf("Singular value decomposition failed")
+	}
+	var v mat.Dense
+	svd.VTo(&v)
+	this.components = mat.NewDense(k, d, nil)
+	this.components.Copy(v.Slice(0, d, 0, k).T())
+	flip(this.components)
+	this.singular = svd.Values(nil)[:k]
+
+	// The explained variance is the variance of the scores on each
+	// component
+	scores, _ := this.Transform(x)
+	this.variance = make([]float64, k)
+	for j := range this.variance {
+		this.variance[j] = variance(mat.Col(nil, j, scores))
+	}
+	this.total = 0
+	for j := 0; j < d; j++ {
+		this.total += variance(mat.Col(nil, j, x))
+	}
+	return nil
+}
+
+// Transform returns the score of each row on each component
+func (this *TruncatedSVD) Transform(x mat.Matrix) (*mat.Dense, error) {
+	if this.components == nil {
+		return nil, ErrNotFitted
+	}
+	if _, cols := x.Dims(); cols != this.features() {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, this.features())
+	}
+	scores := new(mat.Dense)
+	scores.Mul(x, this.components.T())
+	return scores, nil
+}
+
+// InverseTransform returns the approximate samples from their scores on
+// the components
+func (this *TruncatedSVD) InverseTransform(scores mat.Matrix) (*mat.Dense, error) {
+	if this.components == nil {
+		return nil, ErrNotFitted
+	}
+	if _, cols := scores.Dims(); cols != len(this.singular) {
+		return nil, fmt.Errorf("%v: Expected %v components", ErrBadParameter, len(this.singular))
+	}
+	x := new(mat.Dense)
+	x.Mul(scores, this.components)
+	return x, nil
+}
+
+// Components returns the components with one row for each component and
+// one column for each feature, in decreasing order of singular value
+func (this *TruncatedSVD) Components() *mat.Dense {
+	return this.components
+}
+
+// SingularValues returns the singular value for each component
+func (this *TruncatedSVD) SingularValues() []float64 {
+	return this.singular
+}
+
+// ExplainedVariance returns the variance of the scores on each component
+func (this *TruncatedSVD) ExplainedVariance() []float64 {
+	return this.variance
+}
+
+// ExplainedVarianceRatio returns the fraction of the total variance of
+// the samples explained by each component
+func (this *TruncatedSVD) ExplainedVarianceRatio() []float64 {
+	ratio := make([]float64, len(this.variance))
+	for i, value := range this.variance {
+		if this.total > 0 {
+			ratio[i] = value / this.total
+		}
+	}
+	return ratio
+}
+
+// Stringify
+func (this *TruncatedSVD) String() string {
+	return fmt.Sprintf("truncated_svd{ components=%v features=%v iterations=%v singular_values=%.4f explained_variance_ratio=%.4f }", this.k(), this.features(), this.iterations(), this.singular, this.ExplainedVarianceRatio())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *TruncatedSVD) k() int {
+	if this.config.Components == 0 {
+		return DEFAULT_COMPONENTS
+	}
+	return int(this.config.Components)
+}
+
+func (this *TruncatedSVD) iterations() int {
+	if this.config.Iterations == 0 {
+		return DEFAULT_ITERATIONS
+	}
+	return int(this.config.Iterations)
+}
+
+func (this *TruncatedSVD) oversamples() int {
+	if this.config.Oversamples == 0 {
+		return DEFAULT_OVERSAMPLES
+	}
+	return int(this.config.Oversamples)
+}
+
+func (this *TruncatedSVD) features() int {
+	if this.components == nil {
+		return 0
+	}
+	_, d := this.components.Dims()
+	return d
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// orthonormalise replaces the columns of a with orthonormal columns which
+// span the same space, using modified Gram-Schmidt. Columns which are
+// dependent on earlier columns are set to zero
+func orthonormalise(a *mat.Dense) {
+	rows, cols := a.Dims()
+	for j := 0; j < cols; j++ {
+		col := mat.Col(nil, j, a)
+		for k := 0; k < j; k++ {
+			var dot float64
+			for i := 0; i < rows; i++ {
+				dot += a.At(i, k) * col[i]
+			}
+			for i := 0; i < rows; i++ {
+				col[i] -= dot * a.At(i, k)
+			}
+		}
+		var norm float64
+		for _, value := range col {
+			norm += value * value
+		}
+		if norm = math.Sqrt(norm); norm > 1e-12 {
+			for i := range col {
+				col[i] /= norm
+			}
+		} else {
+			for i := range col {
+				col[i] = 0
+			}
+		}
+		a.SetCol(j, col)
+	}
+}
+
+// variance returns the population variance of values
+func variance(values []float64) float64 {
+	var mean, sum float64
+	for _, value := range values {
+		mean += value / float64(len(values))
+	}
+	for _, value := range values {
+		sum += (value - mean) * (value - mean)
+	}
+	return sum / float64(len(values))
+}
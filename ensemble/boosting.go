@@ -0,0 +1,446 @@
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/djthorpe/MachineLearning/tree"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// BoostingConfig is the configuration for gradient boosting
+type BoostingConfig struct {
+	// Rounds is the maximum number of boosting rounds
+	Rounds uint
+
+	// LearningRate shrinks the contribution of each tree
+	LearningRate float64
+
+	// Subsample is the fraction of rows used to fit the tree in each
+	// round, or zero to use all rows
+	Subsample float64
+
+	// Tree is the configuration for each tree. When MaxDepth is zero,
+	// trees have a maximum depth of three
+	Tree tree.Config
+
+	// Validation is the fraction of rows held back to stop boosting
+	// early when the validation loss has not improved for Patience
+	// rounds, or zero to boost for all rounds
+	Validation float64
+	Patience   uint
+
+	// Seed for the validation split, the subsamples and the candidate
+	// features
+	Seed int64
+}
+
+// BoostingClassifier predicts the probability of each label from the
+// softmax of the sum of one tree for each label in each round, which
+// minimises the log loss
+type BoostingClassifier struct {
+	boosting
+	classes []string
+	prior   []float64
+	trees   [][]*tree.Regressor
+}
+
+// BoostingRegressor predicts the mean value plus the sum of the trees in
+// each round, which minimises the squared error
+type BoostingRegressor struct {
+	boosting
+	mean  float64
+	trees []*tree.Regressor
+}
+
+// boosting is common to classifiers and regressors
+type boosting struct {
+	config     BoostingConfig
+	features   []string
+	train      []float64
+	validation []float64
+	best       int
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_ROUNDS        = 100
+	DEFAULT_LEARNING_RATE = 0.1
+	DEFAULT_MAX_DEPTH     = 3
+	DEFAULT_PATIENCE      = 10
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewBoostingClassifier returns a gradient boosting classifier with the
+// configuration
+func NewBoostingClassifier(config BoostingConfig) *BoostingClassifier {
+	return &BoostingClassifier{boosting: boosting{config: config}}
+}
+
+// NewBoostingRegressor returns a gradient boosting regressor with the
+// configuration
+func NewBoostingRegressor(config BoostingConfig) *BoostingRegressor {
+	return &BoostingRegressor{boosting: boosting{config: config}}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CLASSIFIER
+
+// Fit boosts trees from the feature columns of the table to predict the
+// labels in the target column. Rows with a missing label are ignored
+func (this *BoostingClassifier) Fit(table *util.Table, features []string, target string) error {
+	table, labels, err := labelled(table, target)
+	if err != nil {
+		return err
+	}
+	this.classes = unique(labels)
+	class := make(map[string]int, len(this.classes))
+	for c, label := range this.classes {
+		class[label] = c
+	}
+	y := make([]int, len(labels))
+	for i, label := range labels {
+		y[i] = class[label]
+	}
+	r, train, validation, err := this.split(table, features)
+	if err != nil {
+		return err
+	}
+
+	// Start from the log of the proportion of each label
+	k := len(this.classes)
+	this.prior = make([]float64, k)
+	for _, i := range train {
+		this.prior[y[i]]++
+	}
+	for c := range this.prior {
+		this.prior[c] = math.Log(math.Max(this.prior[c], 1) / float64(len(train)))
+	}
+	scores := mat.NewDense(len(y), k, nil)
+	for i := range y {
+		scores.SetRow(i, this.prior)
+	}
+
+	// Fit a tree for each label to the gradient of the log loss, which
+	// is the difference between the observed and predicted probabilities
+	this.trees = make([][]*tree.Regressor, 0, this.rounds())
+	err = this.boost(r, train, validation, func(sample []bool) error {
+		proba := softmax(scores)
+		round := make([]*tree.Regressor, k)
+		for c := range round {
+			residuals := make([]float64, len(y))
+			for i := range y {
+				if sample[i] == false {
+					residuals[i] = math.NaN()
+				} else if y[i] == c {
+					residuals[i] = 1 - proba.At(i, c)
+				} else {
+					residuals[i] = -proba.At(i, c)
+				}
+			}
+			round[c] = tree.NewRegressor(this.treeConfig(r))
+			if err := round[c].FitValues(table, features, residuals); err != nil {
+				return err
+			} else if predicted, err := round[c].Predict(table); err != nil {
+				return err
+			} else {
+				for i, value := range predicted {
+					scores.Set(i, c, scores.At(i, c)+this.learningRate()*value)
+				}
+			}
+		}
+		this.trees = append(this.trees, round)
+		return nil
+	}, func(rows []int) float64 {
+		proba := softmax(scores)
+		var loss float64
+		for _, i := range rows {
+			loss -= math.Log(math.Max(proba.At(i, y[i]), 1e-15))
+		}
+		return loss / float64(len(rows))
+	})
+	if err != nil {
+		this.trees = nil
+		return err
+	}
+	this.trees = this.trees[:this.best+1]
+	return nil
+}
+
+// Classes returns the labels in sorted order, which are the columns
+// returned by PredictProba
+func (this *BoostingClassifier) Classes() []string {
+	return this.classes
+}
+
+// Predict returns the label with the largest probability for each row
+func (this *BoostingClassifier) Predict(table *util.Table) ([]string, error) {
+	proba, err := this.PredictProba(table)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := proba.Dims()
+	predicted := make([]string, rows)
+	for i := range predicted {
+		predicted[i] = this.classes[argmax(proba.RawRowView(i))]
+	}
+	return predicted, nil
+}
+
+// PredictProba returns the probability of each label for each row. The
+// columns are in the order returned by Classes
+func (this *BoostingClassifier) PredictProba(table *util.Table) (*mat.Dense, error) {
+	if this.trees == nil {
+		return nil, ErrNotFitted
+	}
+	scores := mat.NewDense(len(table.Rows), len(this.classes), nil)
+	for i := range table.Rows {
+		scores.SetRow(i, this.prior)
+	}
+	for _, round := range this.trees {
+		for c, t := range round {
+			if predicted, err := t.Predict(table); err != nil {
+				return nil, err
+			} else {
+				for i, value := range predicted {
+					scores.Set(i, c, scores.At(i, c)+this.learningRate()*value)
+				}
+			}
+		}
+	}
+	return softmax(scores), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// REGRESSOR
+
+// Fit boosts trees from the feature columns of the table to predict the
+// values in the target column. Rows with a missing value are ignored
+func (this *BoostingRegressor) Fit(table *util.Table, features []string, target string) error {
+	table, y, err := valued(table, target)
+	if err != nil {
+		return err
+	}
+	r, train, validation, err := this.split(table, features)
+	if err != nil {
+		return err
+	}
+
+	// Start from the mean value
+	this.mean = 0
+	for _, i := range train {
+		this.mean += y[i] / float64(len(train))
+	}
+	predicted := make([]float64, len(y))
+	for i := range predicted {
+		predicted[i] = this.mean
+	}
+
+	// Fit a tree to the residuals, which are the gradient of the squared
+	// error
+	this.trees = make([]*tree.Regressor, 0, this.rounds())
+	err = this.boost(r, train, validation, func(sample []bool) error {
+		residuals := make([]float64, len(y))
+		for i := range y {
+			if sample[i] {
+				residuals[i] = y[i] - predicted[i]
+			} else {
+				residuals[i] = math.NaN()
+			}
+		}
+		t := tree.NewRegressor(this.treeConfig(r))
+		if err := t.FitValues(table, features, residuals); err != nil {
+			return err
+		} else if values, err := t.Predict(table); err != nil {
+			return err
+		} else {
+			for i, value := range values {
+				predicted[i] += this.learningRate() * value
+			}
+		}
+		this.trees = append(this.trees, t)
+		return nil
+	}, func(rows []int) float64 {
+		observed, values := make([]float64, len(rows)), make([]float64, len(rows))
+		for j, i := range rows {
+			observed[j], values[j] = y[i], predicted[i]
+		}
+		return meanSquaredError(observed, values)
+	})
+	if err != nil {
+		this.trees = nil
+		return err
+	}
+	this.trees = this.trees[:this.best+1]
+	return nil
+}
+
+// Predict returns the predicted value for each row
+func (this *BoostingRegressor) Predict(table *util.Table) ([]float64, error) {
+	if this.trees == nil {
+		return nil, ErrNotFitted
+	}
+	predicted := make([]float64, len(table.Rows))
+	for i := range predicted {
+		predicted[i] = this.mean
+	}
+	for _, t := range this.trees {
+		if values, err := t.Predict(table); err != nil {
+			return nil, err
+		} else {
+			for i, value := range values {
+				predicted[i] += this.learningRate() * value
+			}
+		}
+	}
+	return predicted, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// BOOSTING
+
+// Features returns the names of the features used for boosting
+func (this *boosting) Features() []string {
+	return this.features
+}
+
+// Rounds returns the number of rounds kept, which is the round with the
+// lowest validation loss when boosting stops early
+func (this *boosting) Rounds() int {
+	return this.best + 1
+}
+
+// Loss returns the training loss and the validation loss after each
+// round. The validation loss is empty when no rows are held back
+func (this *boosting) Loss() ([]float64, []float64) {
+	return this.train, this.validation
+}
+
+// Stringify
+func (this *boosting) String() string {
+	return fmt.Sprintf("boosting{ rounds=%v learning_rate=%v subsample=%v max_depth=%v validation=%v kept=%v }", this.rounds(), this.learningRate(), this.subsample(), this.treeConfig(nil).MaxDepth, this.config.Validation, this.Rounds())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// split checks the configuration and returns the random source and the
+// rows for training and validation
+func (this *boosting) split(table *util.Table, features []string) (*rand.Rand, []int, []int, error) {
+	if len(features) == 0 {
+		return nil, nil, nil, fmt.Errorf("%v: Expected at least one feature", ErrBadParameter)
+	} else if this.config.LearningRate < 0 {
+		return nil, nil, nil, fmt.Errorf("%v: Learning rate cannot be negative", ErrBadParameter)
+	} else if this.config.Subsample < 0 || this.config.Subsample > 1 {
+		return nil, nil, nil, fmt.Errorf("%v: Subsample must be between zero and one", ErrBadParameter)
+	} else if this.config.Validation < 0 || this.config.Validation >= 1 {
+		return nil, nil, nil, fmt.Errorf("%v: Validation must be less than one", ErrBadParameter)
+	}
+	this.features = append([]string(nil), features...)
+	this.train, this.validation, this.best = nil, nil, 0
+	r := rand.New(rand.NewSource(this.config.Seed))
+	rows := r.Perm(len(table.Rows))
+	n := int(math.Round(this.config.Validation * float64(len(rows))))
+	if n == len(rows) {
+		return nil, nil, nil, ErrEmpty
+	}
+	return r, rows[n:], rows[:n], nil
+}
+
+// boost calls fit for each round with the training rows to sample, and
+// records the loss. It stops early when the validation loss has not
+// improved for the patience rounds
+func (this *boosting) boost(r *rand.Rand, train, validation []int, fit func([]bool) error, loss func([]int) float64) error {
+	n := len(train) + len(validation)
+	size := int(math.Max(1, math.Round(this.subsample()*float64(len(train)))))
+	wait := 0
+	for round := 0; round < this.rounds(); round++ {
+		sample := make([]bool, n)
+		for _, k := range r.Perm(len(train))[:size] {
+			sample[train[k]] = true
+		}
+		if err := fit(sample); err != nil {
+			return err
+		}
+		this.train = append(this.train, loss(train))
+		if len(validation) == 0 {
+			this.best = round
+			continue
+		}
+		this.validation = append(this.validation, loss(validation))
+		if this.validation[round] < this.validation[this.best] || round == 0 {
+			this.best, wait = round, 0
+		} else if wait++; wait >= this.patience() {
+			break
+		}
+	}
+	return nil
+}
+
+// treeConfig returns the configuration for the tree in each round
+func (this *boosting) treeConfig(r *rand.Rand) tree.Config {
+	config := this.config.Tree
+	config.Criterion = tree.CRITERION_MSE
+	if config.MaxDepth == 0 {
+		config.MaxDepth = DEFAULT_MAX_DEPTH
+	}
+	if r != nil {
+		config.Seed = r.Int63()
+	}
+	return config
+}
+
+func (this *boosting) rounds() int {
+	if this.config.Rounds == 0 {
+		return DEFAULT_ROUNDS
+	}
+	return int(this.config.Rounds)
+}
+
+func (this *boosting) learningRate() float64 {
+	if this.config.LearningRate == 0 {
+		return DEFAULT_LEARNING_RATE
+	}
+	return this.config.LearningRate
+}
+
+func (this *boosting) subsample() float64 {
+	if this.config.Subsample == 0 {
+		return 1
+	}
+	return this.config.Subsample
+}
+
+func (this *boosting) patience() int {
+	if this.config.Patience == 0 {
+		return DEFAULT_PATIENCE
+	}
+	return int(this.config.Patience)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// softmax returns the probabilities for each row of scores
+func softmax(scores *mat.Dense) *mat.Dense {
+	rows, cols := scores.Dims()
+	proba := mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		row := scores.RawRowView(i)
+		max := row[argmax(row)]
+		var sum float64
+		for c, score := range row {
+			proba.Set(i, c, math.Exp(score-max))
+			sum += proba.At(i, c)
+		}
+		for c := range row {
+			proba.Set(i, c, proba.At(i, c)/sum)
+		}
+	}
+	return proba
+}
@@ -0,0 +1,132 @@
+/*
+	Package ensemble implements ensembles of decision trees: random forests,
+	which average trees grown in parallel on bootstrap samples of the rows,
+	and gradient boosting, which adds shallow trees one at a time to correct
+	the errors of the trees before them.
+*/
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// labelled returns the rows of the table with a label in the target
+// column, and the labels for those rows
+func labelled(table *util.Table, target string) (*util.Table, []string, error) {
+	labels, err := table.StringColumn(target, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	rows := make([]int, 0, len(labels))
+	values := make([]string, 0, len(labels))
+	for i, label := range labels {
+		if label != "" {
+			rows = append(rows, i)
+			values = append(values, label)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil, ErrEmpty
+	} else if table, err = table.Subsample(rows); err != nil {
+		return nil, nil, err
+	}
+	return table, values, nil
+}
+
+// valued returns the rows of the table with a value in the target
+// column, and the values for those rows
+func valued(table *util.Table, target string) (*util.Table, []float64, error) {
+	y, err := table.FloatColumn(target, math.NaN())
+	if err != nil {
+		return nil, nil, err
+	}
+	rows := make([]int, 0, len(y))
+	values := make([]float64, 0, len(y))
+	for i, value := range y {
+		if math.IsNaN(value) == false {
+			rows = append(rows, i)
+			values = append(values, value)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil, ErrEmpty
+	} else if table, err = table.Subsample(rows); err != nil {
+		return nil, nil, err
+	}
+	return table, values, nil
+}
+
+// subsample returns a new table with the rows, where each value is copied.
+// Values cache the result of parsing them as numbers, so trees grown in
+// parallel should not share values
+func subsample(table *util.Table, rows []int) (*util.Table, error) {
+	that, err := util.NewTable(table.Columns...)
+	if err != nil {
+		return nil, err
+	}
+	that.SetTimeLayouts(table.TimeLayouts()...)
+	that.Rows = make([][]*util.Value, len(rows))
+	for i, row := range rows {
+		if row < 0 || row >= len(table.Rows) {
+			return nil, util.ErrOutOfRange
+		}
+		that.Rows[i] = make([]*util.Value, len(table.Rows[row]))
+		for j, value := range table.Rows[row] {
+			if value != nil {
+				copied := *value
+				that.Rows[i][j] = &copied
+			}
+		}
+	}
+	return that, nil
+}
+
+// unique returns the unique labels in sorted order
+func unique(labels []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, label := range labels {
+		if seen[label] == false {
+			seen[label] = true
+			result = append(result, label)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// argmax returns the index of the largest value, or the first index when
+// values are equal
+func argmax(values []float64) int {
+	best := 0
+	for i, value := range values {
+		if value > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// meanSquaredError returns the mean squared difference between observed
+// and predicted values
+func meanSquaredError(observed, predicted []float64) float64 {
+	var sum float64
+	for i := range observed {
+		sum += (observed[i] - predicted[i]) * (observed[i] - predicted[i])
+	}
+	return sum / float64(len(observed))
+}
@@ -0,0 +1,385 @@
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/djthorpe/MachineLearning/tree"
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ForestConfig is the configuration for a random forest
+type ForestConfig struct {
+	// Trees is the number of trees in the forest
+	Trees uint
+
+	// Tree is the configuration for each tree. When MaxFeatures is zero,
+	// the square root of the number of features are candidates for each
+	// split in a classifier and a third of the features in a regressor
+	Tree tree.Config
+
+	// Workers is the number of trees grown in parallel, which defaults
+	// to the number of CPUs
+	Workers uint
+
+	// Seed for the bootstrap samples and the candidate features
+	Seed int64
+}
+
+// ForestClassifier predicts the label with the largest mean probability
+// across the trees
+type ForestClassifier struct {
+	forest
+	classes []string
+	trees   []*tree.Classifier
+}
+
+// ForestRegressor predicts the mean value across the trees
+type ForestRegressor struct {
+	forest
+	trees []*tree.Regressor
+}
+
+// forest is common to classifiers and regressors
+type forest struct {
+	config      ForestConfig
+	features    []string
+	importances []float64
+	oob         float64
+}
+
+// bag is a tree grown on a bootstrap sample, with the rows not in the
+// sample and its predictions for them
+type bag struct {
+	oob       []int
+	predicted *mat.Dense
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_TREES = 100
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewForestClassifier returns a random forest classifier with the
+// configuration
+func NewForestClassifier(config ForestConfig) *ForestClassifier {
+	return &ForestClassifier{forest: forest{config: config}}
+}
+
+// NewForestRegressor returns a random forest regressor with the
+// configuration
+func NewForestRegressor(config ForestConfig) *ForestRegressor {
+	return &ForestRegressor{forest: forest{config: config}}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CLASSIFIER
+
+// Fit grows the trees in parallel from the feature columns of the table to
+// predict the labels in the target column, and computes the out-of-bag
+// error. Rows with a missing label are ignored
+func (this *ForestClassifier) Fit(table *util.Table, features []string, target string) error {
+	table, labels, err := labelled(table, target)
+	if err != nil {
+		return err
+	}
+	this.classes = unique(labels)
+	this.trees = make([]*tree.Classifier, this.size())
+	max_features := uint(math.Max(1, math.Round(math.Sqrt(float64(len(features))))))
+	bags, err := this.grow(table, features, max_features, func(i int, sample *util.Table, config tree.Config) error {
+		this.trees[i] = tree.NewClassifier(config)
+		return this.trees[i].Fit(sample, features, target)
+	}, func(i int, oob *util.Table) (*mat.Dense, error) {
+		return this.predictTree(i, oob)
+	})
+	if err != nil {
+		this.trees = nil
+		return err
+	}
+
+	// Compute the out-of-bag error from the votes of the trees which
+	// did not see each row
+	votes := mat.NewDense(len(labels), len(this.classes), nil)
+	for _, b := range bags {
+		for j, row := range b.oob {
+			for c := range this.classes {
+				votes.Set(row, c, votes.At(row, c)+b.predicted.At(j, c))
+			}
+		}
+	}
+	var wrong, total int
+	for i, label := range labels {
+		if mat.Sum(votes.RowView(i)) == 0 {
+			continue
+		}
+		total++
+		if this.classes[argmax(votes.RawRowView(i))] != label {
+			wrong++
+		}
+	}
+	this.oob = math.NaN()
+	if total > 0 {
+		this.oob = float64(wrong) / float64(total)
+	}
+	this.importances = make([]float64, len(features))
+	for _, t := range this.trees {
+		for j, importance := range t.Importances() {
+			this.importances[j] += importance / float64(len(this.trees))
+		}
+	}
+	return nil
+}
+
+// Classes returns the labels in sorted order, which are the columns
+// returned by PredictProba
+func (this *ForestClassifier) Classes() []string {
+	return this.classes
+}
+
+// Predict returns the label with the largest mean probability across the
+// trees for each row
+func (this *ForestClassifier) Predict(table *util.Table) ([]string, error) {
+	proba, err := this.PredictProba(table)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := proba.Dims()
+	predicted := make([]string, rows)
+	for i := range predicted {
+		predicted[i] = this.classes[argmax(proba.RawRowView(i))]
+	}
+	return predicted, nil
+}
+
+// PredictProba returns the mean probability of each label across the trees
+// for each row. The columns are in the order returned by Classes
+func (this *ForestClassifier) PredictProba(table *util.Table) (*mat.Dense, error) {
+	if this.trees == nil {
+		return nil, ErrNotFitted
+	}
+	proba := mat.NewDense(len(table.Rows), len(this.classes), nil)
+	for i := range this.trees {
+		if predicted, err := this.predictTree(i, table); err != nil {
+			return nil, err
+		} else {
+			proba.Add(proba, predicted)
+		}
+	}
+	proba.Scale(1/float64(len(this.trees)), proba)
+	return proba, nil
+}
+
+// predictTree returns the probabilities from one tree with the columns
+// in the order of the forest classes, since a tree only has the labels in
+// its bootstrap sample
+func (this *ForestClassifier) predictTree(i int, table *util.Table) (*mat.Dense, error) {
+	proba, err := this.trees[i].PredictProba(table)
+	if err != nil {
+		return nil, err
+	}
+	class := make(map[string]int, len(this.classes))
+	for c, label := range this.classes {
+		class[label] = c
+	}
+	result := mat.NewDense(len(table.Rows), len(this.classes), nil)
+	for c, label := range this.trees[i].Classes() {
+		result.SetCol(class[label], mat.Col(nil, c, proba))
+	}
+	return result, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// REGRESSOR
+
+// Fit grows the trees in parallel from the feature columns of the table to
+// predict the values in the target column, and computes the out-of-bag
+// error. Rows with a missing value are ignored
+func (this *ForestRegressor) Fit(table *util.Table, features []string, target string) error {
+	table, values, err := valued(table, target)
+	if err != nil {
+		return err
+	}
+	this.trees = make([]*tree.Regressor, this.size())
+	max_features := uint(math.Max(1, math.Round(float64(len(features))/3)))
+	bags, err := this.grow(table, features, max_features, func(i int, sample *util.Table, config tree.Config) error {
+		this.trees[i] = tree.NewRegressor(config)
+		return this.trees[i].Fit(sample, features, target)
+	}, func(i int, oob *util.Table) (*mat.Dense, error) {
+		if predicted, err := this.trees[i].Predict(oob); err != nil {
+			return nil, err
+		} else {
+			return mat.NewDense(len(predicted), 1, predicted), nil
+		}
+	})
+	if err != nil {
+		this.trees = nil
+		return err
+	}
+
+	// Compute the out-of-bag error from the mean of the trees which did
+	// not see each row
+	sum, count := make([]float64, len(values)), make([]float64, len(values))
+	for _, b := range bags {
+		for j, row := range b.oob {
+			sum[row] += b.predicted.At(j, 0)
+			count[row]++
+		}
+	}
+	observed, predicted := make([]float64, 0, len(values)), make([]float64, 0, len(values))
+	for i := range values {
+		if count[i] > 0 {
+			observed = append(observed, values[i])
+			predicted = append(predicted, sum[i]/count[i])
+		}
+	}
+	this.oob = math.NaN()
+	if len(observed) > 0 {
+		this.oob = meanSquaredError(observed, predicted)
+	}
+	this.importances = make([]float64, len(features))
+	for _, t := range this.trees {
+		for j, importance := range t.Importances() {
+			this.importances[j] += importance / float64(len(this.trees))
+		}
+	}
+	return nil
+}
+
+// Predict returns the mean value across the trees for each row
+func (this *ForestRegressor) Predict(table *util.Table) ([]float64, error) {
+	if this.trees == nil {
+		return nil, ErrNotFitted
+	}
+	predicted := make([]float64, len(table.Rows))
+	for _, t := range this.trees {
+		if values, err := t.Predict(table); err != nil {
+			return nil, err
+		} else {
+			for i, value := range values {
+				predicted[i] += value / float64(len(this.trees))
+			}
+		}
+	}
+	return predicted, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// FOREST
+
+// Features returns the names of the features used to grow the forest
+func (this *forest) Features() []string {
+	return this.features
+}
+
+// Importances returns the mean importance of each feature across the trees
+func (this *forest) Importances() []float64 {
+	return this.importances
+}
+
+// OOBError returns the out-of-bag error, which is estimated for each row
+// from the trees which did not have the row in their bootstrap sample. It
+// is the proportion of misclassified rows for a classifier and the mean
+// squared error for a regressor, or NaN if every row was in every sample
+func (this *forest) OOBError() float64 {
+	return this.oob
+}
+
+// Stringify
+func (this *forest) String() string {
+	return fmt.Sprintf("forest{ trees=%v max_depth=%v max_features=%v workers=%v oob_error=%.4f }", this.size(), this.config.Tree.MaxDepth, this.config.Tree.MaxFeatures, this.workers(), this.oob)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// grow fits each tree to a bootstrap sample of the table using a pool of
+// workers, and predicts the rows which are not in each sample. The random
+// source for each tree is seeded from its index so that the forest is the
+// same for any number of workers
+func (this *forest) grow(table *util.Table, features []string, max_features uint, fit func(int, *util.Table, tree.Config) error, predict func(int, *util.Table) (*mat.Dense, error)) ([]bag, error) {
+	if len(features) == 0 {
+		return nil, fmt.Errorf("%v: Expected at least one feature", ErrBadParameter)
+	}
+	this.features = append([]string(nil), features...)
+	n := len(table.Rows)
+	bags := make([]bag, this.size())
+	errs := make([]error, this.size())
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < this.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				errs[i] = this.bag(i, table, n, max_features, &bags[i], fit, predict)
+			}
+		}()
+	}
+	for i := range bags {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bags, nil
+}
+
+// bag fits tree i to a bootstrap sample and predicts the out-of-bag rows
+func (this *forest) bag(i int, table *util.Table, n int, max_features uint, b *bag, fit func(int, *util.Table, tree.Config) error, predict func(int, *util.Table) (*mat.Dense, error)) error {
+	r := rand.New(rand.NewSource(this.config.Seed + int64(i)))
+	sample, inbag := make([]int, n), make([]bool, n)
+	for k := range sample {
+		sample[k] = r.Intn(n)
+		inbag[sample[k]] = true
+	}
+	config := this.config.Tree
+	config.Seed = r.Int63()
+	if config.MaxFeatures == 0 {
+		config.MaxFeatures = max_features
+	}
+	if s, err := subsample(table, sample); err != nil {
+		return err
+	} else if err := fit(i, s, config); err != nil {
+		return err
+	}
+	for row := range inbag {
+		if inbag[row] == false {
+			b.oob = append(b.oob, row)
+		}
+	}
+	if len(b.oob) > 0 {
+		if oob, err := subsample(table, b.oob); err != nil {
+			return err
+		} else if b.predicted, err = predict(i, oob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *forest) size() int {
+	if this.config.Trees == 0 {
+		return DEFAULT_TREES
+	}
+	return int(this.config.Trees)
+}
+
+func (this *forest) workers() int {
+	if this.config.Workers == 0 {
+		return runtime.NumCPU()
+	}
+	return int(this.config.Workers)
+}
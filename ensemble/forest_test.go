@@ -0,0 +1,54 @@
+package ensemble
+
+import (
+	"testing"
+
+	"github.com/djthorpe/MachineLearning/tree"
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func Test_Forest_001(t *testing.T) {
+	// Trees grown in parallel do not share values with each other, which
+	// is checked by running the test with -race
+	table, _ := util.NewTable()
+	if err := table.ReadCSV("../chapter2/iris.csv", false, true, true); err != nil {
+		t.Fatal(err)
+	}
+	features := []string{"SepalLength", "SepalWidth", "PetalLength", "PetalWidth"}
+	config := ForestConfig{Trees: 50, Workers: 8, Tree: tree.Config{MaxDepth: 4}, Seed: 1}
+
+	classifier := NewForestClassifier(config)
+	if err := classifier.Fit(table, features, "Name"); err != nil {
+		t.Fatal(err)
+	} else if predicted, err := classifier.Predict(table); err != nil {
+		t.Fatal(err)
+	} else if len(predicted) != len(table.Rows) {
+		t.Errorf("Expected %v predictions, got %v", len(table.Rows), len(predicted))
+	}
+
+	regressor := NewForestRegressor(config)
+	if err := regressor.Fit(table, features[:3], "PetalWidth"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Forest_002(t *testing.T) {
+	// The forest is the same for any number of workers
+	table, _ := util.NewTable("x", "y", "label")
+	for _, row := range [][]string{{"1", "2", "a"}, {"2", "1", "a"}, {"3", "5", "b"}, {"4", "4", "b"}, {"5", "8", "c"}, {"6", "7", "c"}} {
+		table.AppendStringRow(row, true)
+	}
+	var oob []float64
+	for _, workers := range []uint{1, 2} {
+		forest := NewForestClassifier(ForestConfig{Trees: 10, Workers: workers, Seed: 2})
+		if err := forest.Fit(table, []string{"x", "y"}, "label"); err != nil {
+			t.Fatal(err)
+		}
+		oob = append(oob, forest.OOBError())
+	}
+	if oob[0] != oob[1] {
+		t.Errorf("Expected the same out-of-bag error, got %v", oob)
+	}
+}
@@ -0,0 +1,88 @@
+package forecast
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ACF returns the autocorrelation of a series for each lag from zero to
+// lags, where the autocorrelation at lag zero is one
+func ACF(series []float64, lags uint) []float64 {
+	acf := make([]float64, lags+1)
+	if len(series) == 0 {
+		return acf
+	}
+	mean := mean(series)
+	var c0 float64
+	for _, value := range series {
+		c0 += (value - mean) * (value - mean)
+	}
+	for k := range acf {
+		if c0 == 0 || k >= len(series) {
+			continue
+		}
+		var ck float64
+		for t := k; t < len(series); t++ {
+			ck += (series[t] - mean) * (series[t-k] - mean)
+		}
+		acf[k] = ck / c0
+	}
+	return acf
+}
+
+// PACF returns the partial autocorrelation of a series for each lag from
+// zero to lags, which is the correlation at each lag after removing the
+// effect of shorter lags, computed with the Durbin-Levinson recursion
+func PACF(series []float64, lags uint) []float64 {
+	pacf, _ := durbinLevinson(ACF(series, lags), lags)
+	return pacf
+}
+
+// ConfidenceBound returns the bound for the autocorrelation of a series of
+// n independent values at a confidence level between zero and one. Larger
+// autocorrelations are significant
+func ConfidenceBound(n int, level float64) float64 {
+	if n == 0 {
+		return math.NaN()
+	}
+	return distuv.UnitNormal.Quantile(0.5+level/2) / math.Sqrt(float64(n))
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// durbinLevinson returns the partial autocorrelations from the
+// autocorrelations, and the coefficients of the autoregressive model of
+// order p which are the Yule-Walker estimates
+func durbinLevinson(acf []float64, p uint) ([]float64, []float64) {
+	pacf := make([]float64, p+1)
+	pacf[0] = 1
+	phi := make([]float64, 0, p)
+	v := 1.0
+	for k := 1; k <= int(p) && k < len(acf); k++ {
+		// Compute the reflection coefficient for order k
+		num := acf[k]
+		for j := 1; j < k; j++ {
+			num -= phi[j-1] * acf[k-j]
+		}
+		if v <= 0 {
+			break
+		}
+		a := num / v
+		// Update the coefficients for order k
+		next := make([]float64, k)
+		for j := 1; j < k; j++ {
+			next[j-1] = phi[j-1] - a*phi[k-j-1]
+		}
+		next[k-1] = a
+		phi = next
+		v *= 1 - a*a
+		pacf[k] = a
+	}
+	for len(phi) < int(p) {
+		phi = append(phi, 0)
+	}
+	return pacf, phi
+}
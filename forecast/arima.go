@@ -0,0 +1,228 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ARIMA is an autoregressive integrated moving average model. The series
+// is differenced D times, and each differenced value is predicted from the
+// P previous values and the Q previous one-step errors
+type ARIMA struct {
+	// P, D and Q are the orders of the autoregressive, differencing and
+	// moving average parts of the model
+	P, D, Q uint
+
+	// Mean, AR and MA are set when the model is fitted. The mean is only
+	// estimated when the series is not differenced
+	Mean   float64
+	AR, MA []float64
+
+	levels    [][]float64
+	residuals []float64
+	fitted    []float64
+	sigma2    float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewAR returns an autoregressive model of order p
+func NewAR(p uint) *ARIMA {
+	return &ARIMA{P: p}
+}
+
+// NewARIMA returns an ARIMA model of order (p,d,q)
+func NewARIMA(p, d, q uint) *ARIMA {
+	return &ARIMA{P: p, D: d, Q: q}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Fit estimates the coefficients by conditional sum of squares, starting
+// from the Yule-Walker estimates of the autoregressive coefficients
+func (this *ARIMA) Fit(series []float64) error {
+	series, err := copySeries(series, int(this.P+this.D+this.Q)+2)
+	if err != nil {
+		return err
+	}
+
+	// Difference the series
+	this.levels = [][]float64{series}
+	for d := uint(0); d < this.D; d++ {
+		this.levels = append(this.levels, difference(this.levels[d]))
+	}
+	z := this.levels[this.D]
+	this.Mean = 0
+	if this.D == 0 {
+		this.Mean = mean(z)
+	}
+
+	// Estimate the coefficients
+	centred := make([]float64, len(z))
+	for i := range z {
+		centred[i] = z[i] - this.Mean
+	}
+	_, phi := durbinLevinson(ACF(centred, this.P), this.P)
+	x := append(phi, make([]float64, this.Q)...)
+	if len(x) > 0 {
+		x = minimize(func(x []float64) float64 {
+			sse, _ := this.css(z, x[:this.P], x[this.P:], nil)
+			return sse
+		}, x)
+	}
+	this.AR = append([]float64(nil), x[:this.P]...)
+	this.MA = append([]float64(nil), x[this.P:]...)
+
+	// Compute the residuals and the one-step predictions
+	this.residuals = make([]float64, len(z))
+	sse, n := this.css(z, this.AR, this.MA, this.residuals)
+	this.fitted = make([]float64, len(series))
+	for t := range this.fitted {
+		if i := t - int(this.D); i < int(this.P) {
+			this.fitted[t] = math.NaN()
+		} else {
+			this.fitted[t] = series[t] - this.residuals[i]
+		}
+	}
+	parameters := int(this.P + this.Q)
+	if this.D == 0 {
+		parameters++
+	}
+	if n > parameters {
+		n -= parameters
+	}
+	this.sigma2 = sse / float64(n)
+
+	// Return success
+	return nil
+}
+
+// Fitted returns the one-step-ahead predictions
+func (this *ARIMA) Fitted() []float64 {
+	return this.fitted
+}
+
+// Forecast returns the forecasts of the differenced series, where future
+// errors are zero, which are then integrated
+func (this *ARIMA) Forecast(h uint, level float64) (*Forecast, error) {
+	if this.fitted == nil {
+		return nil, ErrNotFitted
+	}
+
+	// Forecast the differenced series
+	z := append([]float64(nil), this.levels[this.D]...)
+	e := append([]float64(nil), this.residuals...)
+	n := len(z)
+	for i := 0; i < int(h); i++ {
+		t := len(z)
+		z = append(z, this.predict(z, e, t))
+		e = append(e, 0)
+	}
+	forecast := z[n:]
+
+	// Integrate the forecasts
+	for d := int(this.D) - 1; d >= 0; d-- {
+		last := this.levels[d][len(this.levels[d])-1]
+		integrated := make([]float64, len(forecast))
+		for i := range forecast {
+			last += forecast[i]
+			integrated[i] = last
+		}
+		forecast = integrated
+	}
+
+	return newForecast(forecast, this.sigma2, this.psi(h), level)
+}
+
+// Stringify
+func (this *ARIMA) String() string {
+	return fmt.Sprintf("arima{ order=(%v,%v,%v) mean=%v ar=%v ma=%v sigma2=%v }", this.P, this.D, this.Q, this.Mean, this.AR, this.MA, this.sigma2)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// css returns the conditional sum of squares for a differenced series,
+// where the first P errors are zero, and the number of errors. The
+// errors are written to residuals if it is not nil
+func (this *ARIMA) css(z, ar, ma, residuals []float64) (float64, int) {
+	if residuals == nil {
+		residuals = make([]float64, len(z))
+	}
+	saved := [2][]float64{this.AR, this.MA}
+	this.AR, this.MA = ar, ma
+	defer func() {
+		this.AR, this.MA = saved[0], saved[1]
+	}()
+	var sse float64
+	var n int
+	for t := range z {
+		if t < int(this.P) {
+			residuals[t] = 0
+			continue
+		}
+		residuals[t] = z[t] - this.predict(z, residuals, t)
+		sse += residuals[t] * residuals[t]
+		n++
+	}
+	return sse, n
+}
+
+// predict returns the prediction of the differenced value at t from the
+// previous values and errors
+func (this *ARIMA) predict(z, e []float64, t int) float64 {
+	prediction := this.Mean
+	for i, phi := range this.AR {
+		if t-i-1 >= 0 {
+			prediction += phi * (z[t-i-1] - this.Mean)
+		}
+	}
+	for j, theta := range this.MA {
+		if t-j-1 >= 0 {
+			prediction += theta * e[t-j-1]
+		}
+	}
+	return prediction
+}
+
+// psi returns the first h weights of the errors in the moving average
+// representation of the model, including the differencing
+func (this *ARIMA) psi(h uint) []float64 {
+	// Multiply the autoregressive polynomial by (1-B) for each difference
+	poly := []float64{1}
+	for _, phi := range this.AR {
+		poly = append(poly, -phi)
+	}
+	for d := uint(0); d < this.D; d++ {
+		next := make([]float64, len(poly)+1)
+		for i, c := range poly {
+			next[i] += c
+			next[i+1] -= c
+		}
+		poly = next
+	}
+	psi := make([]float64, h)
+	for j := range psi {
+		if j == 0 {
+			psi[j] = 1
+			continue
+		}
+		if j <= len(this.MA) {
+			psi[j] = this.MA[j-1]
+		}
+		for i := 1; i < len(poly) && i <= j; i++ {
+			psi[j] -= poly[i] * psi[j-i]
+		}
+	}
+	return psi
+}
+
+// difference returns the differences between consecutive values
+func difference(series []float64) []float64 {
+	diff := make([]float64, len(series)-1)
+	for i := range diff {
+		diff[i] = series[i+1] - series[i]
+	}
+	return diff
+}
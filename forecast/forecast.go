@@ -0,0 +1,148 @@
+/*
+	Package forecast implements classical models for forecasting a time
+	series: moving average, simple, double (Holt) and triple (Holt-Winters)
+	exponential smoothing and ARIMA. Parameters which are not set are
+	estimated by minimising the squared one-step-ahead errors, and forecasts
+	are returned with prediction intervals.
+*/
+package forecast
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Model is a forecasting model for a series of values at regular intervals
+type Model interface {
+	// Fit estimates the model parameters from a series
+	Fit(series []float64) error
+
+	// Fitted returns the one-step-ahead prediction for each value in the
+	// fitted series, which is NaN where no prediction can be made
+	Fitted() []float64
+
+	// Forecast returns the forecasts for the next h values after the
+	// fitted series, with prediction intervals at a confidence level
+	// between zero and one
+	Forecast(h uint, level float64) (*Forecast, error)
+}
+
+// Forecast is a forecast for each step after the end of a series, with
+// the lower and upper bounds of the prediction interval
+type Forecast struct {
+	Mean, Lower, Upper []float64
+	Level              float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// The maximum number of evaluations when estimating parameters
+	MAX_EVALUATIONS = 5000
+)
+
+var (
+	ErrEmpty        = fmt.Errorf("Not enough values in series")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// newForecast returns a forecast from the point forecasts, the variance of
+// the one-step errors and the weights of the errors in the model's
+// moving average representation, where the variance of the forecast h steps
+// ahead is the variance multiplied by the sum of the first h squared weights
+func newForecast(mean []float64, sigma2 float64, psi []float64, level float64) (*Forecast, error) {
+	if level <= 0 || level >= 1 {
+		return nil, fmt.Errorf("%v: Level should be between zero and one", ErrBadParameter)
+	}
+	z := distuv.UnitNormal.Quantile(0.5 + level/2)
+	this := &Forecast{
+		Mean:  mean,
+		Lower: make([]float64, len(mean)),
+		Upper: make([]float64, len(mean)),
+		Level: level,
+	}
+	var sum float64
+	for h := range mean {
+		if h < len(psi) {
+			sum += psi[h] * psi[h]
+		}
+		width := z * math.Sqrt(sigma2*sum)
+		this.Lower[h] = mean[h] - width
+		this.Upper[h] = mean[h] + width
+	}
+	return this, nil
+}
+
+// minimize returns the parameters which minimise a function, starting
+// at x, using the Nelder-Mead method
+func minimize(f func([]float64) float64, x []float64) []float64 {
+	settings := optimize.DefaultSettingsLocal()
+	settings.FuncEvaluations = MAX_EVALUATIONS
+	if result, _ := optimize.Minimize(optimize.Problem{Func: f}, x, settings, &optimize.NelderMead{}); result == nil || math.IsInf(result.F, 0) || math.IsNaN(result.F) {
+		return x
+	} else {
+		return result.X
+	}
+}
+
+// logistic maps any value into the range zero to one, so that smoothing
+// parameters can be estimated without constraints
+func logistic(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// logit is the inverse of logistic
+func logit(p float64) float64 {
+	return math.Log(p / (1 - p))
+}
+
+// sumOfSquares returns the sum of squared errors between the values and
+// the predictions, ignoring predictions which are NaN, and the number of
+// errors
+func sumOfSquares(series, predicted []float64) (float64, int) {
+	var sse float64
+	var n int
+	for i := range series {
+		if math.IsNaN(predicted[i]) {
+			continue
+		}
+		sse += (series[i] - predicted[i]) * (series[i] - predicted[i])
+		n++
+	}
+	return sse, n
+}
+
+// variance returns the variance of the one-step errors, adjusted for the
+// number of estimated parameters
+func variance(series, predicted []float64, parameters int) float64 {
+	sse, n := sumOfSquares(series, predicted)
+	if n > parameters {
+		n -= parameters
+	}
+	if n == 0 {
+		return 0
+	}
+	return sse / float64(n)
+}
+
+// copySeries returns a copy of a series, or an error if any values are NaN
+// or there are fewer than n values
+func copySeries(series []float64, n int) ([]float64, error) {
+	if len(series) < n {
+		return nil, fmt.Errorf("%v: Expected at least %v values", ErrEmpty, n)
+	}
+	for _, value := range series {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("%v: Series contains missing values", ErrBadParameter)
+		}
+	}
+	return append([]float64(nil), series...), nil
+}
@@ -0,0 +1,419 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// MovingAverage forecasts every future value as the mean of the most
+// recent values
+type MovingAverage struct {
+	// Window is the number of values in the mean
+	Window uint
+
+	series, fitted []float64
+	sigma2         float64
+}
+
+// SimpleExponentialSmoothing forecasts every future value as the level,
+// which is updated with each value as a weighted average of the value and
+// the previous level
+type SimpleExponentialSmoothing struct {
+	// Alpha is the weight of each new value between zero and one, or zero
+	// to estimate it
+	Alpha float64
+
+	alpha, level float64
+	fitted       []float64
+	sigma2       float64
+}
+
+// Holt is double exponential smoothing, which adds a trend to the level so
+// that forecasts continue along a straight line
+type Holt struct {
+	// Alpha and Beta are the weights for the level and trend between zero
+	// and one, or zero to estimate them
+	Alpha, Beta float64
+
+	alpha, beta  float64
+	level, trend float64
+	fitted       []float64
+	sigma2       float64
+}
+
+// HoltWinters is triple exponential smoothing, which adds a seasonal
+// component with a fixed period to the level and trend. The seasonal
+// component is either added to or multiplied by the level
+type HoltWinters struct {
+	// Alpha, Beta and Gamma are the weights for the level, trend and
+	// seasonal components between zero and one, or zero to estimate them
+	Alpha, Beta, Gamma float64
+
+	// Period is the number of values in each season, and at least two
+	// seasons are required to fit the model
+	Period uint
+
+	// Multiplicative seasonality, where the seasonal variation is
+	// proportional to the level
+	Multiplicative bool
+
+	alpha, beta, gamma float64
+	level, trend       float64
+	season             []float64
+	fitted             []float64
+	sigma2             float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// MOVING AVERAGE
+
+// Fit stores the series and computes the one-step errors
+func (this *MovingAverage) Fit(series []float64) error {
+	if this.Window == 0 {
+		return fmt.Errorf("%v: Window should be greater than zero", ErrBadParameter)
+	}
+	series, err := copySeries(series, int(this.Window)+1)
+	if err != nil {
+		return err
+	}
+	this.series = series
+	this.fitted = make([]float64, len(series))
+	for t := range series {
+		if t < int(this.Window) {
+			this.fitted[t] = math.NaN()
+		} else {
+			this.fitted[t] = mean(series[t-int(this.Window) : t])
+		}
+	}
+	this.sigma2 = variance(series, this.fitted, 0)
+	return nil
+}
+
+// Fitted returns the one-step-ahead predictions
+func (this *MovingAverage) Fitted() []float64 {
+	return this.fitted
+}
+
+// Forecast returns the mean of the last values for every step. The
+// prediction intervals assume that the errors are independent, so they do
+// not widen with each step
+func (this *MovingAverage) Forecast(h uint, level float64) (*Forecast, error) {
+	if this.series == nil {
+		return nil, ErrNotFitted
+	}
+	value := mean(this.series[len(this.series)-int(this.Window):])
+	forecast := make([]float64, h)
+	for i := range forecast {
+		forecast[i] = value
+	}
+	return newForecast(forecast, this.sigma2, []float64{1}, level)
+}
+
+// Stringify
+func (this *MovingAverage) String() string {
+	return fmt.Sprintf("moving_average{ window=%v sigma2=%v }", this.Window, this.sigma2)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// SIMPLE EXPONENTIAL SMOOTHING
+
+// Fit estimates alpha if it is not set, and computes the level. The
+// estimate is not stored in Alpha, so it is estimated again for each series
+func (this *SimpleExponentialSmoothing) Fit(series []float64) error {
+	series, err := copySeries(series, 2)
+	if err != nil {
+		return err
+	}
+	if this.Alpha < 0 || this.Alpha > 1 {
+		return fmt.Errorf("%v: Alpha should be between zero and one", ErrBadParameter)
+	}
+	alpha := this.Alpha
+	if alpha == 0 {
+		x := minimize(func(x []float64) float64 {
+			_, fitted := this.smooth(series, logistic(x[0]))
+			sse, _ := sumOfSquares(series, fitted)
+			return sse
+		}, []float64{logit(0.5)})
+		alpha = logistic(x[0])
+	}
+	this.alpha = alpha
+	this.level, this.fitted = this.smooth(series, alpha)
+	this.sigma2 = variance(series, this.fitted, 1)
+	return nil
+}
+
+// Fitted returns the one-step-ahead predictions
+func (this *SimpleExponentialSmoothing) Fitted() []float64 {
+	return this.fitted
+}
+
+// Forecast returns the level for every step
+func (this *SimpleExponentialSmoothing) Forecast(h uint, level float64) (*Forecast, error) {
+	if this.fitted == nil {
+		return nil, ErrNotFitted
+	}
+	forecast := make([]float64, h)
+	psi := make([]float64, h)
+	for i := range forecast {
+		forecast[i] = this.level
+		psi[i] = this.alpha
+	}
+	if h > 0 {
+		psi[0] = 1
+	}
+	return newForecast(forecast, this.sigma2, psi, level)
+}
+
+// smooth returns the final level and the one-step predictions
+func (this *SimpleExponentialSmoothing) smooth(series []float64, alpha float64) (float64, []float64) {
+	fitted := make([]float64, len(series))
+	fitted[0] = math.NaN()
+	level := series[0]
+	for t := 1; t < len(series); t++ {
+		fitted[t] = level
+		level = alpha*series[t] + (1-alpha)*level
+	}
+	return level, fitted
+}
+
+// Stringify
+func (this *SimpleExponentialSmoothing) String() string {
+	return fmt.Sprintf("simple_exponential_smoothing{ alpha=%.4f sigma2=%v }", this.alpha, this.sigma2)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// HOLT
+
+// Fit estimates alpha and beta if they are not set, and computes the
+// level and trend. The estimates are not stored in Alpha and Beta, so they
+// are estimated again for each series
+func (this *Holt) Fit(series []float64) error {
+	series, err := copySeries(series, 3)
+	if err != nil {
+		return err
+	}
+	if this.Alpha < 0 || this.Alpha > 1 || this.Beta < 0 || this.Beta > 1 {
+		return fmt.Errorf("%v: Alpha and beta should be between zero and one", ErrBadParameter)
+	}
+	x := []float64{logit(0.5), logit(0.1)}
+	fixed := []bool{this.Alpha != 0, this.Beta != 0}
+	if fixed[0] {
+		x[0] = logit(this.Alpha)
+	}
+	if fixed[1] {
+		x[1] = logit(this.Beta)
+	}
+	if fixed[0] == false || fixed[1] == false {
+		y := minimize(func(y []float64) float64 {
+			_, _, fitted := this.smooth(series, logistic(choose(fixed[0], x[0], y[0])), logistic(choose(fixed[1], x[1], y[1])))
+			sse, _ := sumOfSquares(series, fitted)
+			return sse
+		}, x)
+		for i := range x {
+			x[i] = choose(fixed[i], x[i], y[i])
+		}
+	}
+	this.alpha, this.beta = logistic(x[0]), logistic(x[1])
+	this.level, this.trend, this.fitted = this.smooth(series, this.alpha, this.beta)
+	this.sigma2 = variance(series, this.fitted, 2)
+	return nil
+}
+
+// Fitted returns the one-step-ahead predictions
+func (this *Holt) Fitted() []float64 {
+	return this.fitted
+}
+
+// Forecast returns the level plus the trend multiplied by the number of
+// steps ahead
+func (this *Holt) Forecast(h uint, level float64) (*Forecast, error) {
+	if this.fitted == nil {
+		return nil, ErrNotFitted
+	}
+	forecast := make([]float64, h)
+	psi := make([]float64, h)
+	for i := range forecast {
+		forecast[i] = this.level + float64(i+1)*this.trend
+		psi[i] = this.alpha * (1 + float64(i)*this.beta)
+	}
+	if h > 0 {
+		psi[0] = 1
+	}
+	return newForecast(forecast, this.sigma2, psi, level)
+}
+
+// smooth returns the final level and trend, and the one-step predictions.
+// The level is initialised with the first value and the trend with the
+// difference between the first two values
+func (this *Holt) smooth(series []float64, alpha, beta float64) (float64, float64, []float64) {
+	fitted := make([]float64, len(series))
+	fitted[0], fitted[1] = math.NaN(), math.NaN()
+	level, trend := series[1], series[1]-series[0]
+	for t := 2; t < len(series); t++ {
+		fitted[t] = level + trend
+		previous := level
+		level = alpha*series[t] + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+	}
+	return level, trend, fitted
+}
+
+// Stringify
+func (this *Holt) String() string {
+	return fmt.Sprintf("holt{ alpha=%.4f beta=%.4f sigma2=%v }", this.alpha, this.beta, this.sigma2)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// HOLT-WINTERS
+
+// Fit estimates alpha, beta and gamma if they are not set, and computes
+// the level, trend and seasonal components. The estimates are not stored
+// in Alpha, Beta and Gamma, so they are estimated again for each series
+func (this *HoltWinters) Fit(series []float64) error {
+	if this.Period < 2 {
+		return fmt.Errorf("%v: Period should be at least two", ErrBadParameter)
+	}
+	series, err := copySeries(series, 2*int(this.Period)+1)
+	if err != nil {
+		return err
+	}
+	if this.Multiplicative {
+		for _, value := range series {
+			if value <= 0 {
+				return fmt.Errorf("%v: Multiplicative seasonality requires positive values", ErrBadParameter)
+			}
+		}
+	}
+	params := []float64{this.Alpha, this.Beta, this.Gamma}
+	x := []float64{logit(0.5), logit(0.1), logit(0.1)}
+	fixed := make([]bool, len(params))
+	estimate := false
+	for i, param := range params {
+		if param < 0 || param > 1 {
+			return fmt.Errorf("%v: Alpha, beta and gamma should be between zero and one", ErrBadParameter)
+		} else if param != 0 {
+			x[i], fixed[i] = logit(param), true
+		} else {
+			estimate = true
+		}
+	}
+	if estimate {
+		y := minimize(func(y []float64) float64 {
+			_, _, _, fitted := this.smooth(series, logistic(choose(fixed[0], x[0], y[0])), logistic(choose(fixed[1], x[1], y[1])), logistic(choose(fixed[2], x[2], y[2])))
+			sse, _ := sumOfSquares(series, fitted)
+			return sse
+		}, x)
+		for i := range x {
+			x[i] = choose(fixed[i], x[i], y[i])
+		}
+	}
+	this.alpha, this.beta, this.gamma = logistic(x[0]), logistic(x[1]), logistic(x[2])
+	this.level, this.trend, this.season, this.fitted = this.smooth(series, this.alpha, this.beta, this.gamma)
+	this.sigma2 = variance(series, this.fitted, 3)
+	return nil
+}
+
+// Fitted returns the one-step-ahead predictions
+func (this *HoltWinters) Fitted() []float64 {
+	return this.fitted
+}
+
+// Forecast returns the level plus the trend multiplied by the number of
+// steps ahead, combined with the seasonal component for the step. For
+// multiplicative seasonality, the prediction intervals use the additive
+// approximation
+func (this *HoltWinters) Forecast(h uint, level float64) (*Forecast, error) {
+	if this.fitted == nil {
+		return nil, ErrNotFitted
+	}
+	m := int(this.Period)
+	forecast := make([]float64, h)
+	psi := make([]float64, h)
+	for i := range forecast {
+		trend := this.level + float64(i+1)*this.trend
+		if this.Multiplicative {
+			forecast[i] = trend * this.season[i%m]
+		} else {
+			forecast[i] = trend + this.season[i%m]
+		}
+		psi[i] = this.alpha * (1 + float64(i)*this.beta)
+		if i%m == 0 && i > 0 {
+			psi[i] += this.gamma * (1 - this.alpha)
+		}
+	}
+	if h > 0 {
+		psi[0] = 1
+	}
+	return newForecast(forecast, this.sigma2, psi, level)
+}
+
+// smooth returns the final level and trend, the seasonal components for
+// the next period and the one-step predictions. The level and trend are
+// initialised from the means of the first two seasons, and the seasonal
+// components from the detrended first season
+func (this *HoltWinters) smooth(series []float64, alpha, beta, gamma float64) (float64, float64, []float64, []float64) {
+	m := int(this.Period)
+	fitted := make([]float64, len(series))
+	first, second := mean(series[:m]), mean(series[m:2*m])
+	level, trend := first, (second-first)/float64(m)
+	season := make([]float64, m)
+	for i := 0; i < m; i++ {
+		fitted[i] = math.NaN()
+		// Remove the trend within the first season
+		trended := first + trend*(float64(i)-float64(m-1)/2)
+		if this.Multiplicative {
+			season[i] = series[i] / trended
+		} else {
+			season[i] = series[i] - trended
+		}
+	}
+	// Move the level to the end of the first season
+	level += trend * float64(m-1) / 2
+	for t := m; t < len(series); t++ {
+		s := season[t%m]
+		previous := level
+		if this.Multiplicative {
+			fitted[t] = (level + trend) * s
+			level = alpha*series[t]/s + (1-alpha)*(level+trend)
+			season[t%m] = gamma*series[t]/level + (1-gamma)*s
+		} else {
+			fitted[t] = level + trend + s
+			level = alpha*(series[t]-s) + (1-alpha)*(level+trend)
+			season[t%m] = gamma*(series[t]-level) + (1-gamma)*s
+		}
+		trend = beta*(level-previous) + (1-beta)*trend
+	}
+
+	// Rotate the seasonal components to start with the next value
+	next := make([]float64, m)
+	for i := range next {
+		next[i] = season[(len(series)+i)%m]
+	}
+	return level, trend, next, fitted
+}
+
+// Stringify
+func (this *HoltWinters) String() string {
+	return fmt.Sprintf("holt_winters{ alpha=%.4f beta=%.4f gamma=%.4f period=%v multiplicative=%v sigma2=%v }", this.alpha, this.beta, this.gamma, this.Period, this.Multiplicative, this.sigma2)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// mean returns the mean of values
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// choose returns a if fixed is true, else b
+func choose(fixed bool, a, b float64) float64 {
+	if fixed {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,228 @@
+/*
+	Package gbfs is a client for bike share systems which publish data in the
+	General Bikeshare Feed Specification (GBFS) format, such as Citibike.
+	The feeds are discovered from the gbfs.json file, and responses are cached
+	for the time-to-live returned with each feed.
+*/
+package gbfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRUCTURES
+
+// Config is the configuration for a client
+type Config struct {
+	// URL is the location of the gbfs.json discovery file
+	URL string
+
+	// Language is the language of the feeds to use, or empty to use
+	// DEFAULT_LANGUAGE
+	Language string
+
+	// Client is the HTTP client used to fetch the feeds, or nil to
+	// use http.DefaultClient
+	Client *http.Client
+}
+
+// Client fetches and caches feeds
+type Client struct {
+	url      string
+	language string
+	client   *http.Client
+	now      func() time.Time
+
+	lock  sync.Mutex
+	feeds map[string]string
+	cache map[string]*cached
+}
+
+// cached is a response which is valid until it expires
+type cached struct {
+	expires  time.Time
+	response interface{}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// The URL of the Citibike discovery file
+	CITIBIKE_URL = "https://gbfs.citibikenyc.com/gbfs/gbfs.json"
+
+	// The default language of feeds
+	DEFAULT_LANGUAGE = "en"
+)
+
+const (
+	// Feed names
+	FEED_SYSTEM_INFORMATION  = "system_information"
+	FEED_STATION_INFORMATION = "station_information"
+	FEED_STATION_STATUS      = "station_status"
+	FEED_FREE_BIKE_STATUS    = "free_bike_status"
+)
+
+var (
+	ErrNotFound = fmt.Errorf("Feed not found")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewClient returns a client for the discovery file in the configuration
+func NewClient(config Config) *Client {
+	this := new(Client)
+	this.url = config.URL
+	if this.url == "" {
+		this.url = CITIBIKE_URL
+	}
+	this.language = config.Language
+	if this.language == "" {
+		this.language = DEFAULT_LANGUAGE
+	}
+	this.client = config.Client
+	if this.client == nil {
+		this.client = http.DefaultClient
+	}
+	this.now = time.Now
+	this.cache = make(map[string]*cached)
+	return this
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// FEEDS
+
+// Feeds returns the feed names and URLs for the configured language
+func (this *Client) Feeds() (map[string]string, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if err := this.discover(); err != nil {
+		return nil, err
+	}
+	feeds := make(map[string]string, len(this.feeds))
+	for name, url := range this.feeds {
+		feeds[name] = url
+	}
+	return feeds, nil
+}
+
+// SystemInformation returns information about the bike share system
+func (this *Client) SystemInformation() (*SystemInformation, error) {
+	response := new(SystemInformation)
+	if cached, err := this.fetch(FEED_SYSTEM_INFORMATION, response, &response.Response); err != nil {
+		return nil, err
+	} else {
+		return cached.(*SystemInformation), nil
+	}
+}
+
+// StationInformation returns the static information for all stations
+func (this *Client) StationInformation() (*StationInformation, error) {
+	response := new(StationInformation)
+	if cached, err := this.fetch(FEED_STATION_INFORMATION, response, &response.Response); err != nil {
+		return nil, err
+	} else {
+		return cached.(*StationInformation), nil
+	}
+}
+
+// StationStatus returns the current status of all stations
+func (this *Client) StationStatus() (*StationStatus, error) {
+	response := new(StationStatus)
+	if cached, err := this.fetch(FEED_STATION_STATUS, response, &response.Response); err != nil {
+		return nil, err
+	} else {
+		return cached.(*StationStatus), nil
+	}
+}
+
+// FreeBikeStatus returns the bikes which are not docked at a station
+func (this *Client) FreeBikeStatus() (*FreeBikeStatus, error) {
+	response := new(FreeBikeStatus)
+	if cached, err := this.fetch(FEED_FREE_BIKE_STATUS, response, &response.Response); err != nil {
+		return nil, err
+	} else {
+		return cached.(*FreeBikeStatus), nil
+	}
+}
+
+// Flush removes all cached responses, so that the next request for each
+// feed is fetched
+func (this *Client) Flush() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.cache = make(map[string]*cached)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// discover reads the discovery file if it has not been read already or
+// has expired. The lock should be held by the caller
+func (this *Client) discover() error {
+	if this.feeds != nil && this.valid("gbfs") {
+		return nil
+	}
+	var discovery Discovery
+	if err := this.get(this.url, &discovery); err != nil {
+		return err
+	}
+	language, exists := discovery.Data[this.language]
+	if exists == false {
+		return fmt.Errorf("%v: No feeds for language %v", ErrNotFound, this.language)
+	}
+	this.feeds = make(map[string]string, len(language.Feeds))
+	for _, feed := range language.Feeds {
+		this.feeds[feed.Name] = feed.URL
+	}
+	this.cache["gbfs"] = &cached{this.now().Add(discovery.TTL.Duration), &discovery}
+	return nil
+}
+
+// fetch returns a cached response for a feed if it has not expired, or
+// else fetches the feed into response and caches it
+func (this *Client) fetch(name string, response interface{}, header *Response) (interface{}, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.valid(name) {
+		return this.cache[name].response, nil
+	}
+	if err := this.discover(); err != nil {
+		return nil, err
+	}
+	url, exists := this.feeds[name]
+	if exists == false {
+		return nil, fmt.Errorf("%v: %v", ErrNotFound, name)
+	}
+	if err := this.get(url, response); err != nil {
+		return nil, err
+	}
+	this.cache[name] = &cached{this.now().Add(header.TTL.Duration), response}
+	return response, nil
+}
+
+// valid returns true if a cached response exists which has not expired
+func (this *Client) valid(name string) bool {
+	if cached, exists := this.cache[name]; exists == false {
+		return false
+	} else {
+		return this.now().Before(cached.expires)
+	}
+}
+
+// get fetches a URL and decodes the JSON response
+func (this *Client) get(url string, response interface{}) error {
+	if resp, err := this.client.Get(url); err != nil {
+		return err
+	} else {
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%v: %v", url, resp.Status)
+		}
+		return json.NewDecoder(resp.Body).Decode(response)
+	}
+}
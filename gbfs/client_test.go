@@ -0,0 +1,175 @@
+package gbfs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// server serves the fixture files in testdata, replacing {{URL}} with the
+// URL of the server, and counts the requests for each file
+type server struct {
+	*httptest.Server
+	lock     sync.Mutex
+	requests map[string]int
+}
+
+func newServer() *server {
+	this := &server{requests: make(map[string]int)}
+	this.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		this.lock.Lock()
+		this.requests[r.URL.Path]++
+		this.lock.Unlock()
+		data, err := ioutil.ReadFile(path.Join("testdata", path.Base(r.URL.Path)))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(strings.Replace(string(data), "{{URL}}", this.URL, -1)))
+	}))
+	return this
+}
+
+func (this *server) count(path string) int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.requests[path]
+}
+
+// newTestClient returns a client for the server with a clock which can be
+// moved forward
+func newTestClient(s *server, language string) (*Client, *time.Time) {
+	now := time.Unix(1545210000, 0)
+	client := NewClient(Config{URL: s.URL + "/gbfs.json", Language: language, Client: s.Client()})
+	client.now = func() time.Time { return now }
+	return client, &now
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func Test_Client_001(t *testing.T) {
+	// Feeds are discovered for the configured language
+	s := newServer()
+	defer s.Close()
+	client, _ := newTestClient(s, "")
+	if feeds, err := client.Feeds(); err != nil {
+		t.Fatal(err)
+	} else if len(feeds) != 4 {
+		t.Errorf("Expected 4 feeds, got %v", feeds)
+	} else if feeds[FEED_STATION_STATUS] != s.URL+"/en/station_status.json" {
+		t.Errorf("Unexpected station_status URL: %v", feeds[FEED_STATION_STATUS])
+	}
+
+	client, _ = newTestClient(s, "es")
+	if feeds, err := client.Feeds(); err != nil {
+		t.Fatal(err)
+	} else if len(feeds) != 1 {
+		t.Errorf("Expected 1 feed, got %v", feeds)
+	}
+
+	client, _ = newTestClient(s, "fr")
+	if _, err := client.Feeds(); err == nil {
+		t.Error("Expected an error for a missing language")
+	}
+}
+
+func Test_Client_002(t *testing.T) {
+	// Each of the four feeds is decoded
+	s := newServer()
+	defer s.Close()
+	client, _ := newTestClient(s, "")
+
+	if system, err := client.SystemInformation(); err != nil {
+		t.Fatal(err)
+	} else if system.Data.SystemId != "NYC" || system.Data.Timezone != "America/New_York" {
+		t.Errorf("Unexpected system information: %+v", system.Data)
+	} else if system.TTL.Duration != 10*time.Second {
+		t.Errorf("Unexpected ttl: %v", system.TTL)
+	}
+
+	if info, err := client.StationInformation(); err != nil {
+		t.Fatal(err)
+	} else if len(info.Data.Stations) != 2 {
+		t.Errorf("Expected 2 stations, got %v", len(info.Data.Stations))
+	} else if info.Data.Stations[0].RegionId != "71" || info.Data.Stations[1].RegionId != "71" {
+		t.Errorf("Unexpected region identifiers: %v %v", info.Data.Stations[0].RegionId, info.Data.Stations[1].RegionId)
+	} else if info.Data.Stations[0].Capacity != 55 {
+		t.Errorf("Unexpected capacity: %v", info.Data.Stations[0].Capacity)
+	}
+
+	if status, err := client.StationStatus(); err != nil {
+		t.Fatal(err)
+	} else if len(status.Data.Stations) != 2 {
+		t.Errorf("Expected 2 stations, got %v", len(status.Data.Stations))
+	} else if station := status.Data.Stations[0]; station.BikesAvailable != 12 || station.DocksAvailable != 42 {
+		t.Errorf("Unexpected station status: %v", station)
+	} else if station.LastReported.Unix() != 1545209940 {
+		t.Errorf("Unexpected last reported time: %v", station.LastReported)
+	}
+
+	if bikes, err := client.FreeBikeStatus(); err != nil {
+		t.Fatal(err)
+	} else if len(bikes.Data.Bikes) != 2 {
+		t.Errorf("Expected 2 bikes, got %v", len(bikes.Data.Bikes))
+	} else if bikes.Data.Bikes[1].IsReserved != 1 {
+		t.Errorf("Unexpected bike: %v", bikes.Data.Bikes[1])
+	}
+}
+
+func Test_Client_003(t *testing.T) {
+	// Responses are cached until the ttl expires
+	s := newServer()
+	defer s.Close()
+	client, now := newTestClient(s, "")
+	feed := "/en/station_status.json"
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.StationStatus(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := s.count(feed); n != 1 {
+		t.Errorf("Expected 1 request before the ttl expires, got %v", n)
+	}
+
+	// The feed expires after ten seconds
+	*now = now.Add(9 * time.Second)
+	if _, err := client.StationStatus(); err != nil {
+		t.Fatal(err)
+	} else if n := s.count(feed); n != 1 {
+		t.Errorf("Expected 1 request before the ttl expires, got %v", n)
+	}
+	*now = now.Add(time.Second)
+	if _, err := client.StationStatus(); err != nil {
+		t.Fatal(err)
+	} else if n := s.count(feed); n != 2 {
+		t.Errorf("Expected 2 requests after the ttl expires, got %v", n)
+	}
+
+	// The discovery file expires after sixty seconds
+	if n := s.count("/gbfs.json"); n != 1 {
+		t.Errorf("Expected 1 discovery request, got %v", n)
+	}
+	*now = now.Add(60 * time.Second)
+	if _, err := client.StationStatus(); err != nil {
+		t.Fatal(err)
+	} else if n := s.count("/gbfs.json"); n != 2 {
+		t.Errorf("Expected 2 discovery requests after the ttl expires, got %v", n)
+	}
+
+	// Flush removes the cached responses
+	client.Flush()
+	if _, err := client.StationStatus(); err != nil {
+		t.Fatal(err)
+	} else if n := s.count(feed); n != 4 {
+		t.Errorf("Expected 4 requests after flush, got %v", n)
+	}
+}
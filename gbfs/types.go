@@ -0,0 +1,176 @@
+package gbfs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRUCTURES
+
+// Time interprets a JSON unix timestamp as a time.Time
+type Time struct {
+	time.Time
+}
+
+// Duration interprets a JSON number of seconds as a time.Duration
+type Duration struct {
+	time.Duration
+}
+
+// Id interprets a JSON identifier which may be a string or a number
+type Id string
+
+// Response contains the fields common to every feed
+type Response struct {
+	LastUpdated Time     `json:"last_updated"`
+	TTL         Duration `json:"ttl"`
+}
+
+// Feed is a single feed listed in the discovery file
+type Feed struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Discovery is the gbfs.json file which lists the feeds for each language
+type Discovery struct {
+	Response
+	Data map[string]struct {
+		Feeds []*Feed `json:"feeds"`
+	} `json:"data"`
+}
+
+// SystemInformation describes the bike share system
+type SystemInformation struct {
+	Response
+	Data struct {
+		SystemId    string `json:"system_id"`
+		Language    string `json:"language"`
+		Name        string `json:"name"`
+		ShortName   string `json:"short_name"`
+		Operator    string `json:"operator"`
+		URL         string `json:"url"`
+		PhoneNumber string `json:"phone_number"`
+		Email       string `json:"email"`
+		Timezone    string `json:"timezone"`
+	} `json:"data"`
+}
+
+// StationInformation contains the static information for all stations
+type StationInformation struct {
+	Response
+	Data struct {
+		Stations []*StationInfo `json:"stations"`
+	} `json:"data"`
+}
+
+// StationInfo is the static information for a single station
+type StationInfo struct {
+	StationId string  `json:"station_id"`
+	Name      string  `json:"name"`
+	ShortName string  `json:"short_name"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	RegionId  Id      `json:"region_id"`
+	Capacity  uint    `json:"capacity"`
+}
+
+// StationStatus contains the current status of all stations
+type StationStatus struct {
+	Response
+	Data struct {
+		Stations []*Station `json:"stations"`
+	} `json:"data"`
+}
+
+// Station is the current status of a single station
+type Station struct {
+	StationId      string `json:"station_id"`
+	IsInstalled    uint   `json:"is_installed"`
+	IsRenting      uint   `json:"is_renting"`
+	IsReturning    uint   `json:"is_returning"`
+	DocksAvailable uint   `json:"num_docks_available"`
+	DocksDisabled  uint   `json:"num_docks_disabled"`
+	BikesAvailable uint   `json:"num_bikes_available"`
+	BikesDisabled  uint   `json:"num_bikes_disabled"`
+	LastReported   Time   `json:"last_reported"`
+}
+
+// FreeBikeStatus contains the bikes which are not docked at a station
+type FreeBikeStatus struct {
+	Response
+	Data struct {
+		Bikes []*Bike `json:"bikes"`
+	} `json:"data"`
+}
+
+// Bike is a single bike which is not docked at a station
+type Bike struct {
+	BikeId     string  `json:"bike_id"`
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	IsReserved uint    `json:"is_reserved"`
+	IsDisabled uint    `json:"is_disabled"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PARSERS
+
+// Unmarshall a unixtime into a time.Time structure
+func (t *Time) UnmarshalJSON(j []byte) error {
+	if unixtime, err := strconv.ParseInt(strings.Trim(string(j), "\""), 10, 64); err != nil {
+		return err
+	} else {
+		t.Time = time.Unix(unixtime, 0)
+		return nil
+	}
+}
+
+// Marshall a time.Time structure into a unixtime
+func (t Time) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+}
+
+// Unmarshall a pure number into a time.Duration structure, where the
+// number represents a second
+func (d *Duration) UnmarshalJSON(j []byte) error {
+	if seconds, err := strconv.ParseInt(strings.Trim(string(j), "\""), 10, 64); err != nil {
+		return err
+	} else {
+		d.Duration = time.Second * time.Duration(seconds)
+		return nil
+	}
+}
+
+// Marshall a time.Duration structure into a number of seconds
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(d.Duration/time.Second), 10)), nil
+}
+
+// Unmarshall a string or number into an identifier
+func (i *Id) UnmarshalJSON(j []byte) error {
+	if value, err := strconv.Unquote(string(j)); err == nil {
+		*i = Id(value)
+	} else {
+		*i = Id(strings.TrimSpace(string(j)))
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (s *StationInfo) String() string {
+	return fmt.Sprintf("station_info{ id=%v name=%v lat=%v lon=%v capacity=%v }", s.StationId, strconv.Quote(s.Name), s.Lat, s.Lon, s.Capacity)
+}
+
+func (s *Station) String() string {
+	return fmt.Sprintf("station{ id=%v last_reported=%v is_installed=%v is_renting=%v is_returning=%v bikes_available=%v docks_available=%v bikes_disabled=%v docks_disabled=%v }", s.StationId, s.LastReported, s.IsInstalled, s.IsRenting, s.IsReturning, s.BikesAvailable, s.DocksAvailable, s.BikesDisabled, s.DocksDisabled)
+}
+
+func (b *Bike) String() string {
+	return fmt.Sprintf("bike{ id=%v lat=%v lon=%v is_reserved=%v is_disabled=%v }", b.BikeId, b.Lat, b.Lon, b.IsReserved, b.IsDisabled)
+}
@@ -1,19 +1,28 @@
 module github.com/djthorpe/MachineLearning
 
+go 1.27.1
+
+require (
+	github.com/gonum/floats v0.0.0-20180125090339-7de1f4ea7ab5
+	github.com/mattn/go-sqlite3 v1.10.0
+	github.com/olekukonko/tablewriter v0.0.1
+	gonum.org/v1/gonum v0.0.0-20181208210948-435185761cc9
+	gonum.org/v1/plot v0.0.0-20181208091836-db5ea5fa8928
+)
+
 require (
 	github.com/ajstarks/svgo v0.0.0-20181006003313-6ce6a3bcf6cd // indirect
 	github.com/go-gl/gl v0.0.0-20181026044259-55b76b7df9d2 // indirect
 	github.com/go-gl/glfw v0.0.0-20181014061658-691ee1b84c51 // indirect
-	github.com/gonum/floats v0.0.0-20180125090339-7de1f4ea7ab5
+	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/gonum/internal v0.0.0-20181124074243-f884aa714029 // indirect
+	github.com/jung-kurt/gofpdf v1.0.0 // indirect
 	github.com/llgcode/draw2d v0.0.0-20180825133448-f52c8a71aff0 // indirect
+	github.com/llgcode/ps v0.0.0-20150911083025-f1443b32eedb // indirect
 	github.com/mattn/go-runewidth v0.0.3 // indirect
-	github.com/mattn/go-sqlite3 v1.10.0
-	github.com/olekukonko/tablewriter v0.0.1
 	golang.org/x/exp v0.0.0-20181206211736-68cc7b1f272e // indirect
 	golang.org/x/image v0.0.0-20181116024801-cd38e8056d9b // indirect
 	golang.org/x/tools v0.0.0-20181207222222-4c874b978acb // indirect
-	gonum.org/v1/gonum v0.0.0-20181208210948-435185761cc9
 	gonum.org/v1/netlib v0.0.0-20181029234149-ec6d1f5cefe6 // indirect
-	gonum.org/v1/plot v0.0.0-20181208091836-db5ea5fa8928
+	rsc.io/pdf v0.1.1 // indirect
 )
@@ -0,0 +1,104 @@
+package knn
+
+import (
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Metric determines how the distance between two samples is measured
+type Metric int
+
+const (
+	// Straight line distance
+	METRIC_EUCLIDEAN Metric = iota
+	// Sum of absolute differences
+	METRIC_MANHATTAN
+	// Generalisation of euclidean and manhattan distance with power P
+	METRIC_MINKOWSKI
+	// One minus the cosine of the angle between samples
+	METRIC_COSINE
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Euclidean returns the straight line distance between a and b
+func Euclidean(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(sum)
+}
+
+// Manhattan returns the sum of absolute differences between a and b
+func Manhattan(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += math.Abs(a[i] - b[i])
+	}
+	return sum
+}
+
+// Minkowski returns the p-th root of the sum of absolute differences
+// between a and b raised to the power p. When p is 1 this is the manhattan
+// distance, and when p is 2 this is the euclidean distance
+func Minkowski(a, b []float64, p float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += math.Pow(math.Abs(a[i]-b[i]), p)
+	}
+	return math.Pow(sum, 1/p)
+}
+
+// Cosine returns one minus the cosine of the angle between a and b, which
+// is zero when they point in the same direction. The distance is one when
+// either sample is zero
+func Cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 1
+	}
+	return 1 - dot/math.Sqrt(na*nb)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// distance returns the distance function for the metric
+func (this Metric) distance(p float64) func(a, b []float64) float64 {
+	switch this {
+	case METRIC_EUCLIDEAN:
+		return Euclidean
+	case METRIC_MANHATTAN:
+		return Manhattan
+	case METRIC_MINKOWSKI:
+		return func(a, b []float64) float64 {
+			return Minkowski(a, b, p)
+		}
+	case METRIC_COSINE:
+		return Cosine
+	default:
+		return nil
+	}
+}
+
+// Stringify
+func (this Metric) String() string {
+	switch this {
+	case METRIC_EUCLIDEAN:
+		return "METRIC_EUCLIDEAN"
+	case METRIC_MANHATTAN:
+		return "METRIC_MANHATTAN"
+	case METRIC_MINKOWSKI:
+		return "METRIC_MINKOWSKI"
+	case METRIC_COSINE:
+		return "METRIC_COSINE"
+	default:
+		return "[?? Invalid Metric value]"
+	}
+}
@@ -0,0 +1,293 @@
+package knn
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Index determines how the nearest neighbours are found
+type Index int
+
+const (
+	// Compare the query with every sample
+	INDEX_BRUTE Index = iota
+	// Partition the samples along one feature at a time
+	INDEX_KDTREE
+	// Partition the samples into nested hyperspheres
+	INDEX_BALLTREE
+)
+
+// Neighbour is a sample and its distance from a query
+type Neighbour struct {
+	// Index is the row of the sample in the fitted matrix
+	Index    int
+	Distance float64
+}
+
+// searcher finds the k nearest samples to a query
+type searcher interface {
+	search(query []float64, k int) []Neighbour
+}
+
+// brute compares the query with every sample
+type brute struct {
+	points   [][]float64
+	distance func(a, b []float64) float64
+}
+
+// node is a node in a KD-tree or ball tree. Leaf nodes have the indexes
+// of their samples
+type node struct {
+	// Split feature and value for a KD-tree
+	axis  int
+	split float64
+
+	// Centre and radius for a ball tree
+	centre []float64
+	radius float64
+
+	left, right *node
+	indexes     []int
+}
+
+// kdtree partitions the samples at the median of the feature with the
+// largest spread. It requires a distance where the difference in any one
+// feature is no more than the distance
+type kdtree struct {
+	brute
+	root *node
+}
+
+// balltree partitions the samples into hyperspheres. It requires a
+// distance which satisfies the triangle inequality
+type balltree struct {
+	brute
+	root *node
+}
+
+// neighbours is a max-heap of neighbours, so that the furthest neighbour
+// can be replaced. Neighbours at the same distance are ordered by index
+type neighbours []Neighbour
+
+///////////////////////////////////////////////////////////////////////////////
+// BRUTE FORCE
+
+func (this *brute) search(query []float64, k int) []Neighbour {
+	result := make(neighbours, 0, k+1)
+	for i := range this.points {
+		result.add(Neighbour{i, this.distance(query, this.points[i])}, k)
+	}
+	return result.sorted()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// KD-TREE
+
+func newKDTree(points [][]float64, distance func(a, b []float64) float64, leaf int) *kdtree {
+	this := &kdtree{brute: brute{points, distance}}
+	this.root = this.build(indexes(len(points)), leaf)
+	return this
+}
+
+func (this *kdtree) build(idx []int, leaf int) *node {
+	if len(idx) <= leaf {
+		return &node{indexes: idx}
+	}
+	axis := widest(this.points, idx)
+	sort.Slice(idx, func(i, j int) bool {
+		return this.points[idx[i]][axis] < this.points[idx[j]][axis]
+	})
+	// Read the split before building the children, which sort idx again
+	median := len(idx) / 2
+	split := this.points[idx[median]][axis]
+	return &node{
+		axis:  axis,
+		split: split,
+		left:  this.build(idx[:median], leaf),
+		right: this.build(idx[median:], leaf),
+	}
+}
+
+func (this *kdtree) search(query []float64, k int) []Neighbour {
+	result := make(neighbours, 0, k+1)
+	this.visit(this.root, query, k, &result)
+	return result.sorted()
+}
+
+func (this *kdtree) visit(n *node, query []float64, k int, result *neighbours) {
+	if n.left == nil {
+		for _, i := range n.indexes {
+			result.add(Neighbour{i, this.distance(query, this.points[i])}, k)
+		}
+		return
+	}
+	// Visit the side containing the query first, and the other side only
+	// if it could contain a nearer neighbour
+	near, far := n.left, n.right
+	if query[n.axis] >= n.split {
+		near, far = far, near
+	}
+	this.visit(near, query, k, result)
+	if len(*result) < k || math.Abs(query[n.axis]-n.split) <= result.furthest() {
+		this.visit(far, query, k, result)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// BALL TREE
+
+func newBallTree(points [][]float64, distance func(a, b []float64) float64, leaf int) *balltree {
+	this := &balltree{brute: brute{points, distance}}
+	this.root = this.build(indexes(len(points)), leaf)
+	return this
+}
+
+func (this *balltree) build(idx []int, leaf int) *node {
+	// Compute the centre and radius
+	n := &node{centre: make([]float64, len(this.points[idx[0]]))}
+	for _, i := range idx {
+		for j, value := range this.points[i] {
+			n.centre[j] += value / float64(len(idx))
+		}
+	}
+	for _, i := range idx {
+		n.radius = math.Max(n.radius, this.distance(n.centre, this.points[i]))
+	}
+	if len(idx) <= leaf {
+		n.indexes = idx
+		return n
+	}
+
+	// Split at the median of the feature with the largest spread
+	axis := widest(this.points, idx)
+	sort.Slice(idx, func(i, j int) bool {
+		return this.points[idx[i]][axis] < this.points[idx[j]][axis]
+	})
+	median := len(idx) / 2
+	n.left = this.build(idx[:median], leaf)
+	n.right = this.build(idx[median:], leaf)
+	return n
+}
+
+func (this *balltree) search(query []float64, k int) []Neighbour {
+	result := make(neighbours, 0, k+1)
+	this.visit(this.root, query, k, &result)
+	return result.sorted()
+}
+
+func (this *balltree) visit(n *node, query []float64, k int, result *neighbours) {
+	// Skip the ball if every sample within it is further than the
+	// furthest neighbour
+	if len(*result) >= k && this.distance(query, n.centre)-n.radius > result.furthest() {
+		return
+	}
+	if n.left == nil {
+		for _, i := range n.indexes {
+			result.add(Neighbour{i, this.distance(query, this.points[i])}, k)
+		}
+		return
+	}
+	// Visit the nearest child first
+	near, far := n.left, n.right
+	if this.distance(query, far.centre) < this.distance(query, near.centre) {
+		near, far = far, near
+	}
+	this.visit(near, query, k, result)
+	this.visit(far, query, k, result)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// NEIGHBOURS
+
+func (this neighbours) Len() int            { return len(this) }
+func (this neighbours) Less(i, j int) bool  { return this[j].before(this[i]) }
+func (this neighbours) Swap(i, j int)       { this[i], this[j] = this[j], this[i] }
+func (this *neighbours) Push(x interface{}) { *this = append(*this, x.(Neighbour)) }
+func (this *neighbours) Pop() interface{} {
+	old := *this
+	x := old[len(old)-1]
+	*this = old[:len(old)-1]
+	return x
+}
+
+// add adds a neighbour, keeping at most k neighbours. A neighbour at the
+// same distance as the furthest neighbour replaces it when its index is
+// smaller, so that ties are broken the same way for every index
+func (this *neighbours) add(n Neighbour, k int) {
+	if len(*this) < k {
+		heap.Push(this, n)
+	} else if n.before((*this)[0]) {
+		(*this)[0] = n
+		heap.Fix(this, 0)
+	}
+}
+
+// furthest returns the distance of the furthest neighbour
+func (this neighbours) furthest() float64 {
+	if len(this) == 0 {
+		return math.Inf(1)
+	}
+	return this[0].Distance
+}
+
+// sorted returns the neighbours in order of increasing distance, and
+// then by index so that results are the same for every index
+func (this neighbours) sorted() []Neighbour {
+	result := []Neighbour(this)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].before(result[j])
+	})
+	return result
+}
+
+// before returns true if a neighbour is nearer than another, or at the
+// same distance with a smaller index
+func (this Neighbour) before(other Neighbour) bool {
+	if this.Distance == other.Distance {
+		return this.Index < other.Index
+	}
+	return this.Distance < other.Distance
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// indexes returns the indexes from zero to n-1
+func indexes(n int) []int {
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	return idx
+}
+
+// widest returns the feature with the largest spread of values
+func widest(points [][]float64, idx []int) int {
+	axis, spread := 0, -1.0
+	for j := range points[idx[0]] {
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, i := range idx {
+			min, max = math.Min(min, points[i][j]), math.Max(max, points[i][j])
+		}
+		if max-min > spread {
+			axis, spread = j, max-min
+		}
+	}
+	return axis
+}
+
+// Stringify
+func (this Index) String() string {
+	switch this {
+	case INDEX_BRUTE:
+		return "INDEX_BRUTE"
+	case INDEX_KDTREE:
+		return "INDEX_KDTREE"
+	case INDEX_BALLTREE:
+		return "INDEX_BALLTREE"
+	default:
+		return "[?? Invalid Index value]"
+	}
+}
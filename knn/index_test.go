@@ -0,0 +1,85 @@
+package knn
+
+import (
+	"math/rand"
+	"testing"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+func randomPoints(r *rand.Rand, n, dims int) [][]float64 {
+	points := make([][]float64, n)
+	for i := range points {
+		points[i] = make([]float64, dims)
+		for j := range points[i] {
+			points[i][j] = r.Float64()
+		}
+	}
+	return points
+}
+
+func Test_Index_001(t *testing.T) {
+	// Brute force, KD-tree and ball tree return the same neighbours
+	r := rand.New(rand.NewSource(1))
+	points := randomPoints(r, 500, 3)
+	metrics := map[string]func(a, b []float64) float64{
+		"euclidean": Euclidean,
+		"manhattan": Manhattan,
+		"minkowski": func(a, b []float64) float64 { return Minkowski(a, b, 3) },
+	}
+	for name, distance := range metrics {
+		indexes := map[string]searcher{
+			"kdtree":   newKDTree(points, distance, 10),
+			"balltree": newBallTree(points, distance, 10),
+		}
+		expected := &brute{points, distance}
+		for _, query := range randomPoints(r, 200, 3) {
+			want := expected.search(query, 5)
+			for index, searcher := range indexes {
+				if got := searcher.search(query, 5); equalNeighbours(got, want) == false {
+					t.Errorf("%v %v: query %v: got %v, expected %v", name, index, query, got, want)
+				}
+			}
+		}
+	}
+}
+
+func Test_Index_002(t *testing.T) {
+	// Ties at the k'th distance are broken by index for every index, on
+	// an integer grid with duplicate points
+	r := rand.New(rand.NewSource(2))
+	points := make([][]float64, 500)
+	for i := range points {
+		points[i] = []float64{float64(r.Intn(10)), float64(r.Intn(10))}
+	}
+	for name, distance := range map[string]func(a, b []float64) float64{"euclidean": Euclidean, "manhattan": Manhattan} {
+		indexes := map[string]searcher{
+			"kdtree":   newKDTree(points, distance, 10),
+			"balltree": newBallTree(points, distance, 10),
+		}
+		expected := &brute{points, distance}
+		for q := 0; q < 200; q++ {
+			query := []float64{float64(r.Intn(10)), float64(r.Intn(10))}
+			want := expected.search(query, 5)
+			for index, searcher := range indexes {
+				if got := searcher.search(query, 5); equalNeighbours(got, want) == false {
+					t.Errorf("%v %v: query %v: got %v, expected %v", name, index, query, got, want)
+				}
+			}
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func equalNeighbours(a, b []Neighbour) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
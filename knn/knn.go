@@ -0,0 +1,349 @@
+/*
+	Package knn implements k-nearest neighbours classification and
+	regression, where a sample is predicted from the samples nearest to it
+	in the training data. Neighbours can be found by comparing every sample
+	or by using a KD-tree or ball tree index.
+*/
+package knn
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Weights determines how much each neighbour contributes to a prediction
+type Weights int
+
+// Config is the configuration for a classifier or regressor
+type Config struct {
+	// K is the number of neighbours
+	K uint
+
+	// Metric is the distance between samples, and P is the power for
+	// METRIC_MINKOWSKI
+	Metric Metric
+	P      float64
+
+	// Weights of the neighbours
+	Weights Weights
+
+	// Index used to find the neighbours, and the maximum number of
+	// samples in each leaf of a tree
+	Index    Index
+	LeafSize uint
+}
+
+// Classifier predicts the most common label of the nearest neighbours
+type Classifier struct {
+	model
+	labels  []string
+	classes []string
+}
+
+// Regressor predicts the mean value of the nearest neighbours
+type Regressor struct {
+	model
+	values []float64
+}
+
+// model finds the nearest neighbours of a query
+type model struct {
+	config   Config
+	distance func(a, b []float64) float64
+	index    searcher
+	features int
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// Every neighbour contributes equally
+	WEIGHTS_UNIFORM Weights = iota
+	// Neighbours contribute the inverse of their distance, and a
+	// neighbour at zero distance determines the prediction
+	WEIGHTS_DISTANCE
+)
+
+const (
+	DEFAULT_K         = 5
+	DEFAULT_LEAF_SIZE = 30
+)
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewClassifier returns a classifier with the configuration
+func NewClassifier(config Config) *Classifier {
+	return &Classifier{model: model{config: config}}
+}
+
+// NewRegressor returns a regressor with the configuration
+func NewRegressor(config Config) *Regressor {
+	return &Regressor{model: model{config: config}}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CLASSIFIER
+
+// Fit stores the samples, with one row for each sample, and their labels
+func (this *Classifier) Fit(x mat.Matrix, labels []string) error {
+	if err := this.fit(x, len(labels)); err != nil {
+		return err
+	}
+	this.labels = append([]string(nil), labels...)
+	this.classes = unique(labels)
+	return nil
+}
+
+// Classes returns the labels in sorted order, which are the columns
+// returned by PredictProba
+func (this *Classifier) Classes() []string {
+	return this.classes
+}
+
+// Predict returns the label with the largest weight amongst the nearest
+// neighbours for each row
+func (this *Classifier) Predict(x mat.Matrix) ([]string, error) {
+	proba, err := this.PredictProba(x)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := proba.Dims()
+	predicted := make([]string, rows)
+	for i := range predicted {
+		predicted[i] = this.classes[argmax(proba.RawRowView(i))]
+	}
+	return predicted, nil
+}
+
+// PredictProba returns the weight of each class amongst the nearest
+// neighbours for each row, where the weights for a row sum to one. The
+// columns are in the order returned by Classes
+func (this *Classifier) PredictProba(x mat.Matrix) (*mat.Dense, error) {
+	if this.labels == nil {
+		return nil, ErrNotFitted
+	}
+	class := make(map[string]int, len(this.classes))
+	for i, label := range this.classes {
+		class[label] = i
+	}
+	rows, err := this.query(x)
+	if err != nil {
+		return nil, err
+	}
+	proba := mat.NewDense(len(rows), len(this.classes), nil)
+	for i, row := range rows {
+		neighbours := this.index.search(row, this.k())
+		weights := this.weights(neighbours)
+		var total float64
+		for j, n := range neighbours {
+			c := class[this.labels[n.Index]]
+			proba.Set(i, c, proba.At(i, c)+weights[j])
+			total += weights[j]
+		}
+		for c := range this.classes {
+			proba.Set(i, c, proba.At(i, c)/total)
+		}
+	}
+	return proba, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// REGRESSOR
+
+// Fit stores the samples, with one row for each sample, and their values
+func (this *Regressor) Fit(x mat.Matrix, y []float64) error {
+	if err := this.fit(x, len(y)); err != nil {
+		return err
+	}
+	this.values = append([]float64(nil), y...)
+	return nil
+}
+
+// Predict returns the weighted mean value of the nearest neighbours for
+// each row
+func (this *Regressor) Predict(x mat.Matrix) ([]float64, error) {
+	if this.values == nil {
+		return nil, ErrNotFitted
+	}
+	rows, err := this.query(x)
+	if err != nil {
+		return nil, err
+	}
+	predicted := make([]float64, len(rows))
+	for i, row := range rows {
+		neighbours := this.index.search(row, this.k())
+		weights := this.weights(neighbours)
+		var total float64
+		for j, n := range neighbours {
+			predicted[i] += weights[j] * this.values[n.Index]
+			total += weights[j]
+		}
+		predicted[i] /= total
+	}
+	return predicted, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// NEIGHBOURS
+
+// Neighbours returns the nearest neighbours of a sample in order of
+// increasing distance
+func (this *model) Neighbours(sample []float64) ([]Neighbour, error) {
+	if this.index == nil {
+		return nil, ErrNotFitted
+	} else if len(sample) != this.features {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, this.features)
+	}
+	return this.index.search(sample, this.k()), nil
+}
+
+// Stringify
+func (this *model) String() string {
+	return fmt.Sprintf("knn{ k=%v metric=%v p=%v weights=%v index=%v }", this.k(), this.config.Metric, this.config.P, this.config.Weights, this.config.Index)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// fit checks the configuration and builds the index
+func (this *model) fit(x mat.Matrix, n int) error {
+	rows, cols := x.Dims()
+	if rows != n {
+		return fmt.Errorf("%v: Features and target samples mismatch", ErrBadParameter)
+	} else if rows == 0 || cols == 0 {
+		return ErrEmpty
+	}
+	if this.config.Metric == METRIC_MINKOWSKI && this.config.P < 1 {
+		return fmt.Errorf("%v: Minkowski distance requires P of at least one", ErrBadParameter)
+	}
+	if this.distance = this.config.Metric.distance(this.config.P); this.distance == nil {
+		return fmt.Errorf("%v: Invalid metric", ErrBadParameter)
+	}
+	points := make([][]float64, rows)
+	for i := range points {
+		points[i] = mat.Row(nil, i, x)
+		for _, value := range points[i] {
+			if math.IsNaN(value) {
+				return fmt.Errorf("%v: Missing value in row %v", ErrBadParameter, i)
+			}
+		}
+	}
+	leaf := int(this.config.LeafSize)
+	if leaf == 0 {
+		leaf = DEFAULT_LEAF_SIZE
+	}
+	switch this.config.Index {
+	case INDEX_BRUTE:
+		this.index = &brute{points, this.distance}
+	case INDEX_KDTREE, INDEX_BALLTREE:
+		// Cosine distance does not satisfy the conditions for pruning
+		if this.config.Metric == METRIC_COSINE {
+			return fmt.Errorf("%v: Cosine distance requires the brute force index", ErrBadParameter)
+		} else if this.config.Index == INDEX_KDTREE {
+			this.index = newKDTree(points, this.distance, leaf)
+		} else {
+			this.index = newBallTree(points, this.distance, leaf)
+		}
+	default:
+		return fmt.Errorf("%v: Invalid index", ErrBadParameter)
+	}
+	this.features = cols
+	return nil
+}
+
+// query returns the rows of a matrix to predict
+func (this *model) query(x mat.Matrix) ([][]float64, error) {
+	rows, cols := x.Dims()
+	if cols != this.features {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, this.features)
+	}
+	result := make([][]float64, rows)
+	for i := range result {
+		result[i] = mat.Row(nil, i, x)
+	}
+	return result, nil
+}
+
+// k returns the number of neighbours
+func (this *model) k() int {
+	if this.config.K == 0 {
+		return DEFAULT_K
+	}
+	return int(this.config.K)
+}
+
+// weights returns the weight of each neighbour
+func (this *model) weights(neighbours []Neighbour) []float64 {
+	weights := make([]float64, len(neighbours))
+	exact := false
+	for i, n := range neighbours {
+		switch {
+		case this.config.Weights != WEIGHTS_DISTANCE:
+			weights[i] = 1
+		case n.Distance == 0:
+			weights[i], exact = 1, true
+		default:
+			weights[i] = 1 / n.Distance
+		}
+	}
+	// Only neighbours at zero distance contribute when there are any
+	if exact {
+		for i, n := range neighbours {
+			if n.Distance != 0 {
+				weights[i] = 0
+			}
+		}
+	}
+	return weights
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// unique returns the unique labels in sorted order
+func unique(labels []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, label := range labels {
+		if seen[label] == false {
+			seen[label] = true
+			result = append(result, label)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// argmax returns the index of the largest value, or the first index when
+// values are equal
+func argmax(values []float64) int {
+	best := 0
+	for i, value := range values {
+		if value > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// Stringify
+func (this Weights) String() string {
+	switch this {
+	case WEIGHTS_UNIFORM:
+		return "WEIGHTS_UNIFORM"
+	case WEIGHTS_DISTANCE:
+		return "WEIGHTS_DISTANCE"
+	default:
+		return "[?? Invalid Weights value]"
+	}
+}
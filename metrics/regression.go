@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// MeanAbsoluteError returns the mean of the absolute differences between
+// observed and predicted values
+func MeanAbsoluteError(observed, predicted []float64) (float64, error) {
+	if err := checkSamples(observed, predicted); err != nil {
+		return 0, err
+	}
+	var mae float64
+	for i := range observed {
+		mae += math.Abs(observed[i] - predicted[i])
+	}
+	return mae / float64(len(observed)), nil
+}
+
+// MeanSquaredError returns the mean of the squared differences between
+// observed and predicted values
+func MeanSquaredError(observed, predicted []float64) (float64, error) {
+	if err := checkSamples(observed, predicted); err != nil {
+		return 0, err
+	}
+	var mse float64
+	for i := range observed {
+		mse += (observed[i] - predicted[i]) * (observed[i] - predicted[i])
+	}
+	return mse / float64(len(observed)), nil
+}
+
+// RootMeanSquaredError returns the square root of the mean squared error,
+// which is in the same units as the observed values
+func RootMeanSquaredError(observed, predicted []float64) (float64, error) {
+	if mse, err := MeanSquaredError(observed, predicted); err != nil {
+		return 0, err
+	} else {
+		return math.Sqrt(mse), nil
+	}
+}
+
+// RSquared returns the coefficient of determination, the proportion of the
+// variance in the observed values which is explained by the predicted values
+func RSquared(observed, predicted []float64) (float64, error) {
+	if err := checkSamples(observed, predicted); err != nil {
+		return 0, err
+	}
+	return stat.RSquaredFrom(predicted, observed, nil), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// checkSamples returns an error if the number of samples do not match or
+// there are no samples
+func checkSamples(observed, predicted []float64) error {
+	if len(observed) != len(predicted) {
+		return fmt.Errorf("%v: Observed and predicted samples mismatch", ErrBadParameter)
+	}
+	if len(observed) == 0 {
+		return ErrEmpty
+	}
+	return nil
+}
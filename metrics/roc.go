@@ -0,0 +1,200 @@
+/*
+	Package metrics evaluates the performance of models by comparing
+	observed values with predicted values
+*/
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Criterion determines how the optimal threshold is chosen for a
+// binary classifier
+type Criterion int
+
+// Curve contains the ROC and precision-recall curves for a binary
+// classifier, with one point for each threshold. A sample is predicted
+// positive when its score is greater than or equal to the threshold
+type Curve struct {
+	// Thresholds are in decreasing order, the first is +Inf
+	Thresholds []float64
+
+	// TPR (or recall) and FPR are the true and false positive rates
+	TPR, FPR []float64
+
+	// Precision is the ratio of true positives to predicted positives
+	Precision []float64
+
+	// Positives and Negatives are the number of observed samples
+	// in each class
+	Positives, Negatives uint
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// Maximise Youden's J statistic, TPR - FPR
+	YOUDEN_J Criterion = iota
+	// Maximise the F1 score, the harmonic mean of precision and recall
+	F1
+)
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewCurve sweeps the threshold across all the scores to create the ROC
+// and precision-recall curves. It returns an error if there are no positive
+// or no negative samples
+func NewCurve(observed []bool, scores []float64) (*Curve, error) {
+	if len(observed) != len(scores) {
+		return nil, fmt.Errorf("%v: Observed and predicted samples mismatch", ErrBadParameter)
+	}
+	if len(observed) == 0 {
+		return nil, ErrEmpty
+	}
+
+	// Sort the samples by decreasing score
+	index := make([]int, len(scores))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return scores[index[i]] > scores[index[j]]
+	})
+
+	this := new(Curve)
+	for _, positive := range observed {
+		if positive {
+			this.Positives++
+		} else {
+			this.Negatives++
+		}
+	}
+	if this.Positives == 0 || this.Negatives == 0 {
+		return nil, fmt.Errorf("%v: Both positive and negative samples are required", ErrBadParameter)
+	}
+
+	// The first point predicts nothing as positive
+	this.Thresholds = []float64{math.Inf(1)}
+	this.TPR = []float64{0}
+	this.FPR = []float64{0}
+	this.Precision = []float64{1}
+
+	// Add a point each time the score changes
+	var tp, fp float64
+	for i, j := range index {
+		if observed[j] {
+			tp++
+		} else {
+			fp++
+		}
+		if i < len(index)-1 && scores[index[i+1]] == scores[j] {
+			continue
+		}
+		this.Thresholds = append(this.Thresholds, scores[j])
+		this.TPR = append(this.TPR, tp/float64(this.Positives))
+		this.FPR = append(this.FPR, fp/float64(this.Negatives))
+		this.Precision = append(this.Precision, tp/(tp+fp))
+	}
+
+	return this, nil
+}
+
+// NewCurveFromTable creates the ROC and precision-recall curves from two
+// columns in a table, the observed labels and the predicted scores or
+// probabilities. Labels equal to the positive string are the positive
+// class, and rows with missing values are ignored
+func NewCurveFromTable(table *util.Table, observed, score, positive string) (*Curve, error) {
+	labels, err := table.StringColumn(observed, "")
+	if err != nil {
+		return nil, err
+	}
+	scores, err := table.FloatColumn(score, math.NaN())
+	if err != nil {
+		return nil, err
+	}
+	o := make([]bool, 0, len(labels))
+	s := make([]float64, 0, len(scores))
+	for i := range labels {
+		if labels[i] == "" || math.IsNaN(scores[i]) {
+			continue
+		}
+		o = append(o, labels[i] == positive)
+		s = append(s, scores[i])
+	}
+	return NewCurve(o, s)
+}
+
+// Recall returns the recall for each threshold, which is the same as the
+// true positive rate
+func (this *Curve) Recall() []float64 {
+	return this.TPR
+}
+
+// AUC returns the area under the ROC curve using the trapezoidal rule
+func (this *Curve) AUC() float64 {
+	var area float64
+	for i := 1; i < len(this.FPR); i++ {
+		area += (this.FPR[i] - this.FPR[i-1]) * (this.TPR[i] + this.TPR[i-1]) / 2
+	}
+	return area
+}
+
+// AveragePrecision returns the average precision, which summarises the
+// precision-recall curve as the mean of precision at each threshold
+// weighted by the increase in recall
+func (this *Curve) AveragePrecision() float64 {
+	var ap float64
+	for i := 1; i < len(this.TPR); i++ {
+		ap += (this.TPR[i] - this.TPR[i-1]) * this.Precision[i]
+	}
+	return ap
+}
+
+// OptimalThreshold returns the index of the threshold which maximises the
+// criterion, and the value of the criterion at that threshold
+func (this *Curve) OptimalThreshold(criterion Criterion) (int, float64, error) {
+	best, value := -1, math.Inf(-1)
+	for i := 1; i < len(this.Thresholds); i++ {
+		var v float64
+		switch criterion {
+		case YOUDEN_J:
+			v = this.TPR[i] - this.FPR[i]
+		case F1:
+			if this.Precision[i]+this.TPR[i] > 0 {
+				v = 2 * this.Precision[i] * this.TPR[i] / (this.Precision[i] + this.TPR[i])
+			}
+		default:
+			return -1, 0, fmt.Errorf("%v: Unknown criterion", ErrBadParameter)
+		}
+		if v > value {
+			best, value = i, v
+		}
+	}
+	if best < 0 {
+		return -1, 0, ErrEmpty
+	}
+	return best, value, nil
+}
+
+// Stringify
+func (this Criterion) String() string {
+	switch this {
+	case YOUDEN_J:
+		return "YOUDEN_J"
+	case F1:
+		return "F1"
+	default:
+		return "[?? Invalid Criterion value]"
+	}
+}
@@ -0,0 +1,155 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Encoder encodes the weights of a model as little endian binary values,
+// for use in MarshalBinary
+type Encoder struct {
+	buf bytes.Buffer
+}
+
+// Decoder decodes weights written by an Encoder, for use in
+// UnmarshalBinary. After the first error, each method returns a zero
+// value and the error is returned by Err
+type Decoder struct {
+	data []byte
+	err  error
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	ErrShortData = fmt.Errorf("Unexpected end of weights")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewEncoder returns an encoder which starts with the version of the
+// model's weights, so that the model can check it when decoding
+func NewEncoder(version uint) *Encoder {
+	this := new(Encoder)
+	this.Uint(version)
+	return this
+}
+
+// NewDecoder returns a decoder for weights, and the version written by
+// NewEncoder
+func NewDecoder(data []byte) (*Decoder, uint) {
+	this := &Decoder{data: data}
+	return this, this.Uint()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// ENCODER
+
+// Uint encodes an unsigned integer
+func (this *Encoder) Uint(value uint) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(value))
+	this.buf.Write(buf[:])
+}
+
+// Float encodes a floating point value
+func (this *Encoder) Float(value float64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(value))
+	this.buf.Write(buf[:])
+}
+
+// Floats encodes the number of values followed by each value
+func (this *Encoder) Floats(values []float64) {
+	this.Uint(uint(len(values)))
+	for _, value := range values {
+		this.Float(value)
+	}
+}
+
+// Text encodes the length of a string followed by its bytes
+func (this *Encoder) Text(value string) {
+	this.Uint(uint(len(value)))
+	this.buf.WriteString(value)
+}
+
+// Bytes returns the encoded weights
+func (this *Encoder) Bytes() []byte {
+	return this.buf.Bytes()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// DECODER
+
+// Uint decodes an unsigned integer
+func (this *Decoder) Uint() uint {
+	if buf := this.next(8); buf != nil {
+		return uint(binary.LittleEndian.Uint64(buf))
+	}
+	return 0
+}
+
+// Float decodes a floating point value
+func (this *Decoder) Float() float64 {
+	if buf := this.next(8); buf != nil {
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf))
+	}
+	return 0
+}
+
+// Floats decodes values written by Floats
+func (this *Decoder) Floats() []float64 {
+	n := this.Uint()
+	if this.err != nil || n > uint(len(this.data)/8) {
+		this.fail()
+		return nil
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = this.Float()
+	}
+	return values
+}
+
+// Text decodes a string written by Text
+func (this *Decoder) Text() string {
+	n := this.Uint()
+	if this.err != nil || n > uint(len(this.data)) {
+		this.fail()
+		return ""
+	}
+	return string(this.next(int(n)))
+}
+
+// Err returns the first error, or an error if there are weights which
+// have not been decoded
+func (this *Decoder) Err() error {
+	if this.err == nil && len(this.data) > 0 {
+		return fmt.Errorf("%v: %v bytes remaining", ErrBadFormat, len(this.data))
+	}
+	return this.err
+}
+
+// next returns the next n bytes, or nil after an error
+func (this *Decoder) next(n int) []byte {
+	if this.err != nil {
+		return nil
+	} else if n > len(this.data) {
+		this.fail()
+		return nil
+	}
+	buf := this.data[:n]
+	this.data = this.data[n:]
+	return buf
+}
+
+func (this *Decoder) fail() {
+	if this.err == nil {
+		this.err = ErrShortData
+	}
+	this.data = nil
+}
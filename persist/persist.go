@@ -0,0 +1,179 @@
+/*
+	Package persist saves trained models to files and loads them again, so
+	that a model can be trained once and used for prediction later. A file
+	starts with JSON metadata, which records the format version, the type
+	of the model, when it was saved and a checksum, followed by the binary
+	weights of the model. The checksum is validated when the model is
+	loaded.
+*/
+package persist
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Model is a model which can be saved and loaded, by encoding its
+// parameters as binary weights
+type Model interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Metadata describes the model in a file
+type Metadata struct {
+	// Format is the version of the file format
+	Format uint `json:"format"`
+
+	// Type is the Go type of the model
+	Type string `json:"type"`
+
+	// Created is the time the model was saved
+	Created time.Time `json:"created"`
+
+	// Size is the number of bytes of weights, and Checksum is the SHA-256
+	// hash of the weights
+	Size     int    `json:"size"`
+	Checksum string `json:"checksum"`
+
+	// Properties are optional values which describe how the model was
+	// trained, such as the names of the features
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// The version of the file format written by Save
+	FORMAT_VERSION = 1
+
+	// The bytes at the start of every file
+	MAGIC = "GOMODEL\n"
+
+	// The largest metadata and weights which are read, so that a corrupt
+	// header cannot cause a large allocation
+	MAX_METADATA_SIZE = 1 << 20
+	MAX_WEIGHTS_SIZE  = 1 << 32
+)
+
+var (
+	ErrBadFormat = fmt.Errorf("Not a model file")
+	ErrVersion   = fmt.Errorf("Unsupported format version")
+	ErrType      = fmt.Errorf("Model type mismatch")
+	ErrChecksum  = fmt.Errorf("Checksum mismatch")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Save writes a model to a file, with optional properties
+func Save(filename string, model Model, properties map[string]string) error {
+	fh, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := Write(fh, model, properties); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
+
+// Load reads a model from a file, returning the metadata. The model should
+// be the same type as the model which was saved
+func Load(filename string, model Model) (*Metadata, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	return Read(bufio.NewReader(fh), model)
+}
+
+// Write writes the magic bytes, the length of the metadata as a 32-bit big
+// endian value, the metadata as JSON and the weights of the model
+func Write(w io.Writer, model Model, properties map[string]string) error {
+	weights, err := model.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	checksum := sha256.Sum256(weights)
+	metadata, err := json.Marshal(&Metadata{
+		Format:     FORMAT_VERSION,
+		Type:       fmt.Sprintf("%T", model),
+		Created:    time.Now().UTC(),
+		Size:       len(weights),
+		Checksum:   hex.EncodeToString(checksum[:]),
+		Properties: properties,
+	})
+	if err != nil {
+		return err
+	}
+	var header bytes.Buffer
+	header.WriteString(MAGIC)
+	binary.Write(&header, binary.BigEndian, uint32(len(metadata)))
+	header.Write(metadata)
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	} else if _, err := w.Write(weights); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Read reads a model written by Write, returning the metadata. It returns
+// an error if the format version is not supported, the type of the model
+// is different, the size of the weights is not the size in the metadata
+// or the checksum of the weights does not match
+func Read(r io.Reader, model Model) (*Metadata, error) {
+	magic := make([]byte, len(MAGIC))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != MAGIC {
+		return nil, ErrBadFormat
+	}
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, ErrBadFormat
+	} else if length > MAX_METADATA_SIZE {
+		return nil, fmt.Errorf("%v: Metadata size %v is too large", ErrBadFormat, length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, ErrBadFormat
+	}
+	metadata := new(Metadata)
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrBadFormat, err)
+	} else if metadata.Format == 0 || metadata.Format > FORMAT_VERSION {
+		return nil, fmt.Errorf("%v: %v", ErrVersion, metadata.Format)
+	} else if t := fmt.Sprintf("%T", model); metadata.Type != t {
+		return nil, fmt.Errorf("%v: Expected %v but file contains %v", ErrType, t, metadata.Type)
+	} else if metadata.Size < 0 || int64(metadata.Size) > MAX_WEIGHTS_SIZE {
+		return nil, fmt.Errorf("%v: Invalid weights size %v", ErrBadFormat, metadata.Size)
+	}
+
+	// Read one byte more than the size of the weights, to check there are
+	// no more bytes than expected
+	weights, err := io.ReadAll(io.LimitReader(r, int64(metadata.Size)+1))
+	if err != nil {
+		return nil, err
+	} else if len(weights) != metadata.Size {
+		return nil, fmt.Errorf("%v: Expected %v bytes of weights but read %v", ErrChecksum, metadata.Size, len(weights))
+	}
+	if checksum := sha256.Sum256(weights); hex.EncodeToString(checksum[:]) != metadata.Checksum {
+		return nil, ErrChecksum
+	}
+	if err := model.UnmarshalBinary(weights); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
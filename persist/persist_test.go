@@ -0,0 +1,129 @@
+package persist_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/djthorpe/MachineLearning/persist"
+	"github.com/djthorpe/MachineLearning/regression"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// fitted returns a linear model fitted on y = 1 + 2a - 3b
+func fitted(t *testing.T) *regression.Linear {
+	x := mat.NewDense(5, 2, []float64{0, 0, 1, 0, 0, 1, 1, 1, 2, 3})
+	y := []float64{1, 3, -2, 0, -4}
+	model := regression.NewLinear(0)
+	if err := model.Fit(x, y); err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+// save writes a fitted model to a file and returns the contents
+func save(t *testing.T) (string, []byte) {
+	filename := filepath.Join(t.TempDir(), "linear.model")
+	if err := persist.Save(filename, fitted(t), map[string]string{"x": "a,b"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filename, data
+}
+
+// isError returns true if err is target, or wraps target as a prefix
+func isError(err, target error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), target.Error())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func Test_Persist_001(t *testing.T) {
+	// A saved model is loaded with the same weights and properties
+	filename, _ := save(t)
+	expected := fitted(t)
+	model := regression.NewLinear(1)
+	if metadata, err := persist.Load(filename, model); err != nil {
+		t.Fatal(err)
+	} else if metadata.Format != persist.FORMAT_VERSION || metadata.Type != "*regression.Linear" {
+		t.Errorf("Unexpected metadata: %+v", metadata)
+	} else if metadata.Properties["x"] != "a,b" {
+		t.Errorf("Unexpected properties: %v", metadata.Properties)
+	}
+	if model.Lambda != expected.Lambda || model.Intercept != expected.Intercept {
+		t.Errorf("Expected %v, got %v", expected, model)
+	} else if len(model.Coefficients) != len(expected.Coefficients) {
+		t.Fatalf("Expected %v, got %v", expected, model)
+	}
+	for j := range expected.Coefficients {
+		if model.Coefficients[j] != expected.Coefficients[j] {
+			t.Errorf("Expected %v, got %v", expected, model)
+		}
+	}
+}
+
+func Test_Persist_002(t *testing.T) {
+	// A corrupted byte in the weights fails the checksum
+	_, data := save(t)
+	data[len(data)-1] ^= 0xFF
+	if _, err := persist.Read(bytes.NewReader(data), regression.NewLinear(0)); isError(err, persist.ErrChecksum) == false {
+		t.Errorf("Expected %v, got %v", persist.ErrChecksum, err)
+	}
+}
+
+func Test_Persist_003(t *testing.T) {
+	// A truncated file is an error, wherever it is truncated
+	_, data := save(t)
+	for _, n := range []int{0, 4, len(persist.MAGIC) + 2, len(data) / 2, len(data) - 1} {
+		if _, err := persist.Read(bytes.NewReader(data[:n]), regression.NewLinear(0)); err == nil {
+			t.Errorf("Expected an error for %v of %v bytes", n, len(data))
+		}
+	}
+	if _, err := persist.Read(bytes.NewReader(data[:len(data)-1]), regression.NewLinear(0)); isError(err, persist.ErrChecksum) == false {
+		t.Errorf("Expected %v, got %v", persist.ErrChecksum, err)
+	}
+
+	// Extra bytes after the weights are also an error
+	if _, err := persist.Read(bytes.NewReader(append(data, 0)), regression.NewLinear(0)); isError(err, persist.ErrChecksum) == false {
+		t.Errorf("Expected %v, got %v", persist.ErrChecksum, err)
+	}
+}
+
+func Test_Persist_004(t *testing.T) {
+	// A file without the magic bytes is not a model file
+	_, data := save(t)
+	data[0] = 'X'
+	if _, err := persist.Read(bytes.NewReader(data), regression.NewLinear(0)); isError(err, persist.ErrBadFormat) == false {
+		t.Errorf("Expected %v, got %v", persist.ErrBadFormat, err)
+	}
+}
+
+func Test_Persist_005(t *testing.T) {
+	// Sizes in the header which are too large are rejected before they
+	// are allocated
+	var header bytes.Buffer
+	header.WriteString(persist.MAGIC)
+	binary.Write(&header, binary.BigEndian, uint32(0xFFFFFFFF))
+	if _, err := persist.Read(bytes.NewReader(header.Bytes()), regression.NewLinear(0)); isError(err, persist.ErrBadFormat) == false {
+		t.Errorf("Expected %v, got %v", persist.ErrBadFormat, err)
+	}
+
+	for _, size := range []string{"-1", "1099511627776"} {
+		metadata := `{"format":1,"type":"*regression.Linear","size":` + size + `,"checksum":""}`
+		header.Reset()
+		header.WriteString(persist.MAGIC)
+		binary.Write(&header, binary.BigEndian, uint32(len(metadata)))
+		header.WriteString(metadata)
+		if _, err := persist.Read(bytes.NewReader(header.Bytes()), regression.NewLinear(0)); isError(err, persist.ErrBadFormat) == false {
+			t.Errorf("Expected %v for size %v, got %v", persist.ErrBadFormat, size, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// EncoderConfig is the configuration for a one-hot encoder
+type EncoderConfig struct {
+	// Columns to encode, or empty to encode the feature columns which
+	// are not numeric
+	Columns []string
+
+	// DropFirst does not add a column for the first category of each
+	// column, which is then encoded as zero in every other column
+	DropFirst bool
+}
+
+// OneHotEncoder replaces each categorical column with a column for each
+// category learnt from the training table, named "column=category", which
+// is one for rows in the category and zero otherwise
+type OneHotEncoder struct {
+	config     EncoderConfig
+	columns    []string
+	categories [][]string
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewOneHotEncoder returns a one-hot encoder with the configuration
+func NewOneHotEncoder(config EncoderConfig) *OneHotEncoder {
+	return &OneHotEncoder{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit learns the categories of each column in sorted order, ignoring
+// missing values
+func (this *OneHotEncoder) Fit(table *util.Table, columns []string) error {
+	columns = selected(this.config.Columns, columns, func(column string) bool {
+		return numeric(table, column) == false
+	})
+	categories := make([][]string, len(columns))
+	for j, column := range columns {
+		values, err := table.StringColumn(column, "")
+		if err != nil {
+			return err
+		}
+		exists := make(map[string]bool)
+		for _, value := range values {
+			if value != "" && exists[value] == false {
+				exists[value] = true
+				categories[j] = append(categories[j], value)
+			}
+		}
+		if len(categories[j]) == 0 {
+			return fmt.Errorf("%v: No values in %v", ErrEmpty, column)
+		}
+		sort.Strings(categories[j])
+	}
+	this.columns, this.categories = columns, categories
+	return nil
+}
+
+// Transform returns a new table with each column replaced by a column for
+// each category. A category not seen in the training table is zero in
+// every column, and a missing value is missing in every column
+func (this *OneHotEncoder) Transform(table *util.Table) (*util.Table, error) {
+	if this.columns == nil {
+		return nil, ErrNotFitted
+	}
+	encoded := make(map[string]bool, len(this.columns))
+	for _, column := range this.columns {
+		encoded[column] = true
+	}
+	remaining := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		if encoded[column] == false {
+			remaining = append(remaining, column)
+		}
+	}
+	out, err := table.Select(remaining...)
+	if err != nil {
+		return nil, err
+	}
+	for j, column := range this.columns {
+		values, err := table.StringColumn(column, "")
+		if err != nil {
+			return nil, err
+		}
+		for k, category := range this.categories[j] {
+			if k == 0 && this.config.DropFirst {
+				continue
+			}
+			onehot := make([]float64, len(values))
+			for i, value := range values {
+				switch value {
+				case "":
+					onehot[i] = math.NaN()
+				case category:
+					onehot[i] = 1
+				}
+			}
+			if err := out.AppendFloatColumn(column+"="+category, onehot); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// Columns returns the encoded columns
+func (this *OneHotEncoder) Columns() []string {
+	return this.columns
+}
+
+// Categories returns the categories of an encoded column, in the order of
+// the columns which replace it
+func (this *OneHotEncoder) Categories(column string) []string {
+	for j := range this.columns {
+		if this.columns[j] == column {
+			return this.categories[j]
+		}
+	}
+	return nil
+}
+
+// Stringify
+func (this *OneHotEncoder) String() string {
+	return fmt.Sprintf("onehot{ columns=%v drop_first=%v }", this.columns, this.config.DropFirst)
+}
@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// MatrixClassifier is a classifier which is fitted on a matrix of
+// features, with one row for each sample, and the label of each sample
+type MatrixClassifier interface {
+	Fit(x mat.Matrix, labels []string) error
+	Predict(x mat.Matrix) ([]string, error)
+}
+
+// MatrixRegressor is a regressor which is fitted on a matrix of features,
+// with one row for each sample, and the value of each sample
+type MatrixRegressor interface {
+	Fit(x mat.Matrix, y []float64) error
+	Predict(x mat.Matrix) ([]float64, error)
+}
+
+// classifier adapts a matrix classifier to a table
+type classifier struct {
+	model    MatrixClassifier
+	features []string
+}
+
+// regressor adapts a matrix regressor to a table
+type regressor struct {
+	model    MatrixRegressor
+	features []string
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewClassifier returns an estimator which fits a matrix classifier on
+// the feature columns of a table
+func NewClassifier(model MatrixClassifier) Estimator {
+	return &classifier{model: model}
+}
+
+// NewRegressor returns a regressor which fits a matrix regressor on the
+// feature columns of a table
+func NewRegressor(model MatrixRegressor) Regressor {
+	return &regressor{model: model}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CLASSIFIER
+
+// Fit fits the model on the rows of the table with a label
+func (this *classifier) Fit(table *util.Table, features []string, target string) error {
+	labels, err := table.StringColumn(target, "")
+	if err != nil {
+		return err
+	}
+	rows := make([]int, 0, len(labels))
+	for i, label := range labels {
+		if label != "" {
+			rows = append(rows, i)
+		}
+	}
+	x, err := matrix(table, features, rows)
+	if err != nil {
+		return err
+	}
+	y := make([]string, len(rows))
+	for i, row := range rows {
+		y[i] = labels[row]
+	}
+	if err := this.model.Fit(x, y); err != nil {
+		return err
+	}
+	this.features = features
+	return nil
+}
+
+// Predict returns the label predicted for each row
+func (this *classifier) Predict(table *util.Table) ([]string, error) {
+	if this.features == nil {
+		return nil, ErrNotFitted
+	} else if x, err := table.Matrix(this.features...); err != nil {
+		return nil, err
+	} else {
+		return this.model.Predict(x)
+	}
+}
+
+// Stringify
+func (this *classifier) String() string {
+	return fmt.Sprint(this.model)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// REGRESSOR
+
+// Fit fits the model on the rows of the table with a value
+func (this *regressor) Fit(table *util.Table, features []string, target string) error {
+	values, err := table.FloatColumn(target, math.NaN())
+	if err != nil {
+		return err
+	}
+	rows := make([]int, 0, len(values))
+	for i, value := range values {
+		if math.IsNaN(value) == false {
+			rows = append(rows, i)
+		}
+	}
+	x, err := matrix(table, features, rows)
+	if err != nil {
+		return err
+	}
+	y := make([]float64, len(rows))
+	for i, row := range rows {
+		y[i] = values[row]
+	}
+	if err := this.model.Fit(x, y); err != nil {
+		return err
+	}
+	this.features = features
+	return nil
+}
+
+// Predict returns the value predicted for each row
+func (this *regressor) Predict(table *util.Table) ([]float64, error) {
+	if this.features == nil {
+		return nil, ErrNotFitted
+	} else if x, err := table.Matrix(this.features...); err != nil {
+		return nil, err
+	} else {
+		return this.model.Predict(x)
+	}
+}
+
+// Stringify
+func (this *regressor) String() string {
+	return fmt.Sprint(this.model)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// matrix returns the feature columns of the rows of a table
+func matrix(table *util.Table, features []string, rows []int) (*mat.Dense, error) {
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	} else if subset, err := table.Subsample(rows); err != nil {
+		return nil, err
+	} else {
+		return subset.Matrix(features...)
+	}
+}
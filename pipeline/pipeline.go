@@ -0,0 +1,262 @@
+/*
+	Package pipeline chains transformers, which scale or encode the
+	columns of a table, with a model which predicts a target column. The
+	transformers and the model are fitted on a training table, and the
+	statistics learnt from the training table are used to transform test
+	data, so that nothing about the test data leaks into the model.
+	Decision trees, ensembles and naive Bayes are fitted on a table and
+	can be used in a pipeline directly. Models which are fitted on a
+	matrix of features, such as k-nearest neighbours or support vector
+	machines, can be used in a pipeline with NewClassifier and
+	NewRegressor.
+*/
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/djthorpe/MachineLearning/util"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Transformer learns statistics of columns from a training table, and
+// transforms tables using those statistics
+type Transformer interface {
+	// Fit learns the statistics of the columns of a training table
+	Fit(table *util.Table, columns []string) error
+
+	// Transform returns a new table with the columns transformed, which
+	// does not change the table
+	Transform(table *util.Table) (*util.Table, error)
+}
+
+// Estimator is a classifier which is fitted on the feature columns of a
+// table to predict the labels in the target column
+type Estimator interface {
+	Fit(table *util.Table, features []string, target string) error
+	Predict(table *util.Table) ([]string, error)
+}
+
+// Regressor is fitted on the feature columns of a table to predict the
+// values in the target column
+type Regressor interface {
+	Fit(table *util.Table, features []string, target string) error
+	Predict(table *util.Table) ([]float64, error)
+}
+
+// Pipeline transforms a table with each transformer in turn and then
+// predicts labels with a classifier
+type Pipeline struct {
+	steps
+	estimator Estimator
+}
+
+// RegressionPipeline transforms a table with each transformer in turn and
+// then predicts values with a regressor
+type RegressionPipeline struct {
+	steps
+	regressor Regressor
+}
+
+// steps are the transformers common to pipelines
+type steps struct {
+	transformers []Transformer
+	features     []string
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewPipeline returns a pipeline which applies the transformers in order
+// before the classifier
+func NewPipeline(estimator Estimator, transformers ...Transformer) *Pipeline {
+	return &Pipeline{steps: steps{transformers: transformers}, estimator: estimator}
+}
+
+// NewRegressionPipeline returns a pipeline which applies the transformers
+// in order before the regressor
+func NewRegressionPipeline(regressor Regressor, transformers ...Transformer) *RegressionPipeline {
+	return &RegressionPipeline{steps: steps{transformers: transformers}, regressor: regressor}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PIPELINE
+
+// Fit fits each transformer on the training table transformed by the
+// transformers before it, and then fits the classifier on the transformed
+// feature columns
+func (this *Pipeline) Fit(table *util.Table, features []string, target string) error {
+	if this.estimator == nil {
+		return fmt.Errorf("%v: Missing classifier", ErrBadParameter)
+	} else if table, err := this.fit(table, features, target); err != nil {
+		return err
+	} else {
+		return this.estimator.Fit(table, this.features, target)
+	}
+}
+
+// Predict transforms the table and returns the label predicted for each
+// row
+func (this *Pipeline) Predict(table *util.Table) ([]string, error) {
+	if table, err := this.Transform(table); err != nil {
+		return nil, err
+	} else {
+		return this.estimator.Predict(table)
+	}
+}
+
+// Stringify
+func (this *Pipeline) String() string {
+	return fmt.Sprintf("pipeline{ %v }", this.describe(this.estimator))
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// REGRESSION PIPELINE
+
+// Fit fits each transformer on the training table transformed by the
+// transformers before it, and then fits the regressor on the transformed
+// feature columns
+func (this *RegressionPipeline) Fit(table *util.Table, features []string, target string) error {
+	if this.regressor == nil {
+		return fmt.Errorf("%v: Missing regressor", ErrBadParameter)
+	} else if table, err := this.fit(table, features, target); err != nil {
+		return err
+	} else {
+		return this.regressor.Fit(table, this.features, target)
+	}
+}
+
+// Predict transforms the table and returns the value predicted for each
+// row
+func (this *RegressionPipeline) Predict(table *util.Table) ([]float64, error) {
+	if table, err := this.Transform(table); err != nil {
+		return nil, err
+	} else {
+		return this.regressor.Predict(table)
+	}
+}
+
+// Stringify
+func (this *RegressionPipeline) String() string {
+	return fmt.Sprintf("pipeline{ %v }", this.describe(this.regressor))
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STEPS
+
+// Transform returns the table transformed by each transformer in turn,
+// using the statistics of the training table
+func (this *steps) Transform(table *util.Table) (*util.Table, error) {
+	if this.features == nil {
+		return nil, ErrNotFitted
+	}
+	for _, transformer := range this.transformers {
+		if out, err := transformer.Transform(table); err != nil {
+			return nil, err
+		} else {
+			table = out
+		}
+	}
+	return table, nil
+}
+
+// Features returns the feature columns of the transformed table, which
+// the model is fitted on
+func (this *steps) Features() []string {
+	return this.features
+}
+
+// fit fits and applies each transformer in turn, and returns the
+// transformed table. The features are updated with the columns removed
+// and added by each transformer
+func (this *steps) fit(table *util.Table, features []string, target string) (*util.Table, error) {
+	if len(features) == 0 {
+		return nil, fmt.Errorf("%v: Expected at least one feature", ErrBadParameter)
+	} else if len(table.Rows) == 0 {
+		return nil, ErrEmpty
+	}
+	this.features = nil
+	for _, column := range features {
+		if column == target {
+			return nil, fmt.Errorf("%v: Target %v is a feature", ErrBadParameter, target)
+		}
+	}
+	for _, transformer := range this.transformers {
+		if err := transformer.Fit(table, features); err != nil {
+			return nil, err
+		} else if out, err := transformer.Transform(table); err != nil {
+			return nil, err
+		} else {
+			features = columns(features, table, out)
+			table = out
+		}
+	}
+	this.features = features
+	return table, nil
+}
+
+func (this *steps) describe(model interface{}) string {
+	parts := make([]string, 0, len(this.transformers)+1)
+	for _, transformer := range this.transformers {
+		parts = append(parts, fmt.Sprint(transformer))
+	}
+	return strings.Join(append(parts, fmt.Sprint(model)), " -> ")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// columns returns the features which remain after a table is transformed,
+// followed by any columns added by the transform
+func columns(features []string, before, after *util.Table) []string {
+	existing := make(map[string]bool, len(before.Columns))
+	for _, column := range before.Columns {
+		existing[column] = true
+	}
+	remaining := make(map[string]bool, len(after.Columns))
+	for _, column := range after.Columns {
+		remaining[column] = true
+	}
+	result := make([]string, 0, len(features))
+	for _, column := range features {
+		if remaining[column] {
+			result = append(result, column)
+		}
+	}
+	for _, column := range after.Columns {
+		if existing[column] == false {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// selected returns the columns of the config if set, or the columns for
+// which the filter returns true
+func selected(config, columns []string, filter func(string) bool) []string {
+	if len(config) > 0 {
+		return config
+	}
+	result := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if filter(column) {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// numeric returns true if a column contains only numeric values
+func numeric(table *util.Table, column string) bool {
+	t, err := table.TypeForColumn(column)
+	return err == nil && t != "" && t != "time"
+}
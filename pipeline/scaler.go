@@ -0,0 +1,150 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/stat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Scaling determines how a column is scaled
+type Scaling int
+
+// ScalerConfig is the configuration for a scaler
+type ScalerConfig struct {
+	// Scaling of each column
+	Scaling Scaling
+
+	// Columns to scale, or empty to scale the numeric feature columns
+	Columns []string
+}
+
+// Scaler subtracts an offset from each value in a column and divides by a
+// scale, both learnt from the training table
+type Scaler struct {
+	config  ScalerConfig
+	columns []string
+	offset  []float64
+	scale   []float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// Zero mean and unit standard deviation
+	SCALING_STANDARD Scaling = iota
+	// Values between zero and one
+	SCALING_MINMAX
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewScaler returns a scaler with the configuration
+func NewScaler(config ScalerConfig) *Scaler {
+	return &Scaler{config: config}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit learns the offset and scale of each column from the values which
+// are not missing. A column with a single value is not scaled
+func (this *Scaler) Fit(table *util.Table, columns []string) error {
+	if this.config.Scaling != SCALING_STANDARD && this.config.Scaling != SCALING_MINMAX {
+		return fmt.Errorf("%v: Invalid scaling: %v", ErrBadParameter, this.config.Scaling)
+	}
+	columns = selected(this.config.Columns, columns, func(column string) bool {
+		return numeric(table, column)
+	})
+	offset, scale := make([]float64, len(columns)), make([]float64, len(columns))
+	for j, column := range columns {
+		values, err := table.FloatColumn(column, math.NaN())
+		if err != nil {
+			return err
+		}
+		values = finite(values)
+		if len(values) == 0 {
+			return fmt.Errorf("%v: No values in %v", ErrEmpty, column)
+		}
+		switch this.config.Scaling {
+		case SCALING_STANDARD:
+			offset[j] = stat.Mean(values, nil)
+			if len(values) > 1 {
+				scale[j] = stat.StdDev(values, nil)
+			}
+		case SCALING_MINMAX:
+			min, max := values[0], values[0]
+			for _, value := range values {
+				min, max = math.Min(min, value), math.Max(max, value)
+			}
+			offset[j], scale[j] = min, max-min
+		}
+		if scale[j] == 0 {
+			scale[j] = 1
+		}
+	}
+	this.columns, this.offset, this.scale = columns, offset, scale
+	return nil
+}
+
+// Transform returns a new table with each column scaled. Missing values
+// remain missing
+func (this *Scaler) Transform(table *util.Table) (*util.Table, error) {
+	if this.columns == nil {
+		return nil, ErrNotFitted
+	}
+	out, err := table.Select(table.Columns...)
+	if err != nil {
+		return nil, err
+	}
+	for j, column := range this.columns {
+		values, err := out.FloatColumn(column, math.NaN())
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			if err := out.SetFloat(i, column, (value-this.offset[j])/this.scale[j]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// Columns returns the scaled columns
+func (this *Scaler) Columns() []string {
+	return this.columns
+}
+
+// Stringify
+func (this *Scaler) String() string {
+	return fmt.Sprintf("scaler{ scaling=%v columns=%v }", this.config.Scaling, this.columns)
+}
+
+func (this Scaling) String() string {
+	switch this {
+	case SCALING_STANDARD:
+		return "SCALING_STANDARD"
+	case SCALING_MINMAX:
+		return "SCALING_MINMAX"
+	default:
+		return "[?? Invalid Scaling value]"
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// finite returns the values which are not NaN
+func finite(values []float64) []float64 {
+	result := make([]float64, 0, len(values))
+	for _, value := range values {
+		if math.IsNaN(value) == false {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package plots
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// BiplotOptions determine how a biplot is drawn
+type BiplotOptions struct {
+	// Features are the names of the features, drawn at the end of each
+	// loading
+	Features []string
+
+	// Labels are optional categories for each row, used to colour points
+	Labels []string
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Biplot returns a scatter plot of the scores on the first two
+// components, with a line for the loading of each feature on the two
+// components. The loadings have one row for each component and one column
+// for each feature, and are scaled to the range of the scores
+func Biplot(scores, loadings mat.Matrix, opts BiplotOptions) (*plot.Plot, error) {
+	rows, k := scores.Dims()
+	components, features := loadings.Dims()
+	if rows == 0 {
+		return nil, ErrEmpty
+	} else if k < 2 || components < 2 {
+		return nil, ErrBadParameter
+	} else if opts.Labels != nil && len(opts.Labels) != rows {
+		return nil, ErrBadParameter
+	} else if opts.Features != nil && len(opts.Features) != features {
+		return nil, ErrBadParameter
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = "Biplot"
+	p.X.Label.Text = "Component 1"
+	p.Y.Label.Text = "Component 2"
+	p.Legend.Top = true
+
+	// Draw the scores grouped by label
+	names := make([]string, 0)
+	groups := make(map[string]plotter.XYs)
+	var extent float64
+	for i := 0; i < rows; i++ {
+		x, y := scores.At(i, 0), scores.At(i, 1)
+		if math.IsNaN(x) || math.IsNaN(y) {
+			continue
+		}
+		extent = math.Max(extent, math.Max(math.Abs(x), math.Abs(y)))
+		label := ""
+		if opts.Labels != nil {
+			label = opts.Labels[i]
+		}
+		if _, exists := groups[label]; exists == false {
+			names = append(names, label)
+		}
+		groups[label] = append(groups[label], struct{ X, Y float64 }{x, y})
+	}
+	if len(names) == 0 {
+		return nil, ErrEmpty
+	}
+	for i, name := range names {
+		if scatter, err := plotter.NewScatter(groups[name]); err != nil {
+			return nil, err
+		} else {
+			scatter.GlyphStyle.Color = colorForIndex(i, 160)
+			scatter.GlyphStyle.Radius = vg.Points(1.5)
+			p.Add(scatter)
+			if name != "" {
+				p.Legend.Add(name, scatter)
+			}
+		}
+	}
+
+	// Draw the loadings from the origin, scaled so the longest reaches the
+	// furthest score
+	var longest float64
+	for j := 0; j < features; j++ {
+		longest = math.Max(longest, math.Hypot(loadings.At(0, j), loadings.At(1, j)))
+	}
+	if longest == 0 {
+		return p, nil
+	}
+	scale := extent / longest
+	ends := make(plotter.XYs, features)
+	for j := range ends {
+		ends[j].X, ends[j].Y = scale*loadings.At(0, j), scale*loadings.At(1, j)
+		if line, err := plotter.NewLine(plotter.XYs{{0, 0}, ends[j]}); err != nil {
+			return nil, err
+		} else {
+			line.Color = referenceColor
+			p.Add(line)
+		}
+	}
+	if opts.Features != nil {
+		if labels, err := plotter.NewLabels(plotter.XYLabels{XYs: ends, Labels: opts.Features}); err != nil {
+			return nil, err
+		} else {
+			p.Add(labels)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,123 @@
+package plots
+
+import (
+	"fmt"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// BoxPlotOptions determine which columns are plotted and how
+type BoxPlotOptions struct {
+	// Columns are the numeric columns to plot, or empty for all
+	// numeric columns in the table
+	Columns []string
+
+	// Group is an optional categorical column. When set, one box is
+	// drawn for each distinct value in the column, side by side
+	Group string
+
+	// Violin draws a violin plot around each box
+	Violin bool
+
+	// Width is the total width for each column, or zero for a default
+	Width vg.Length
+
+	// Title is the plot title, or empty for a default title
+	Title string
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// The default width of the boxes for each column
+	DEFAULT_BOX_WIDTH = 50
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// BoxPlot returns a box plot for numeric columns of a table. Values more
+// than 1.5 times the interquartile range beyond the quartiles are drawn
+// as outliers
+func BoxPlot(table *util.Table, opts BoxPlotOptions) (*plot.Plot, error) {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		for _, c := range table.NumericColumns() {
+			if c != opts.Group {
+				columns = append(columns, c)
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return nil, ErrEmpty
+	}
+	width := opts.Width
+	if width <= 0 {
+		width = vg.Points(DEFAULT_BOX_WIDTH)
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Title != "" {
+		p.Title.Text = opts.Title
+	} else if opts.Group != "" {
+		p.Title.Text = fmt.Sprintf("Box plots by %v", opts.Group)
+	} else {
+		p.Title.Text = "Box plots"
+	}
+	p.Y.Label.Text = "Values"
+	p.Legend.Top = true
+
+	// Draw the boxes for each column, with groups side by side
+	for i, column := range columns {
+		names, groups, err := groupValues(table, column, opts.Group)
+		if err != nil {
+			return nil, err
+		}
+		w := width / vg.Length(len(names))
+		for j, name := range names {
+			offset := w * (vg.Length(j) - vg.Length(len(names)-1)/2)
+			color := colorForIndex(j, 255)
+			if opts.Violin {
+				if violin, err := NewViolin(w*0.9, float64(i), groups[name]); err != nil {
+					return nil, err
+				} else {
+					violin.Offset = offset
+					violin.FillColor = colorForIndex(j, 96)
+					p.Add(violin)
+				}
+			}
+			box_width := w * 0.8
+			if opts.Violin {
+				box_width = w * 0.2
+			}
+			if box, err := plotter.NewBoxPlot(box_width, float64(i), plotter.Values(groups[name])); err != nil {
+				return nil, err
+			} else {
+				box.Offset = offset
+				if name != "" {
+					box.BoxStyle.Color = color
+					box.MedianStyle.Color = color
+					box.WhiskerStyle.Color = color
+					box.GlyphStyle.Color = color
+				}
+				p.Add(box)
+			}
+			if name != "" && i == 0 {
+				p.Legend.Add(name, swatch{colorForIndex(j, 96), plotter.DefaultLineStyle})
+			}
+		}
+	}
+
+	// Label the X axis with the column names
+	p.NominalX(columns...)
+
+	// Return success
+	return p, nil
+}
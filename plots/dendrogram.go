@@ -0,0 +1,77 @@
+package plots
+
+import (
+	"github.com/djthorpe/MachineLearning/cluster"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// DendrogramOptions determine how a dendrogram is drawn
+type DendrogramOptions struct {
+	// Labels are the names of the samples, which are drawn under the
+	// leaves when set
+	Labels []string
+
+	// Threshold draws a reference line at a distance when set
+	Threshold float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Dendrogram returns a plot of the tree of merges from agglomerative
+// clustering, with the samples along the X axis in leaf order and the
+// distance of each merge on the Y axis
+func Dendrogram(model *cluster.Agglomerative, opts DendrogramOptions) (*plot.Plot, error) {
+	merges, order := model.Merges(), model.Order()
+	if len(order) == 0 {
+		return nil, ErrEmpty
+	} else if opts.Labels != nil && len(opts.Labels) != len(order) {
+		return nil, ErrBadParameter
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = "Dendrogram"
+	p.Y.Label.Text = "Distance"
+
+	// Leaves are spaced along the X axis and each merge is drawn between
+	// the middle of its clusters
+	n := len(order)
+	x, y := make([]float64, n+len(merges)), make([]float64, n+len(merges))
+	for i, sample := range order {
+		x[sample] = float64(i)
+	}
+	for i, merge := range merges {
+		node := n + i
+		x[node], y[node] = (x[merge.A]+x[merge.B])/2, merge.Distance
+		pts := plotter.XYs{{x[merge.A], y[merge.A]}, {x[merge.A], y[node]}, {x[merge.B], y[node]}, {x[merge.B], y[merge.B]}}
+		if line, err := plotter.NewLine(pts); err != nil {
+			return nil, err
+		} else {
+			p.Add(line)
+		}
+	}
+	if opts.Threshold > 0 {
+		if line, err := referenceLine(-0.5, opts.Threshold, float64(n)-0.5, opts.Threshold); err != nil {
+			return nil, err
+		} else {
+			p.Add(line)
+		}
+	}
+
+	// Label the leaves
+	if opts.Labels != nil {
+		names := make([]string, n)
+		for i, sample := range order {
+			names[i] = opts.Labels[sample]
+		}
+		p.NominalX(names...)
+	} else {
+		p.HideX()
+	}
+	p.Y.Min = 0
+	return p, nil
+}
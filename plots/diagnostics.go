@@ -0,0 +1,252 @@
+package plots
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	// The color used for reference lines
+	referenceColor = color.RGBA{R: 255, A: 255}
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// LossCurve returns a line plot of the training loss for each epoch
+func LossCurve(history []float64) (*plot.Plot, error) {
+	if len(history) == 0 {
+		return nil, ErrEmpty
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = "Loss curve"
+	p.X.Label.Text = "Epoch"
+	p.Y.Label.Text = "Loss"
+
+	pts := make(plotter.XYs, 0, len(history))
+	for i, loss := range history {
+		if math.IsNaN(loss) || math.IsInf(loss, 0) {
+			continue
+		}
+		pts = append(pts, struct{ X, Y float64 }{float64(i), loss})
+	}
+	if line, err := plotter.NewLine(pts); err != nil {
+		return nil, err
+	} else {
+		line.Color = colorForIndex(0, 255)
+		p.Add(line)
+	}
+	return p, nil
+}
+
+// ResidualsVsFitted returns a scatter plot of residuals (observed minus
+// predicted) against the predicted values, with a reference line at zero
+func ResidualsVsFitted(observed, predicted []float64) (*plot.Plot, error) {
+	residuals, err := Residuals(observed, predicted)
+	if err != nil {
+		return nil, err
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = "Residuals vs fitted"
+	p.X.Label.Text = "Fitted"
+	p.Y.Label.Text = "Residual"
+	if scatter, err := plotter.NewScatter(xyPoints(predicted, residuals)); err != nil {
+		return nil, err
+	} else {
+		p.Add(scatter)
+	}
+	if line, err := referenceLine(floats.Min(predicted), 0, floats.Max(predicted), 0); err != nil {
+		return nil, err
+	} else {
+		p.Add(line)
+	}
+	return p, nil
+}
+
+// ResidualHistogram returns a histogram of residuals (observed minus
+// predicted), using Sturges' formula for the number of bins
+func ResidualHistogram(observed, predicted []float64) (*plot.Plot, error) {
+	residuals, err := Residuals(observed, predicted)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := BinEdges(residuals, BIN_STURGES, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = "Histogram of residuals"
+	p.X.Label.Text = "Residual"
+	p.Y.Label.Text = "Count"
+	p.Add(&plotter.Histogram{
+		Bins:      histogramBins(residuals, edges, false),
+		Width:     (edges[len(edges)-1] - edges[0]) / float64(len(edges)-1),
+		FillColor: colorForIndex(0, 255),
+		LineStyle: plotter.DefaultLineStyle,
+	})
+	return p, nil
+}
+
+// QQPlot returns a normal quantile-quantile plot of values, which are
+// usually residuals. Points lie close to the reference line when the
+// values are normally distributed
+func QQPlot(values []float64) (*plot.Plot, error) {
+	values = finiteValues(values)
+	if len(values) < 2 {
+		return nil, ErrEmpty
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mean, std := stat.MeanStdDev(sorted, nil)
+
+	// Compute the theoretical quantiles of a standard normal
+	theoretical := make([]float64, len(sorted))
+	for i := range sorted {
+		theoretical[i] = distuv.UnitNormal.Quantile((float64(i) + 0.5) / float64(len(sorted)))
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = "Normal Q-Q plot"
+	p.X.Label.Text = "Theoretical quantiles"
+	p.Y.Label.Text = "Sample quantiles"
+	if scatter, err := plotter.NewScatter(xyPoints(theoretical, sorted)); err != nil {
+		return nil, err
+	} else {
+		p.Add(scatter)
+	}
+	zmin, zmax := theoretical[0], theoretical[len(theoretical)-1]
+	if line, err := referenceLine(zmin, mean+std*zmin, zmax, mean+std*zmax); err != nil {
+		return nil, err
+	} else {
+		p.Add(line)
+	}
+	return p, nil
+}
+
+// PredictedVsObserved returns a scatter plot of predicted against observed
+// values, with a y=x reference line for perfect predictions
+func PredictedVsObserved(observed, predicted []float64) (*plot.Plot, error) {
+	if _, err := Residuals(observed, predicted); err != nil {
+		return nil, err
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = "Predicted vs observed"
+	p.X.Label.Text = "Observed"
+	p.Y.Label.Text = "Predicted"
+	if scatter, err := plotter.NewScatter(xyPoints(observed, predicted)); err != nil {
+		return nil, err
+	} else {
+		p.Add(scatter)
+	}
+	min := math.Min(floats.Min(observed), floats.Min(predicted))
+	max := math.Max(floats.Max(observed), floats.Max(predicted))
+	if line, err := referenceLine(min, min, max, max); err != nil {
+		return nil, err
+	} else {
+		p.Add(line)
+	}
+	return p, nil
+}
+
+// Diagnostics returns a 2x2 grid with residuals vs fitted, a residual
+// histogram, a Q-Q plot of residuals and predicted vs observed
+func Diagnostics(observed, predicted []float64) (Grid, error) {
+	residuals, err := Residuals(observed, predicted)
+	if err != nil {
+		return nil, err
+	}
+	grid := Grid{make([]*plot.Plot, 2), make([]*plot.Plot, 2)}
+	if grid[0][0], err = ResidualsVsFitted(observed, predicted); err != nil {
+		return nil, err
+	}
+	if grid[0][1], err = ResidualHistogram(observed, predicted); err != nil {
+		return nil, err
+	}
+	if grid[1][0], err = QQPlot(residuals); err != nil {
+		return nil, err
+	}
+	if grid[1][1], err = PredictedVsObserved(observed, predicted); err != nil {
+		return nil, err
+	}
+	return grid, nil
+}
+
+// DiagnosticsForTable returns the diagnostics grid for observed and
+// predicted columns of a table. Rows with missing values are ignored
+func DiagnosticsForTable(table *util.Table, observed, predicted string) (Grid, error) {
+	_, groups, err := groupPoints(table, observed, predicted, "")
+	if err != nil {
+		return nil, err
+	}
+	pts := groups[""]
+	o, p := make([]float64, len(pts)), make([]float64, len(pts))
+	for i := range pts {
+		o[i], p[i] = pts[i].X, pts[i].Y
+	}
+	return Diagnostics(o, p)
+}
+
+// Residuals returns observed minus predicted values, or an error if the
+// number of samples do not match
+func Residuals(observed, predicted []float64) ([]float64, error) {
+	if len(observed) != len(predicted) {
+		return nil, fmt.Errorf("%v: Observed and predicted samples mismatch", ErrBadParameter)
+	}
+	if len(observed) == 0 {
+		return nil, ErrEmpty
+	}
+	residuals := make([]float64, len(observed))
+	floats.SubTo(residuals, observed, predicted)
+	return residuals, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// xyPoints returns points from two slices of the same length
+func xyPoints(x, y []float64) plotter.XYs {
+	pts := make(plotter.XYs, len(x))
+	for i := range pts {
+		pts[i].X = x[i]
+		pts[i].Y = y[i]
+	}
+	return pts
+}
+
+// referenceLine returns a dashed line between two points
+func referenceLine(x0, y0, x1, y1 float64) (*plotter.Line, error) {
+	pts := plotter.XYs{{x0, y0}, {x1, y1}}
+	if line, err := plotter.NewLine(pts); err != nil {
+		return nil, err
+	} else {
+		line.Color = referenceColor
+		line.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+		return line, nil
+	}
+}
@@ -0,0 +1,242 @@
+package plots
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// BinStrategy determines how the edges of histogram bins are chosen
+type BinStrategy int
+
+// HistogramOptions determine which column is plotted and how
+type HistogramOptions struct {
+	// Column is the name of the numeric column to plot
+	Column string
+
+	// Group is an optional categorical column. When set, one histogram
+	// is overlaid for each distinct value in the column
+	Group string
+
+	// Strategy determines how bin edges are computed
+	Strategy BinStrategy
+
+	// Bins is the number of bins for BIN_FIXED
+	Bins uint
+
+	// Edges are the explicit bin edges for BIN_EDGES
+	Edges []float64
+
+	// Density normalizes each histogram so the area sums to one
+	Density bool
+
+	// KDE overlays a kernel density estimate, which implies Density
+	KDE bool
+
+	// Title is the plot title, or empty for a default title
+	Title string
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	BIN_FIXED BinStrategy = iota
+	BIN_STURGES
+	BIN_FREEDMAN_DIACONIS
+	BIN_EDGES
+)
+
+const (
+	// The number of points used to draw a kernel density estimate
+	KDE_POINTS = 200
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Histogram returns a histogram plot for a numeric column of a table
+func Histogram(table *util.Table, opts HistogramOptions) (*plot.Plot, error) {
+	names, groups, err := groupValues(table, opts.Column, opts.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute edges across all groups so that overlaid bins line up
+	all := make([]float64, 0, len(table.Rows))
+	for _, name := range names {
+		all = append(all, groups[name]...)
+	}
+	edges, err := BinEdges(all, opts.Strategy, opts.Bins, opts.Edges)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Title != "" {
+		p.Title.Text = opts.Title
+	} else if opts.Group != "" {
+		p.Title.Text = fmt.Sprintf("Histogram of %v by %v", opts.Column, opts.Group)
+	} else {
+		p.Title.Text = fmt.Sprintf("Histogram of %v", opts.Column)
+	}
+	p.X.Label.Text = opts.Column
+	p.Legend.Top = true
+	density := opts.Density || opts.KDE
+	if density {
+		p.Y.Label.Text = "Density"
+	} else {
+		p.Y.Label.Text = "Count"
+	}
+
+	// Add a histogram for each group, with translucent fill when
+	// more than one group is overlaid
+	alpha := uint8(255)
+	if len(names) > 1 {
+		alpha = 96
+	}
+	for i, name := range names {
+		h := &plotter.Histogram{
+			Bins:      histogramBins(groups[name], edges, density),
+			Width:     (edges[len(edges)-1] - edges[0]) / float64(len(edges)-1),
+			FillColor: colorForIndex(i, alpha),
+			LineStyle: plotter.DefaultLineStyle,
+		}
+		p.Add(h)
+		if name != "" {
+			p.Legend.Add(name, h)
+		}
+		if opts.KDE && len(groups[name]) > 1 {
+			if line, err := kdeLine(groups[name], edges[0], edges[len(edges)-1]); err != nil {
+				return nil, err
+			} else {
+				line.Color = colorForIndex(i, 255)
+				line.Width = vg.Points(1.5)
+				p.Add(line)
+			}
+		}
+	}
+
+	// Return success
+	return p, nil
+}
+
+// BinEdges returns the edges of histogram bins for a set of values using
+// the given strategy. For BIN_FIXED the number of bins is used, and for
+// BIN_EDGES the explicit edges are checked and returned
+func BinEdges(values []float64, strategy BinStrategy, bins uint, edges []float64) ([]float64, error) {
+	if strategy == BIN_EDGES {
+		if len(edges) < 2 {
+			return nil, fmt.Errorf("%v: At least two bin edges are required", ErrBadParameter)
+		}
+		for i := 1; i < len(edges); i++ {
+			if edges[i] <= edges[i-1] {
+				return nil, fmt.Errorf("%v: Bin edges must be increasing", ErrBadParameter)
+			}
+		}
+		return edges, nil
+	}
+
+	values = finiteValues(values)
+	if len(values) == 0 {
+		return nil, ErrEmpty
+	}
+	min, max := floats.Min(values), floats.Max(values)
+	if min == max {
+		// All values are the same, so create a single bin around them
+		min, max = min-0.5, max+0.5
+	}
+
+	// Determine the number of bins
+	var n int
+	switch strategy {
+	case BIN_FIXED:
+		if bins == 0 {
+			return nil, fmt.Errorf("%v: Number of bins must be greater than zero", ErrBadParameter)
+		}
+		n = int(bins)
+	case BIN_STURGES:
+		n = sturgesBins(len(values))
+	case BIN_FREEDMAN_DIACONIS:
+		sorted := make([]float64, len(values))
+		copy(sorted, values)
+		sort.Float64s(sorted)
+		iqr := stat.Quantile(0.75, stat.Empirical, sorted, nil) - stat.Quantile(0.25, stat.Empirical, sorted, nil)
+		if width := 2 * iqr / math.Cbrt(float64(len(values))); width > 0 {
+			n = int(math.Ceil((max - min) / width))
+		} else {
+			// Fall back to Sturges when the IQR is zero
+			n = sturgesBins(len(values))
+		}
+	default:
+		return nil, fmt.Errorf("%v: Unknown bin strategy", ErrBadParameter)
+	}
+
+	// Create evenly spaced edges
+	edges = make([]float64, n+1)
+	floats.Span(edges, min, max)
+	return edges, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// sturgesBins returns the number of bins using Sturges' formula
+func sturgesBins(n int) int {
+	return int(math.Ceil(math.Log2(float64(n)))) + 1
+}
+
+// histogramBins counts the values within each bin. The last bin includes
+// its upper edge, and values outside the edges are ignored. When density
+// is true the weights are scaled so that the total area is one
+func histogramBins(values []float64, edges []float64, density bool) []plotter.HistogramBin {
+	bins := make([]plotter.HistogramBin, len(edges)-1)
+	for i := range bins {
+		bins[i].Min, bins[i].Max = edges[i], edges[i+1]
+	}
+	var total float64
+	for _, v := range values {
+		if v < edges[0] || v > edges[len(edges)-1] {
+			continue
+		}
+		// Find the first edge greater than the value
+		i := sort.SearchFloat64s(edges, v)
+		if i < len(edges) && edges[i] == v {
+			i++
+		}
+		if i > len(bins) {
+			i = len(bins)
+		}
+		bins[i-1].Weight++
+		total++
+	}
+	if density && total > 0 {
+		for i := range bins {
+			bins[i].Weight /= total * (bins[i].Max - bins[i].Min)
+		}
+	}
+	return bins
+}
+
+// kdeLine returns a line plotting the kernel density estimate for values
+// between min and max
+func kdeLine(values []float64, min, max float64) (*plotter.Line, error) {
+	kde := KernelDensity(values, 0)
+	xs := make([]float64, KDE_POINTS)
+	floats.Span(xs, min, max)
+	pts := make(plotter.XYs, len(xs))
+	for i, x := range xs {
+		pts[i].X = x
+		pts[i].Y = kde(x)
+	}
+	return plotter.NewLine(pts)
+}
@@ -0,0 +1,51 @@
+package plots
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// KernelDensity returns a gaussian kernel density estimate for the values,
+// using the bandwidth provided. If the bandwidth is zero or negative, then
+// Silverman's rule of thumb is used to choose the bandwidth
+func KernelDensity(values []float64, bandwidth float64) func(float64) float64 {
+	values = finiteValues(values)
+	if bandwidth <= 0 {
+		bandwidth = SilvermanBandwidth(values)
+	}
+	n := float64(len(values))
+	norm := 1.0 / (n * bandwidth * math.Sqrt(2*math.Pi))
+	return func(x float64) float64 {
+		var sum float64
+		for _, v := range values {
+			u := (x - v) / bandwidth
+			sum += math.Exp(-0.5 * u * u)
+		}
+		return sum * norm
+	}
+}
+
+// SilvermanBandwidth returns a bandwidth for a gaussian kernel using
+// Silverman's rule of thumb, or 1.0 if there are too few values
+func SilvermanBandwidth(values []float64) float64 {
+	if len(values) < 2 {
+		return 1.0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	std := stat.StdDev(sorted, nil)
+	iqr := stat.Quantile(0.75, stat.Empirical, sorted, nil) - stat.Quantile(0.25, stat.Empirical, sorted, nil)
+	spread := std
+	if iqr > 0 && iqr/1.34 < spread {
+		spread = iqr / 1.34
+	}
+	if spread <= 0 {
+		return 1.0
+	}
+	return 0.9 * spread * math.Pow(float64(len(values)), -0.2)
+}
@@ -0,0 +1,212 @@
+package plots
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// PairPlotOptions determine which columns are included in a pair plot
+type PairPlotOptions struct {
+	// Columns are the numeric columns to plot, or empty for all
+	// numeric columns in the table
+	Columns []string
+
+	// Label is an optional categorical column used to colour points
+	Label string
+
+	// Bins is the number of bins for the histograms on the diagonal,
+	// or zero to use Sturges' formula
+	Bins uint
+}
+
+// Grid is a set of plots arranged in rows and columns
+type Grid [][]*plot.Plot
+
+///////////////////////////////////////////////////////////////////////////////
+
+// PairPlot returns an NxN grid of plots for N numeric columns, with
+// scatter plots of each pair of columns and histograms on the diagonal
+func PairPlot(table *util.Table, opts PairPlotOptions) (Grid, error) {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		for _, c := range table.NumericColumns() {
+			if c != opts.Label {
+				columns = append(columns, c)
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return nil, ErrEmpty
+	}
+
+	strategy := BIN_STURGES
+	if opts.Bins > 0 {
+		strategy = BIN_FIXED
+	}
+
+	grid := make(Grid, len(columns))
+	for row, y := range columns {
+		grid[row] = make([]*plot.Plot, len(columns))
+		for col, x := range columns {
+			var p *plot.Plot
+			var err error
+			if row == col {
+				p, err = Histogram(table, HistogramOptions{
+					Column:   x,
+					Group:    opts.Label,
+					Strategy: strategy,
+					Bins:     opts.Bins,
+				})
+			} else {
+				p, err = scatterPlot(table, x, y, opts.Label)
+			}
+			if err != nil {
+				return nil, err
+			}
+			// Only label the outer axes, and only keep one legend
+			p.Title.Text = ""
+			p.X.Label.Text = ""
+			p.Y.Label.Text = ""
+			if row == len(columns)-1 {
+				p.X.Label.Text = x
+			}
+			if col == 0 {
+				p.Y.Label.Text = y
+			}
+			if row != 0 || col != len(columns)-1 {
+				if p.Legend, err = plot.NewLegend(); err != nil {
+					return nil, err
+				}
+			}
+			grid[row][col] = p
+		}
+	}
+
+	// Return success
+	return grid, nil
+}
+
+// SaveGrid writes a grid of plots to a single file, where the output format
+// is determined by the file extension, which should be png, svg or pdf.
+// The width and height are for the whole image
+func SaveGrid(grid Grid, width, height vg.Length, filename string) error {
+	if err := checkFormat(filename); err != nil {
+		return err
+	}
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return ErrEmpty
+	}
+	if width <= 0 {
+		width = DEFAULT_SIZE * vg.Length(len(grid[0]))
+	}
+	if height <= 0 {
+		height = DEFAULT_SIZE * vg.Length(len(grid))
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	canvas, err := draw.NewFormattedCanvas(width, height, format)
+	if err != nil {
+		return err
+	}
+	dc := draw.New(canvas)
+	tiles := draw.Tiles{
+		Rows: len(grid),
+		Cols: len(grid[0]),
+		PadX: vg.Millimeter,
+		PadY: vg.Millimeter,
+	}
+	for row := range grid {
+		for col, p := range grid[row] {
+			if p != nil {
+				p.Draw(tiles.At(dc, col, row))
+			}
+		}
+	}
+
+	// Write the file
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := canvas.WriteTo(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// scatterPlot returns a scatter plot of two columns, with points coloured
+// by an optional label column
+func scatterPlot(table *util.Table, x, y, label string) (*plot.Plot, error) {
+	names, groups, err := groupPoints(table, x, y, label)
+	if err != nil {
+		return nil, err
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.X.Label.Text = x
+	p.Y.Label.Text = y
+	p.Legend.Top = true
+	for i, name := range names {
+		if scatter, err := plotter.NewScatter(groups[name]); err != nil {
+			return nil, err
+		} else {
+			scatter.GlyphStyle.Color = colorForIndex(i, 255)
+			scatter.GlyphStyle.Radius = vg.Points(1.5)
+			p.Add(scatter)
+			if name != "" {
+				p.Legend.Add(name, scatter)
+			}
+		}
+	}
+	return p, nil
+}
+
+// groupPoints returns the (x,y) points for two columns split by the values
+// in a categorical group column. Rows where either value is missing are
+// ignored. When group is empty, a single unnamed group is returned
+func groupPoints(table *util.Table, x, y, group string) ([]string, map[string]plotter.XYs, error) {
+	xs, err := table.FloatColumn(x, math.NaN())
+	if err != nil {
+		return nil, nil, err
+	}
+	ys, err := table.FloatColumn(y, math.NaN())
+	if err != nil {
+		return nil, nil, err
+	}
+	labels := make([]string, len(xs))
+	if group != "" {
+		if labels, err = table.StringColumn(group, ""); err != nil {
+			return nil, nil, err
+		}
+	}
+	names := make([]string, 0)
+	groups := make(map[string]plotter.XYs)
+	for i := range xs {
+		if math.IsNaN(xs[i]) || math.IsNaN(ys[i]) || (group != "" && labels[i] == "") {
+			continue
+		}
+		if _, exists := groups[labels[i]]; exists == false {
+			names = append(names, labels[i])
+		}
+		groups[labels[i]] = append(groups[labels[i]], struct{ X, Y float64 }{xs[i], ys[i]})
+	}
+	if len(names) == 0 {
+		return nil, nil, ErrEmpty
+	}
+	return names, groups, nil
+}
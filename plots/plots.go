@@ -0,0 +1,109 @@
+/*
+	Package plots renders charts from util.Table data using gonum/plot.
+	Each function returns a *plot.Plot which can then be written to a file
+	using the Save function.
+*/
+package plots
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	ErrEmpty             = fmt.Errorf("No values to plot")
+	ErrBadParameter      = fmt.Errorf("Bad parameter")
+	ErrUnsupportedFormat = fmt.Errorf("Unsupported output format, expected png, svg or pdf")
+)
+
+const (
+	// The default size of a plot, in inches
+	DEFAULT_SIZE = 4 * vg.Inch
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Save writes a plot to a file, where the output format is determined by
+// the file extension, which should be png, svg or pdf
+func Save(p *plot.Plot, width, height vg.Length, filename string) error {
+	if err := checkFormat(filename); err != nil {
+		return err
+	}
+	if width <= 0 {
+		width = DEFAULT_SIZE
+	}
+	if height <= 0 {
+		height = DEFAULT_SIZE
+	}
+	return p.Save(width, height, filename)
+}
+
+// checkFormat returns an error if the filename does not have a
+// supported extension
+func checkFormat(filename string) error {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".png", ".svg", ".pdf":
+		return nil
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// colorForIndex returns a color from the default palette with an alpha
+// value, so that overlaid plots remain visible
+func colorForIndex(i int, alpha uint8) color.Color {
+	r, g, b, _ := plotutil.Color(i).RGBA()
+	return color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: alpha}
+}
+
+// finiteValues returns values with any NaN or infinite values removed
+func finiteValues(values []float64) []float64 {
+	finite := make([]float64, 0, len(values))
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		finite = append(finite, v)
+	}
+	return finite
+}
+
+// groupValues returns the values for a column split by the values in a
+// categorical group column. The group names are returned in the order
+// they first appear. When group is empty, a single unnamed group is
+// returned
+func groupValues(table *util.Table, column, group string) ([]string, map[string][]float64, error) {
+	values, err := table.FloatColumn(column, math.NaN())
+	if err != nil {
+		return nil, nil, err
+	}
+	if group == "" {
+		return []string{""}, map[string][]float64{"": finiteValues(values)}, nil
+	}
+	labels, err := table.StringColumn(group, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0)
+	groups := make(map[string][]float64)
+	for i, label := range labels {
+		if label == "" || math.IsNaN(values[i]) || math.IsInf(values[i], 0) {
+			continue
+		}
+		if _, exists := groups[label]; exists == false {
+			names = append(names, label)
+		}
+		groups[label] = append(groups[label], values[i])
+	}
+	return names, groups, nil
+}
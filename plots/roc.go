@@ -0,0 +1,84 @@
+package plots
+
+import (
+	"fmt"
+
+	"github.com/djthorpe/MachineLearning/metrics"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ROCCurve returns a plot of the true positive rate against the false
+// positive rate, with a diagonal reference line for a random classifier.
+// If optimal is a valid threshold index, the point is marked on the curve
+func ROCCurve(curve *metrics.Curve, optimal int) (*plot.Plot, error) {
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = fmt.Sprintf("ROC curve (AUC=%.3f)", curve.AUC())
+	p.X.Label.Text = "False positive rate"
+	p.Y.Label.Text = "True positive rate"
+	if err := addCurve(p, curve.FPR, curve.TPR, optimal); err != nil {
+		return nil, err
+	}
+	if line, err := referenceLine(0, 0, 1, 1); err != nil {
+		return nil, err
+	} else {
+		p.Add(line)
+	}
+	return p, nil
+}
+
+// PrecisionRecallCurve returns a plot of precision against recall, with a
+// horizontal reference line at the proportion of positive samples. If
+// optimal is a valid threshold index, the point is marked on the curve
+func PrecisionRecallCurve(curve *metrics.Curve, optimal int) (*plot.Plot, error) {
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = fmt.Sprintf("Precision-recall curve (AP=%.3f)", curve.AveragePrecision())
+	p.X.Label.Text = "Recall"
+	p.Y.Label.Text = "Precision"
+	if err := addCurve(p, curve.Recall(), curve.Precision, optimal); err != nil {
+		return nil, err
+	}
+	baseline := float64(curve.Positives) / float64(curve.Positives+curve.Negatives)
+	if line, err := referenceLine(0, baseline, 1, baseline); err != nil {
+		return nil, err
+	} else {
+		p.Add(line)
+	}
+	return p, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// addCurve adds a line to a plot with an optional marker at one point
+func addCurve(p *plot.Plot, x, y []float64, marker int) error {
+	if line, err := plotter.NewLine(xyPoints(x, y)); err != nil {
+		return err
+	} else {
+		line.Color = colorForIndex(0, 255)
+		line.Width = vg.Points(1.5)
+		p.Add(line)
+	}
+	if marker >= 0 && marker < len(x) {
+		if scatter, err := plotter.NewScatter(plotter.XYs{{x[marker], y[marker]}}); err != nil {
+			return err
+		} else {
+			scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+			scatter.GlyphStyle.Radius = vg.Points(4)
+			scatter.GlyphStyle.Color = colorForIndex(1, 255)
+			p.Add(scatter)
+		}
+	}
+	p.X.Min, p.X.Max = 0, 1
+	p.Y.Min, p.Y.Max = 0, 1
+	return nil
+}
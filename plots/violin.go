@@ -0,0 +1,153 @@
+package plots
+
+import (
+	"image/color"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Violin implements the plot.Plotter interface, drawing a mirrored kernel
+// density estimate of a set of values at a location on the X axis
+type Violin struct {
+	// Values are the sorted values for the violin
+	Values []float64
+
+	// Location is the location of the violin along the X axis
+	Location float64
+
+	// Offset is added to the x location of the violin
+	Offset vg.Length
+
+	// Width is the maximum width of the violin
+	Width vg.Length
+
+	// Median is the median value of the data
+	Median float64
+
+	// FillColor is the color used to fill the violin, or nil
+	FillColor color.Color
+
+	// LineStyle is the style of the outline of the violin
+	draw.LineStyle
+
+	// density is the estimated density at each point in ys
+	ys, density []float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewViolin returns a violin plot for the values at a location on the
+// X axis, with the given maximum width
+func NewViolin(w vg.Length, loc float64, values []float64) (*Violin, error) {
+	values = finiteValues(values)
+	if len(values) == 0 {
+		return nil, ErrEmpty
+	}
+	this := new(Violin)
+	this.Values = make([]float64, len(values))
+	copy(this.Values, values)
+	sort.Float64s(this.Values)
+	this.Location = loc
+	this.Width = w
+	this.Median = stat.Quantile(0.5, stat.Empirical, this.Values, nil)
+	this.LineStyle = plotter.DefaultLineStyle
+
+	// Estimate the density between the extreme values
+	this.ys = make([]float64, KDE_POINTS)
+	this.density = make([]float64, KDE_POINTS)
+	min, max := this.Values[0], this.Values[len(this.Values)-1]
+	if min == max {
+		this.ys = []float64{min, max}
+		this.density = []float64{1, 1}
+	} else {
+		kde := KernelDensity(this.Values, 0)
+		floats.Span(this.ys, min, max)
+		for i, y := range this.ys {
+			this.density[i] = kde(y)
+		}
+		if peak := floats.Max(this.density); peak > 0 {
+			floats.Scale(1/peak, this.density)
+		}
+	}
+
+	return this, nil
+}
+
+// Plot draws the violin on canvas c and plot plt
+func (this *Violin) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	x := trX(this.Location)
+	if !c.ContainsX(x) {
+		return
+	}
+	x += this.Offset
+
+	// Make the outline, going up the right hand side and down the left
+	pts := make([]vg.Point, 0, 2*len(this.ys)+1)
+	for i, y := range this.ys {
+		pts = append(pts, vg.Point{X: x + vg.Length(this.density[i])*this.Width/2, Y: trY(y)})
+	}
+	for i := len(this.ys) - 1; i >= 0; i-- {
+		pts = append(pts, vg.Point{X: x - vg.Length(this.density[i])*this.Width/2, Y: trY(this.ys[i])})
+	}
+	if this.FillColor != nil {
+		c.FillPolygon(this.FillColor, c.ClipPolygonY(pts))
+	}
+	pts = append(pts, pts[0])
+	c.StrokeLines(this.LineStyle, c.ClipLinesY(pts)...)
+}
+
+// DataRange returns the minimum and maximum x and y values
+func (this *Violin) DataRange() (float64, float64, float64, float64) {
+	return this.Location, this.Location, this.Values[0], this.Values[len(this.Values)-1]
+}
+
+// GlyphBoxes returns a glyph box for the width of the violin so that
+// it is not clipped at the edge of the plot
+func (this *Violin) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	return []plot.GlyphBox{
+		{
+			X: plt.X.Norm(this.Location),
+			Y: plt.Y.Norm(this.Median),
+			Rectangle: vg.Rectangle{
+				Min: vg.Point{X: this.Offset - this.Width/2},
+				Max: vg.Point{X: this.Offset + this.Width/2},
+			},
+		},
+	}
+}
+
+// Thumbnail draws a filled rectangle for the legend
+func (this *Violin) Thumbnail(c *draw.Canvas) {
+	swatch{this.FillColor, this.LineStyle}.Thumbnail(c)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// swatch draws a filled rectangle in a legend
+type swatch struct {
+	color.Color
+	draw.LineStyle
+}
+
+func (this swatch) Thumbnail(c *draw.Canvas) {
+	pts := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+	}
+	if this.Color != nil {
+		c.FillPolygon(this.Color, c.ClipPolygonXY(pts))
+	}
+	pts = append(pts, pts[0])
+	c.StrokeLines(this.LineStyle, c.ClipLinesXY(pts)...)
+}
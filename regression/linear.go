@@ -0,0 +1,159 @@
+/*
+	Package regression fits models which predict a continuous target value
+	from one or more features.
+*/
+package regression
+
+import (
+	"fmt"
+
+	"github.com/djthorpe/MachineLearning/persist"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Linear is a linear regression model, fitted by least squares with an
+// optional L2 (ridge) penalty on the coefficients
+type Linear struct {
+	// Lambda is the ridge penalty, or zero for ordinary least squares.
+	// The intercept is not penalised
+	Lambda float64
+
+	// Intercept and Coefficients are set when the model is fitted
+	Intercept    float64
+	Coefficients []float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// The version of the weights written by MarshalBinary
+	LINEAR_VERSION = 1
+)
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrSingular     = fmt.Errorf("Features are linearly dependent")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewLinear returns a linear regression model with a ridge penalty, which
+// should be zero for ordinary least squares
+func NewLinear(lambda float64) *Linear {
+	return &Linear{Lambda: lambda}
+}
+
+// Fit estimates the intercept and coefficients from a matrix of features,
+// with one row for each sample, and the target value for each sample. The
+// features and target are centred so that the intercept is not penalised,
+// and the normal equations are solved using a Cholesky decomposition
+func (this *Linear) Fit(x mat.Matrix, y []float64) error {
+	rows, cols := x.Dims()
+	if rows != len(y) {
+		return fmt.Errorf("%v: Features and target samples mismatch", ErrBadParameter)
+	}
+	if rows == 0 || cols == 0 {
+		return ErrEmpty
+	}
+	if this.Lambda < 0 {
+		return fmt.Errorf("%v: Lambda cannot be negative", ErrBadParameter)
+	}
+
+	// Centre the features and target
+	means := make([]float64, cols)
+	centred := mat.DenseCopyOf(x)
+	for j := range means {
+		means[j] = stat.Mean(mat.Col(nil, j, centred), nil)
+		for i := 0; i < rows; i++ {
+			centred.Set(i, j, centred.At(i, j)-means[j])
+		}
+	}
+	ymean := stat.Mean(y, nil)
+	target := mat.NewVecDense(rows, nil)
+	for i := range y {
+		target.SetVec(i, y[i]-ymean)
+	}
+
+	// Solve (XᵀX + λI)β = Xᵀy
+	xtx := mat.NewSymDense(cols, nil)
+	xtx.SymOuterK(1, centred.T())
+	for j := 0; j < cols; j++ {
+		xtx.SetSym(j, j, xtx.At(j, j)+this.Lambda)
+	}
+	xty := mat.NewVecDense(cols, nil)
+	xty.MulVec(centred.T(), target)
+	var chol mat.Cholesky
+	if ok := chol.Factorize(xtx); ok == false {
+		return ErrSingular
+	}
+	beta := mat.NewVecDense(cols, nil)
+	if err := chol.SolveVec(beta, xty); err != nil {
+		return ErrSingular
+	}
+
+	// Set the coefficients and the intercept
+	this.Coefficients = make([]float64, cols)
+	this.Intercept = ymean
+	for j := range this.Coefficients {
+		this.Coefficients[j] = beta.AtVec(j)
+		this.Intercept -= this.Coefficients[j] * means[j]
+	}
+
+	// Return success
+	return nil
+}
+
+// Predict returns the predicted value for each row of features
+func (this *Linear) Predict(x mat.Matrix) ([]float64, error) {
+	if this.Coefficients == nil {
+		return nil, ErrNotFitted
+	}
+	rows, cols := x.Dims()
+	if cols != len(this.Coefficients) {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, len(this.Coefficients))
+	}
+	predicted := make([]float64, rows)
+	for i := range predicted {
+		predicted[i] = this.Intercept
+		for j, c := range this.Coefficients {
+			predicted[i] += c * x.At(i, j)
+		}
+	}
+	return predicted, nil
+}
+
+// MarshalBinary encodes the penalty, intercept and coefficients
+func (this *Linear) MarshalBinary() ([]byte, error) {
+	if this.Coefficients == nil {
+		return nil, ErrNotFitted
+	}
+	encoder := persist.NewEncoder(LINEAR_VERSION)
+	encoder.Float(this.Lambda)
+	encoder.Float(this.Intercept)
+	encoder.Floats(this.Coefficients)
+	return encoder.Bytes(), nil
+}
+
+// UnmarshalBinary decodes weights written by MarshalBinary
+func (this *Linear) UnmarshalBinary(data []byte) error {
+	decoder, version := persist.NewDecoder(data)
+	if version != LINEAR_VERSION {
+		return fmt.Errorf("%v: Unsupported linear model version %v", ErrBadParameter, version)
+	}
+	lambda, intercept, coefficients := decoder.Float(), decoder.Float(), decoder.Floats()
+	if err := decoder.Err(); err != nil {
+		return err
+	}
+	this.Lambda, this.Intercept, this.Coefficients = lambda, intercept, coefficients
+	return nil
+}
+
+// Stringify
+func (this *Linear) String() string {
+	return fmt.Sprintf("linear{ lambda=%v intercept=%v coefficients=%v }", this.Lambda, this.Intercept, this.Coefficients)
+}
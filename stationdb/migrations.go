@@ -0,0 +1,255 @@
+package stationdb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRUCTURES
+
+// Migration changes the schema from the previous version to Version
+type Migration struct {
+	Version     uint
+	Description string
+	Up          func(tx *sql.Tx) error
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// The table which records applied migrations
+	SQL_MIGRATIONS_TABLENAME = "migrations"
+
+	// The table created by earlier versions of chapter1/sql_writer.go
+	SQL_LEGACY_TABLENAME = "station"
+
+	// The layout of last_reported in the legacy table, which is the
+	// output of time.Time.String()
+	LEGACY_TIME_LAYOUT = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+	// The columns and constraints of the tables created by the first
+	// migration
+	SQL_STATIONS_SCHEMA = `station_id TEXT NOT NULL PRIMARY KEY,
+		name TEXT,
+		lat REAL,
+		lon REAL,
+		capacity INTEGER CHECK (capacity >= 0)`
+	SQL_SNAPSHOTS_SCHEMA = `snapshot_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		last_updated INTEGER NOT NULL UNIQUE,
+		fetched INTEGER NOT NULL`
+	SQL_READINGS_SCHEMA = `snapshot_id INTEGER NOT NULL REFERENCES snapshots(snapshot_id),
+		station_id TEXT NOT NULL REFERENCES stations(station_id),
+		last_reported INTEGER NOT NULL,
+		is_installed INTEGER NOT NULL CHECK (is_installed IN (0,1)),
+		is_renting INTEGER NOT NULL CHECK (is_renting IN (0,1)),
+		is_returning INTEGER NOT NULL CHECK (is_returning IN (0,1)),
+		bikes_available INTEGER NOT NULL CHECK (bikes_available >= 0),
+		bikes_disabled INTEGER NOT NULL CHECK (bikes_disabled >= 0),
+		docks_available INTEGER NOT NULL CHECK (docks_available >= 0),
+		docks_disabled INTEGER NOT NULL CHECK (docks_disabled >= 0),
+		PRIMARY KEY (snapshot_id, station_id)`
+)
+
+var (
+	// MIGRATIONS are applied in order to bring a database up to date. New
+	// migrations should only ever be appended
+	MIGRATIONS = []Migration{
+		{1, "Create stations, snapshots and readings tables", createTables},
+		{2, "Add reading indexes and latest status view", exec(
+			`CREATE INDEX IF NOT EXISTS readings_station ON readings (station_id, snapshot_id)`,
+			`CREATE INDEX IF NOT EXISTS readings_last_reported ON readings (last_reported)`,
+			`CREATE VIEW IF NOT EXISTS station_status AS
+				SELECT r.* FROM readings r
+				JOIN (SELECT station_id, MAX(snapshot_id) AS snapshot_id FROM readings GROUP BY station_id) m
+				ON r.station_id = m.station_id AND r.snapshot_id = m.snapshot_id`,
+		)},
+		{3, "Convert legacy station table into readings", convertLegacy},
+	}
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Version returns the current schema version, which is zero for a new
+// database
+func (this *DB) Version() (uint, error) {
+	if _, err := this.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		version INTEGER NOT NULL PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied INTEGER NOT NULL
+	)`, SQL_MIGRATIONS_TABLENAME)); err != nil {
+		return 0, err
+	}
+	var version sql.NullInt64
+	if err := this.db.QueryRow(fmt.Sprintf("SELECT MAX(version) FROM %v", SQL_MIGRATIONS_TABLENAME)).Scan(&version); err != nil {
+		return 0, err
+	}
+	return uint(version.Int64), nil
+}
+
+// Migrate applies any migrations which have not yet been applied, each
+// within its own transaction
+func (this *DB) Migrate() error {
+	version, err := this.Version()
+	if err != nil {
+		return err
+	}
+	for _, migration := range MIGRATIONS {
+		if migration.Version <= version {
+			continue
+		}
+		if err := this.apply(migration); err != nil {
+			return fmt.Errorf("Migration %v (%v): %v", migration.Version, migration.Description, err)
+		}
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// apply runs a migration and records it as applied
+func (this *DB) apply(migration Migration) error {
+	tx, err := this.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := migration.Up(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %v (version, description, applied) VALUES (?,?,?)", SQL_MIGRATIONS_TABLENAME), migration.Version, migration.Description, time.Now().Unix()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// exec returns a migration which executes statements in order
+func exec(statements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// createTables creates the stations, snapshots and readings tables.
+// Databases written before migrations were added already have stations
+// and readings tables without the check constraints, so these are rebuilt
+// in the same way as the legacy table is converted: a new table is
+// created, the rows are copied and the old table is dropped before the new
+// table is renamed. The migration fails if any existing row does not meet
+// the constraints. The snapshots table is unchanged, so it is only created
+// when it does not exist
+func createTables(tx *sql.Tx) error {
+	if err := rebuild(tx, "stations", SQL_STATIONS_SCHEMA); err != nil {
+		return err
+	} else if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS snapshots (%v)", SQL_SNAPSHOTS_SCHEMA)); err != nil {
+		return err
+	} else if err := rebuild(tx, "readings", SQL_READINGS_SCHEMA); err != nil {
+		return err
+	}
+	return nil
+}
+
+// rebuild creates a table with a schema, copying the rows of any existing
+// table with the same name and columns into it
+func rebuild(tx *sql.Tx, table, schema string) error {
+	var name string
+	if err := tx.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&name); err == sql.ErrNoRows {
+		_, err := tx.Exec(fmt.Sprintf("CREATE TABLE %v (%v)", table, schema))
+		return err
+	} else if err != nil {
+		return err
+	}
+	return exec(
+		fmt.Sprintf("CREATE TABLE %v_new (%v)", table, schema),
+		fmt.Sprintf("INSERT INTO %v_new SELECT * FROM %v", table, table),
+		fmt.Sprintf("DROP TABLE %v", table),
+		fmt.Sprintf("ALTER TABLE %v_new RENAME TO %v", table, table),
+	)(tx)
+}
+
+// convertLegacy converts the table written by earlier versions of
+// chapter1/sql_writer.go, which stored the station_id as an integer and
+// last_reported as a formatted string, into a snapshot with a reading
+// for each station. The legacy table is then dropped
+func convertLegacy(tx *sql.Tx) error {
+	var name string
+	if err := tx.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name=?", SQL_LEGACY_TABLENAME).Scan(&name); err == sql.ErrNoRows {
+		// No legacy table so nothing to convert
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Read the legacy rows, converting the types
+	type legacy struct {
+		station_id                      string
+		last_reported                   int64
+		is_installed, is_renting        bool
+		docks_available, docks_disabled int64
+		bikes_available, bikes_disabled int64
+	}
+	rows, err := tx.Query(fmt.Sprintf(`SELECT CAST(id AS TEXT), last_reported, is_installed, is_renting,
+		docks_available, docks_disabled, bikes_available, bikes_disabled FROM %v`, SQL_LEGACY_TABLENAME))
+	if err != nil {
+		return err
+	}
+	stations := make([]legacy, 0)
+	var last_updated int64
+	for rows.Next() {
+		var row legacy
+		var last_reported string
+		var docks_available, docks_disabled, bikes_available, bikes_disabled sql.NullInt64
+		if err := rows.Scan(&row.station_id, &last_reported, &row.is_installed, &row.is_renting, &docks_available, &docks_disabled, &bikes_available, &bikes_disabled); err != nil {
+			rows.Close()
+			return err
+		}
+		if t, err := time.Parse(LEGACY_TIME_LAYOUT, last_reported); err != nil {
+			rows.Close()
+			return fmt.Errorf("Station %v: %v", row.station_id, err)
+		} else {
+			row.last_reported = t.Unix()
+		}
+		row.docks_available, row.docks_disabled = docks_available.Int64, docks_disabled.Int64
+		row.bikes_available, row.bikes_disabled = bikes_available.Int64, bikes_disabled.Int64
+		if row.last_reported > last_updated {
+			last_updated = row.last_reported
+		}
+		stations = append(stations, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Write the rows as a single snapshot, at the time of the most
+	// recent report. The legacy table did not store is_returning, so
+	// it is assumed to be the same as is_renting
+	if len(stations) > 0 {
+		var snapshot_id int64
+		if _, err := tx.Exec("INSERT OR IGNORE INTO snapshots (last_updated, fetched) VALUES (?,?)", last_updated, last_updated); err != nil {
+			return err
+		} else if err := tx.QueryRow("SELECT snapshot_id FROM snapshots WHERE last_updated=?", last_updated).Scan(&snapshot_id); err != nil {
+			return err
+		}
+		for _, row := range stations {
+			if _, err := tx.Exec("INSERT OR IGNORE INTO stations (station_id) VALUES (?)", row.station_id); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("INSERT OR REPLACE INTO readings VALUES (?,?,?,?,?,?,?,?,?,?)", snapshot_id, row.station_id, row.last_reported, row.is_installed, row.is_renting, row.is_renting, row.bikes_available, row.bikes_disabled, row.docks_available, row.docks_disabled); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Drop the legacy table
+	_, err = tx.Exec(fmt.Sprintf("DROP TABLE %v", SQL_LEGACY_TABLENAME))
+	return err
+}
@@ -0,0 +1,205 @@
+/*
+	Package stationdb stores bike share station status in an SQLite
+	database as an append-only time series. Each fetch of the station status
+	feed is stored as a snapshot, and a reading is stored for each station
+	only when its status has changed since the previous snapshot.
+*/
+package stationdb
+
+import (
+	"database/sql"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/MachineLearning/gbfs"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRUCTURES
+
+// DB is a station database
+type DB struct {
+	db   *sql.DB
+	last map[string]reading
+}
+
+// reading is the status of a station, used to detect changes
+type reading struct {
+	LastReported   int64
+	IsInstalled    uint
+	IsRenting      uint
+	IsReturning    uint
+	BikesAvailable uint
+	BikesDisabled  uint
+	DocksAvailable uint
+	DocksDisabled  uint
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Open opens or creates a station database at path, and migrates the
+// schema to the latest version
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	this := &DB{db: db}
+	if err := this.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return this, nil
+}
+
+// Close closes the database
+func (this *DB) Close() error {
+	return this.db.Close()
+}
+
+// SQL returns the underlying database handle, which can be used for
+// querying the stored data
+func (this *DB) SQL() *sql.DB {
+	return this.db
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// WRITE DATA
+
+// WriteStationInformation inserts or updates the static information
+// for each station
+func (this *DB) WriteStationInformation(info *gbfs.StationInformation) error {
+	tx, err := this.db.Begin()
+	if err != nil {
+		return err
+	}
+	if stmt, err := tx.Prepare(`INSERT INTO stations (station_id, name, lat, lon, capacity) VALUES (?,?,?,?,?)
+		ON CONFLICT(station_id) DO UPDATE SET name=excluded.name, lat=excluded.lat, lon=excluded.lon, capacity=excluded.capacity`); err != nil {
+		tx.Rollback()
+		return err
+	} else {
+		defer stmt.Close()
+		for _, station := range info.Data.Stations {
+			if _, err := stmt.Exec(station.StationId, station.Name, station.Lat, station.Lon, station.Capacity); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// WriteSnapshot stores the station status as a snapshot, with a reading
+// for each station which has changed since its previous reading. It
+// returns the number of readings written, which is zero if the snapshot
+// has already been stored
+func (this *DB) WriteSnapshot(status *gbfs.StationStatus) (int, error) {
+	// Read the most recent reading for each station
+	if this.last == nil {
+		if err := this.readLast(); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := this.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert the snapshot, or return if it already exists
+	var snapshot_id int64
+	if result, err := tx.Exec("INSERT OR IGNORE INTO snapshots (last_updated, fetched) VALUES (?,?)", status.LastUpdated.Unix(), time.Now().Unix()); err != nil {
+		tx.Rollback()
+		return 0, err
+	} else if rows, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		return 0, err
+	} else if rows == 0 {
+		return 0, tx.Rollback()
+	} else if snapshot_id, err = result.LastInsertId(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Insert stations and changed readings
+	written := make(map[string]reading)
+	if station_stmt, err := tx.Prepare("INSERT OR IGNORE INTO stations (station_id) VALUES (?)"); err != nil {
+		tx.Rollback()
+		return 0, err
+	} else if reading_stmt, err := tx.Prepare("INSERT INTO readings VALUES (?,?,?,?,?,?,?,?,?,?)"); err != nil {
+		station_stmt.Close()
+		tx.Rollback()
+		return 0, err
+	} else {
+		defer station_stmt.Close()
+		defer reading_stmt.Close()
+		for _, station := range status.Data.Stations {
+			r := readingForStation(station)
+			if last, exists := this.last[station.StationId]; exists && last == r {
+				continue
+			}
+			if _, err := station_stmt.Exec(station.StationId); err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+			if _, err := reading_stmt.Exec(snapshot_id, station.StationId, r.LastReported, r.IsInstalled, r.IsRenting, r.IsReturning, r.BikesAvailable, r.BikesDisabled, r.DocksAvailable, r.DocksDisabled); err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+			written[station.StationId] = r
+		}
+	}
+
+	// Commit and then update the most recent readings
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	for station_id, r := range written {
+		this.last[station_id] = r
+	}
+	return len(written), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// readLast reads the most recent reading for each station
+func (this *DB) readLast() error {
+	rows, err := this.db.Query(`SELECT r.station_id, r.last_reported, r.is_installed, r.is_renting, r.is_returning,
+		r.bikes_available, r.bikes_disabled, r.docks_available, r.docks_disabled
+		FROM readings r JOIN (SELECT station_id, MAX(snapshot_id) AS snapshot_id FROM readings GROUP BY station_id) m
+		ON r.station_id = m.station_id AND r.snapshot_id = m.snapshot_id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	last := make(map[string]reading)
+	for rows.Next() {
+		var station_id string
+		var r reading
+		if err := rows.Scan(&station_id, &r.LastReported, &r.IsInstalled, &r.IsRenting, &r.IsReturning, &r.BikesAvailable, &r.BikesDisabled, &r.DocksAvailable, &r.DocksDisabled); err != nil {
+			return err
+		}
+		last[station_id] = r
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	this.last = last
+	return nil
+}
+
+// readingForStation returns the reading for a station status
+func readingForStation(station *gbfs.Station) reading {
+	return reading{
+		LastReported:   station.LastReported.Unix(),
+		IsInstalled:    station.IsInstalled,
+		IsRenting:      station.IsRenting,
+		IsReturning:    station.IsReturning,
+		BikesAvailable: station.BikesAvailable,
+		BikesDisabled:  station.BikesDisabled,
+		DocksAvailable: station.DocksAvailable,
+		DocksDisabled:  station.DocksDisabled,
+	}
+}
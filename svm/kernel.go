@@ -0,0 +1,76 @@
+package svm
+
+import (
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Kernel determines the similarity between two samples
+type Kernel int
+
+// kernel is a kernel with its parameters
+type kernel struct {
+	Kernel Kernel
+	Gamma  float64
+	Degree uint
+	Coef0  float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// Radial basis function, exp(-gamma |a-b|^2)
+	KERNEL_RBF Kernel = iota
+	// Dot product, a.b
+	KERNEL_LINEAR
+	// Polynomial, (gamma a.b + coef0)^degree
+	KERNEL_POLYNOMIAL
+	// Sigmoid, tanh(gamma a.b + coef0)
+	KERNEL_SIGMOID
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// apply returns the kernel for samples a and b
+func (this kernel) apply(a, b []float64) float64 {
+	switch this.Kernel {
+	case KERNEL_LINEAR:
+		return dot(a, b)
+	case KERNEL_POLYNOMIAL:
+		return math.Pow(this.Gamma*dot(a, b)+this.Coef0, float64(this.Degree))
+	case KERNEL_SIGMOID:
+		return math.Tanh(this.Gamma*dot(a, b) + this.Coef0)
+	default:
+		var sum float64
+		for i := range a {
+			sum += (a[i] - b[i]) * (a[i] - b[i])
+		}
+		return math.Exp(-this.Gamma * sum)
+	}
+}
+
+// dot returns the dot product of a and b
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// Stringify
+func (this Kernel) String() string {
+	switch this {
+	case KERNEL_RBF:
+		return "KERNEL_RBF"
+	case KERNEL_LINEAR:
+		return "KERNEL_LINEAR"
+	case KERNEL_POLYNOMIAL:
+		return "KERNEL_POLYNOMIAL"
+	case KERNEL_SIGMOID:
+		return "KERNEL_SIGMOID"
+	default:
+		return "[?? Invalid Kernel value]"
+	}
+}
@@ -0,0 +1,293 @@
+package svm
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// pegasos is a linear machine trained by stochastic sub-gradient descent
+// on the primal problem. The bias is learnt as the weight of a constant
+// feature
+type pegasos struct {
+	c       float64
+	epochs  int
+	seed    int64
+	weights []float64
+}
+
+// smo is a kernel machine trained by sequential minimal optimisation on
+// the dual problem, choosing the maximal violating pair of samples in
+// each iteration
+type smo struct {
+	c          float64
+	kernel     kernel
+	tolerance  float64
+	iterations int
+
+	// support vectors with their coefficients, alpha times the label
+	vectors [][]float64
+	coef    []float64
+	rho     float64
+}
+
+// sigmoid maps a decision value f to a probability 1 / (1 + exp(A f + B))
+type sigmoid struct {
+	A, B float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PEGASOS
+
+func (this *pegasos) fit(x [][]float64, y []float64) error {
+	n, d := len(x), len(x[0])
+	lambda := 1 / (this.c * float64(n))
+	this.weights = make([]float64, d+1)
+	r := rand.New(rand.NewSource(this.seed))
+	t := 0
+	for epoch := 0; epoch < this.epochs; epoch++ {
+		for _, i := range r.Perm(n) {
+			t++
+			eta := 1 / (lambda * float64(t))
+			margin := y[i] * this.decision(x[i])
+			for j := range this.weights {
+				this.weights[j] *= 1 - eta*lambda
+			}
+			if margin < 1 {
+				for j, value := range x[i] {
+					this.weights[j] += eta * y[i] * value
+				}
+				this.weights[d] += eta * y[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (this *pegasos) decision(x []float64) float64 {
+	d := len(x)
+	return dot(this.weights[:d], x) + this.weights[d]
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// SMO
+
+func (this *smo) fit(x [][]float64, y []float64) error {
+	n := len(x)
+
+	// Cache the kernel matrix, with Q = y_i y_j K(x_i, x_j)
+	q := make([][]float64, n)
+	for i := range q {
+		q[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			q[i][j] = y[i] * y[j] * this.kernel.apply(x[i], x[j])
+			q[j][i] = q[i][j]
+		}
+	}
+
+	// The gradient of the dual objective starts at minus one
+	alpha, g := make([]float64, n), make([]float64, n)
+	for i := range g {
+		g[i] = -1
+	}
+	upper := func(t int) bool { return alpha[t] >= this.c }
+	lower := func(t int) bool { return alpha[t] <= 0 }
+
+	converged := false
+	for iter := 0; iter < this.iterations; iter++ {
+		// Select the maximal violating pair
+		i, j := -1, -1
+		gmax, gmin := math.Inf(-1), math.Inf(1)
+		for t := 0; t < n; t++ {
+			v := -y[t] * g[t]
+			if (y[t] > 0 && upper(t) == false) || (y[t] < 0 && lower(t) == false) {
+				if v >= gmax {
+					i, gmax = t, v
+				}
+			}
+			if (y[t] > 0 && lower(t) == false) || (y[t] < 0 && upper(t) == false) {
+				if v <= gmin {
+					j, gmin = t, v
+				}
+			}
+		}
+		if i < 0 || j < 0 || gmax-gmin < this.tolerance {
+			converged = true
+			break
+		}
+
+		// Solve for the pair analytically, keeping both within the box
+		oi, oj := alpha[i], alpha[j]
+		if y[i] != y[j] {
+			quad := math.Max(q[i][i]+q[j][j]+2*q[i][j], 1e-12)
+			delta := (-g[i] - g[j]) / quad
+			diff := alpha[i] - alpha[j]
+			alpha[i] += delta
+			alpha[j] += delta
+			if diff > 0 && alpha[j] < 0 {
+				alpha[j], alpha[i] = 0, diff
+			} else if diff <= 0 && alpha[i] < 0 {
+				alpha[i], alpha[j] = 0, -diff
+			}
+			if diff > 0 && alpha[i] > this.c {
+				alpha[i], alpha[j] = this.c, this.c-diff
+			} else if diff <= 0 && alpha[j] > this.c {
+				alpha[j], alpha[i] = this.c, this.c+diff
+			}
+		} else {
+			quad := math.Max(q[i][i]+q[j][j]-2*q[i][j], 1e-12)
+			delta := (g[i] - g[j]) / quad
+			sum := alpha[i] + alpha[j]
+			alpha[i] -= delta
+			alpha[j] += delta
+			if sum > this.c && alpha[i] > this.c {
+				alpha[i], alpha[j] = this.c, sum-this.c
+			} else if sum <= this.c && alpha[j] < 0 {
+				alpha[j], alpha[i] = 0, sum
+			}
+			if sum > this.c && alpha[j] > this.c {
+				alpha[j], alpha[i] = this.c, sum-this.c
+			} else if sum <= this.c && alpha[i] < 0 {
+				alpha[i], alpha[j] = 0, sum
+			}
+		}
+
+		// Update the gradient
+		di, dj := alpha[i]-oi, alpha[j]-oj
+		for t := 0; t < n; t++ {
+			g[t] += q[t][i]*di + q[t][j]*dj
+		}
+	}
+	if converged == false {
+		return fmt.Errorf("SMO did not converge in %v iterations", this.iterations)
+	}
+
+	// Compute the bias from the free samples, or the middle of the
+	// feasible range when there are none
+	var sum float64
+	free, ub, lb := 0, math.Inf(1), math.Inf(-1)
+	for t := 0; t < n; t++ {
+		yg := y[t] * g[t]
+		switch {
+		case upper(t) && y[t] < 0, lower(t) && y[t] > 0:
+			ub = math.Min(ub, yg)
+		case upper(t), lower(t):
+			lb = math.Max(lb, yg)
+		default:
+			free++
+			sum += yg
+		}
+	}
+	if free > 0 {
+		this.rho = sum / float64(free)
+	} else {
+		this.rho = (ub + lb) / 2
+	}
+
+	// Keep the support vectors
+	this.vectors, this.coef = nil, nil
+	for t := 0; t < n; t++ {
+		if alpha[t] > 0 {
+			this.vectors = append(this.vectors, x[t])
+			this.coef = append(this.coef, alpha[t]*y[t])
+		}
+	}
+	return nil
+}
+
+func (this *smo) decision(x []float64) float64 {
+	var sum float64
+	for i, v := range this.vectors {
+		sum += this.coef[i] * this.kernel.apply(v, x)
+	}
+	return sum - this.rho
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PLATT SCALING
+
+// fitSigmoid fits a sigmoid to decision values and labels of plus or
+// minus one by maximum likelihood using Newton's method, with the
+// regularised targets from Platt (1999) and the line search from Lin,
+// Lin and Weng (2007)
+func fitSigmoid(decision, y []float64) sigmoid {
+	var prior0, prior1 float64
+	for _, label := range y {
+		if label > 0 {
+			prior1++
+		} else {
+			prior0++
+		}
+	}
+	hi, lo := (prior1+1)/(prior1+2), 1/(prior0+2)
+	t := make([]float64, len(y))
+	for i, label := range y {
+		if label > 0 {
+			t[i] = hi
+		} else {
+			t[i] = lo
+		}
+	}
+	objective := func(a, b float64) float64 {
+		var f float64
+		for i, d := range decision {
+			if fApB := d*a + b; fApB >= 0 {
+				f += t[i]*fApB + math.Log1p(math.Exp(-fApB))
+			} else {
+				f += (t[i]-1)*fApB + math.Log1p(math.Exp(fApB))
+			}
+		}
+		return f
+	}
+
+	s := sigmoid{A: 0, B: math.Log((prior0 + 1) / (prior1 + 1))}
+	f := objective(s.A, s.B)
+	for iter := 0; iter < 100; iter++ {
+		// Gradient and Hessian
+		h11, h22, h21, g1, g2 := 1e-12, 1e-12, 0.0, 0.0, 0.0
+		for i, d := range decision {
+			var p, q float64
+			if fApB := d*s.A + s.B; fApB >= 0 {
+				p, q = math.Exp(-fApB)/(1+math.Exp(-fApB)), 1/(1+math.Exp(-fApB))
+			} else {
+				p, q = 1/(1+math.Exp(fApB)), math.Exp(fApB)/(1+math.Exp(fApB))
+			}
+			h11 += d * d * p * q
+			h22 += p * q
+			h21 += d * p * q
+			g1 += d * (t[i] - p)
+			g2 += t[i] - p
+		}
+		if math.Abs(g1) < 1e-5 && math.Abs(g2) < 1e-5 {
+			break
+		}
+
+		// Newton direction with a backtracking line search
+		det := h11*h22 - h21*h21
+		dA, dB := -(h22*g1-h21*g2)/det, -(-h21*g1+h11*g2)/det
+		gd := g1*dA + g2*dB
+		step := 1.0
+		for ; step >= 1e-10; step /= 2 {
+			a, b := s.A+step*dA, s.B+step*dB
+			if fnew := objective(a, b); fnew < f+1e-4*step*gd {
+				s.A, s.B, f = a, b, fnew
+				break
+			}
+		}
+		if step < 1e-10 {
+			break
+		}
+	}
+	return s
+}
+
+// probability returns the probability of the positive class
+func (this sigmoid) probability(decision float64) float64 {
+	if fApB := decision*this.A + this.B; fApB >= 0 {
+		return math.Exp(-fApB) / (1 + math.Exp(-fApB))
+	} else {
+		return 1 / (1 + math.Exp(fApB))
+	}
+}
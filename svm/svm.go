@@ -0,0 +1,391 @@
+/*
+	Package svm implements support vector machine classifiers. A linear
+	classifier is trained by stochastic sub-gradient descent (Pegasos),
+	which scales to large numbers of samples, and a kernel classifier is
+	trained by sequential minimal optimisation (SMO) with a linear, RBF,
+	polynomial or sigmoid kernel. More than two labels are classified one
+	label against the rest, and probabilities can be calibrated from the
+	decision values using Platt scaling.
+*/
+package svm
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Config is the configuration for a classifier
+type Config struct {
+	// C is the penalty for samples inside the margin, where a smaller
+	// value gives a softer margin
+	C float64
+
+	// Kernel for the kernel classifier, with the Gamma, Degree and Coef0
+	// parameters. Gamma defaults to one divided by the number of features
+	Kernel Kernel
+	Gamma  float64
+	Degree uint
+	Coef0  float64
+
+	// Tolerance is the stopping tolerance for SMO, and MaxIter is the
+	// maximum number of epochs for Pegasos or iterations for SMO
+	Tolerance float64
+	MaxIter   uint
+
+	// Probability calibrates probabilities by cross-validation when
+	// fitting, which is required for PredictProba
+	Probability bool
+
+	// Seed for the order of samples and the cross-validation folds
+	Seed int64
+}
+
+// Classifier is a support vector machine classifier, with one binary
+// machine for two labels or one machine for each label otherwise
+type Classifier struct {
+	config   Config
+	solver   func() machine
+	classes  []string
+	features int
+	machines []machine
+	platt    []sigmoid
+}
+
+// machine is a binary classifier, where the decision value is positive
+// for the positive class
+type machine interface {
+	fit(x [][]float64, y []float64) error
+	decision(x []float64) float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_C          = 1.0
+	DEFAULT_DEGREE     = 3
+	DEFAULT_TOLERANCE  = 1e-3
+	DEFAULT_EPOCHS     = 100
+	DEFAULT_ITERATIONS = 100000
+	CALIBRATION_FOLDS  = 3
+)
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewLinear returns a linear classifier trained with Pegasos. The kernel
+// parameters are ignored
+func NewLinear(config Config) *Classifier {
+	this := &Classifier{config: config}
+	this.solver = func() machine {
+		return &pegasos{c: this.c(), epochs: this.maxIter(DEFAULT_EPOCHS), seed: config.Seed}
+	}
+	return this
+}
+
+// NewKernel returns a kernel classifier trained with SMO
+func NewKernel(config Config) *Classifier {
+	this := &Classifier{config: config}
+	this.solver = func() machine {
+		return &smo{c: this.c(), kernel: this.kernel(), tolerance: this.tolerance(), iterations: this.maxIter(DEFAULT_ITERATIONS)}
+	}
+	return this
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Fit trains the classifier from the samples, with one row for each
+// sample, and their labels
+func (this *Classifier) Fit(x mat.Matrix, labels []string) error {
+	rows, cols := x.Dims()
+	if rows != len(labels) {
+		return fmt.Errorf("%v: Features and target samples mismatch", ErrBadParameter)
+	} else if rows == 0 || cols == 0 {
+		return ErrEmpty
+	} else if this.config.C < 0 || this.config.Gamma < 0 || this.config.Tolerance < 0 {
+		return fmt.Errorf("%v: Parameters cannot be negative", ErrBadParameter)
+	}
+	points := make([][]float64, rows)
+	for i := range points {
+		points[i] = mat.Row(nil, i, x)
+		for _, value := range points[i] {
+			if math.IsNaN(value) {
+				return fmt.Errorf("%v: Missing value in row %v", ErrBadParameter, i)
+			}
+		}
+	}
+	classes := unique(labels)
+	if len(classes) < 2 {
+		return fmt.Errorf("%v: Expected at least two labels", ErrBadParameter)
+	}
+	this.classes, this.features, this.machines, this.platt = classes, cols, nil, nil
+
+	// Train one machine for two labels, otherwise one for each label
+	positives := classes[1:]
+	if len(classes) > 2 {
+		positives = classes
+	}
+	for _, positive := range positives {
+		y := make([]float64, rows)
+		for i, label := range labels {
+			if label == positive {
+				y[i] = 1
+			} else {
+				y[i] = -1
+			}
+		}
+		m := this.solver()
+		if err := m.fit(points, y); err != nil {
+			this.machines = nil
+			return err
+		}
+		this.machines = append(this.machines, m)
+		if this.config.Probability {
+			if s, err := this.calibrate(points, y); err != nil {
+				this.machines = nil
+				return err
+			} else {
+				this.platt = append(this.platt, s)
+			}
+		}
+	}
+	return nil
+}
+
+// Classes returns the labels in sorted order, which are the columns
+// returned by PredictProba
+func (this *Classifier) Classes() []string {
+	return this.classes
+}
+
+// DecisionFunction returns the decision value of each machine for each
+// row. For two labels there is one column, which is positive for the
+// second label, otherwise there is one column for each label
+func (this *Classifier) DecisionFunction(x mat.Matrix) (*mat.Dense, error) {
+	if this.machines == nil {
+		return nil, ErrNotFitted
+	}
+	rows, cols := x.Dims()
+	if cols != this.features {
+		return nil, fmt.Errorf("%v: Expected %v features", ErrBadParameter, this.features)
+	}
+	result := mat.NewDense(rows, len(this.machines), nil)
+	row := make([]float64, cols)
+	for i := 0; i < rows; i++ {
+		mat.Row(row, i, x)
+		for j, m := range this.machines {
+			result.Set(i, j, m.decision(row))
+		}
+	}
+	return result, nil
+}
+
+// Predict returns the label with the largest decision value for each row
+func (this *Classifier) Predict(x mat.Matrix) ([]string, error) {
+	decision, err := this.DecisionFunction(x)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := decision.Dims()
+	predicted := make([]string, rows)
+	for i := range predicted {
+		if len(this.machines) == 1 {
+			if decision.At(i, 0) > 0 {
+				predicted[i] = this.classes[1]
+			} else {
+				predicted[i] = this.classes[0]
+			}
+		} else {
+			predicted[i] = this.classes[argmax(decision.RawRowView(i))]
+		}
+	}
+	return predicted, nil
+}
+
+// PredictProba returns the calibrated probability of each label for each
+// row, where the probabilities for a row sum to one. The columns are in
+// the order returned by Classes. It requires Probability to be set in the
+// configuration
+func (this *Classifier) PredictProba(x mat.Matrix) (*mat.Dense, error) {
+	if this.machines != nil && this.platt == nil {
+		return nil, fmt.Errorf("%v: Probability is not enabled", ErrBadParameter)
+	}
+	decision, err := this.DecisionFunction(x)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := decision.Dims()
+	proba := mat.NewDense(rows, len(this.classes), nil)
+	for i := 0; i < rows; i++ {
+		if len(this.machines) == 1 {
+			p := this.platt[0].probability(decision.At(i, 0))
+			proba.Set(i, 0, 1-p)
+			proba.Set(i, 1, p)
+			continue
+		}
+		var sum float64
+		for j := range this.machines {
+			proba.Set(i, j, this.platt[j].probability(decision.At(i, j)))
+			sum += proba.At(i, j)
+		}
+		for j := range this.machines {
+			proba.Set(i, j, proba.At(i, j)/sum)
+		}
+	}
+	return proba, nil
+}
+
+// SupportVectors returns the number of support vectors for each machine,
+// or nil for a linear classifier trained with Pegasos
+func (this *Classifier) SupportVectors() []int {
+	var result []int
+	for _, m := range this.machines {
+		if s, ok := m.(*smo); ok {
+			result = append(result, len(s.coef))
+		}
+	}
+	return result
+}
+
+// Stringify
+func (this *Classifier) String() string {
+	if len(this.machines) > 0 {
+		if _, ok := this.machines[0].(*pegasos); ok {
+			return fmt.Sprintf("svm{ solver=pegasos c=%v epochs=%v classes=%v probability=%v }", this.c(), this.maxIter(DEFAULT_EPOCHS), len(this.classes), this.config.Probability)
+		}
+	}
+	k := this.kernel()
+	return fmt.Sprintf("svm{ solver=smo c=%v kernel=%v gamma=%v degree=%v coef0=%v classes=%v support_vectors=%v probability=%v }", this.c(), k.Kernel, k.Gamma, k.Degree, k.Coef0, len(this.classes), this.SupportVectors(), this.config.Probability)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// calibrate fits a sigmoid to the decision values for samples which were
+// held out of training, using cross-validation folds
+func (this *Classifier) calibrate(x [][]float64, y []float64) (sigmoid, error) {
+	n := len(x)
+	decision := make([]float64, n)
+	fold := rand.New(rand.NewSource(this.config.Seed)).Perm(n)
+	for k := 0; k < CALIBRATION_FOLDS; k++ {
+		var train_x, test_x [][]float64
+		var train_y []float64
+		var test []int
+		for i := range x {
+			if fold[i]%CALIBRATION_FOLDS == k {
+				test = append(test, i)
+				test_x = append(test_x, x[i])
+			} else {
+				train_x = append(train_x, x[i])
+				train_y = append(train_y, y[i])
+			}
+		}
+		// Use the decision values from all samples when a fold does not
+		// have both classes
+		m := this.solver()
+		if len(test) == 0 || len(unique(signs(train_y))) < 2 {
+			return this.sigmoid(x, y)
+		} else if err := m.fit(train_x, train_y); err != nil {
+			return sigmoid{}, err
+		}
+		for j, i := range test {
+			decision[i] = m.decision(test_x[j])
+		}
+	}
+	return fitSigmoid(decision, y), nil
+}
+
+// sigmoid fits a sigmoid to the decision values of the last machine
+func (this *Classifier) sigmoid(x [][]float64, y []float64) (sigmoid, error) {
+	m := this.machines[len(this.machines)-1]
+	decision := make([]float64, len(x))
+	for i := range x {
+		decision[i] = m.decision(x[i])
+	}
+	return fitSigmoid(decision, y), nil
+}
+
+func (this *Classifier) c() float64 {
+	if this.config.C == 0 {
+		return DEFAULT_C
+	}
+	return this.config.C
+}
+
+func (this *Classifier) tolerance() float64 {
+	if this.config.Tolerance == 0 {
+		return DEFAULT_TOLERANCE
+	}
+	return this.config.Tolerance
+}
+
+func (this *Classifier) maxIter(value int) int {
+	if this.config.MaxIter == 0 {
+		return value
+	}
+	return int(this.config.MaxIter)
+}
+
+// kernel returns the kernel with default parameters
+func (this *Classifier) kernel() kernel {
+	k := kernel{Kernel: this.config.Kernel, Gamma: this.config.Gamma, Degree: this.config.Degree, Coef0: this.config.Coef0}
+	if k.Gamma == 0 && this.features > 0 {
+		k.Gamma = 1 / float64(this.features)
+	}
+	if k.Degree == 0 {
+		k.Degree = DEFAULT_DEGREE
+	}
+	return k
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// unique returns the unique labels in sorted order
+func unique(labels []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, label := range labels {
+		if seen[label] == false {
+			seen[label] = true
+			result = append(result, label)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// signs returns the sign of each value as a string
+func signs(values []float64) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		if value > 0 {
+			result[i] = "+"
+		} else {
+			result[i] = "-"
+		}
+	}
+	return result
+}
+
+// argmax returns the index of the largest value, or the first index when
+// values are equal
+func argmax(values []float64) int {
+	best := 0
+	for i, value := range values {
+		if value > values[best] {
+			best = i
+		}
+	}
+	return best
+}
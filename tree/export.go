@@ -0,0 +1,93 @@
+package tree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// EXPORT
+
+// Text returns the rules of the tree as indented text, with the
+// prediction and number of samples for each leaf
+func (this *tree) Text() string {
+	buf := new(bytes.Buffer)
+	if this.root == nil {
+		return ""
+	}
+	var visit func(n *node, depth int)
+	visit = func(n *node, depth int) {
+		indent := strings.Repeat("|   ", depth) + "|--- "
+		if n.leaf() {
+			fmt.Fprintf(buf, "%v%v (samples=%v)\n", indent, this.label(n.value), n.samples)
+			return
+		}
+		left, right := this.condition(n)
+		fmt.Fprintln(buf, indent+left)
+		visit(n.left, depth+1)
+		fmt.Fprintln(buf, indent+right)
+		visit(n.right, depth+1)
+	}
+	visit(this.root, 0)
+	return buf.String()
+}
+
+// WriteDOT writes the tree in the Graphviz DOT format, which can be
+// rendered with the dot command
+func (this *tree) WriteDOT(w io.Writer) error {
+	if this.root == nil {
+		return ErrNotFitted
+	}
+	buf := new(bytes.Buffer)
+	fmt.Fprintln(buf, "digraph Tree {")
+	fmt.Fprintln(buf, "  node [shape=box, fontname=\"helvetica\"];")
+	fmt.Fprintln(buf, "  edge [fontname=\"helvetica\"];")
+	id := 0
+	var visit func(n *node) int
+	visit = func(n *node) int {
+		this_id := id
+		id++
+		label := fmt.Sprintf("%v = %.3f\\nsamples = %v\\nprediction = %v", strings.ToLower(strings.TrimPrefix(this.config.Criterion.String(), "CRITERION_")), n.impurity, n.samples, this.label(n.value))
+		if n.leaf() == false {
+			left, _ := this.condition(n)
+			label = left + "\\n" + label
+		}
+		fmt.Fprintf(buf, "  %v [label=\"%v\"];\n", this_id, strings.Replace(label, "\"", "\\\"", -1))
+		if n.leaf() == false {
+			left_id := visit(n.left)
+			fmt.Fprintf(buf, "  %v -> %v [label=\"true\"];\n", this_id, left_id)
+			right_id := visit(n.right)
+			fmt.Fprintf(buf, "  %v -> %v [label=\"false\"];\n", this_id, right_id)
+		}
+		return this_id
+	}
+	visit(this.root)
+	fmt.Fprintln(buf, "}")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// condition returns the conditions for the left and right children of a
+// node, including which side missing values go to
+func (this *tree) condition(n *node) (string, string) {
+	f := this.features[n.feature]
+	var left, right string
+	if n.category < 0 {
+		left = fmt.Sprintf("%v <= %.4g", f.name, n.threshold)
+		right = fmt.Sprintf("%v > %.4g", f.name, n.threshold)
+	} else {
+		left = fmt.Sprintf("%v == %v", f.name, f.categories[n.category])
+		right = fmt.Sprintf("%v != %v", f.name, f.categories[n.category])
+	}
+	if n.missing > 0 && n.missingLeft {
+		left += " or missing"
+	} else if n.missing > 0 {
+		right += " or missing"
+	}
+	return left, right
+}
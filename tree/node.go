@@ -0,0 +1,343 @@
+package tree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// feature is a column used to grow the tree. Categorical features have
+// the categories seen when fitting in sorted order
+type feature struct {
+	name       string
+	numeric    bool
+	categories []string
+}
+
+// node is a node in the tree. Samples go left when the feature value is
+// less than or equal to the threshold for a numeric feature, or equal to
+// the category for a categorical feature. Leaf nodes have no children
+type node struct {
+	feature   int
+	threshold float64
+	category  int
+
+	// missing is the number of samples with a missing feature value when
+	// the node was split, and these go left when missingLeft is true
+	missing     int
+	missingLeft bool
+
+	left, right *node
+
+	// samples is the number of samples reaching the node, impurity is
+	// their impurity, and value is the proportion of each class for a
+	// classifier or the mean value for a regressor
+	samples  int
+	impurity float64
+	value    []float64
+}
+
+// builder grows a tree from the feature values x and targets y, which
+// are class indexes when there are classes and values otherwise. The
+// random source chooses the candidate features for each split
+type builder struct {
+	*tree
+	x       [][]float64
+	y       []float64
+	classes int
+	rand    *rand.Rand
+}
+
+// stats are the statistics of the targets for a set of samples
+type stats struct {
+	n          float64
+	counts     []float64
+	sum, sumsq float64
+	criterion  Criterion
+	regression bool
+}
+
+// split is a candidate split for a node
+type split struct {
+	feature   int
+	threshold float64
+	category  int
+	gain      float64
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GROW
+
+// grow returns a node for the rows, split recursively until the node is
+// pure or cannot be split further
+func (this *builder) grow(rows []int, depth int) *node {
+	s := this.stats(rows)
+	n := &node{feature: -1, samples: len(rows), impurity: s.impurity(), value: s.value()}
+	if n.impurity <= 0 || len(rows) < this.minSamplesSplit() || len(rows) < 2*this.minSamplesLeaf() {
+		return n
+	} else if this.config.MaxDepth > 0 && depth >= int(this.config.MaxDepth) {
+		return n
+	}
+
+	best := split{feature: -1}
+	for _, j := range this.candidates() {
+		if candidate, ok := this.best(rows, j); ok && candidate.gain > best.gain {
+			best = candidate
+		}
+	}
+	if best.feature < 0 {
+		return n
+	}
+
+	// Partition the rows, sending missing values to the larger side
+	n.feature, n.threshold, n.category = best.feature, best.threshold, best.category
+	left, right, missing := make([]int, 0, len(rows)), make([]int, 0, len(rows)), make([]int, 0)
+	for _, i := range rows {
+		switch value := this.x[i][n.feature]; {
+		case math.IsNaN(value):
+			missing = append(missing, i)
+		case n.goesLeft(value):
+			left = append(left, i)
+		default:
+			right = append(right, i)
+		}
+	}
+	n.missing, n.missingLeft = len(missing), len(left) >= len(right)
+	if n.missingLeft {
+		left = append(left, missing...)
+	} else {
+		right = append(right, missing...)
+	}
+	if len(left) < this.minSamplesLeaf() || len(right) < this.minSamplesLeaf() {
+		return n.collapse()
+	}
+	n.left = this.grow(left, depth+1)
+	n.right = this.grow(right, depth+1)
+	return n
+}
+
+// best returns the split on feature j with the largest decrease in
+// impurity for the samples with a value, scaled by the proportion of
+// samples with a value
+func (this *builder) best(rows []int, j int) (split, bool) {
+	present := make([]int, 0, len(rows))
+	for _, i := range rows {
+		if math.IsNaN(this.x[i][j]) == false {
+			present = append(present, i)
+		}
+	}
+	if len(present) < 2 {
+		return split{}, false
+	}
+	all := this.stats(present)
+	scale := float64(len(present)) / float64(len(rows))
+	leaf := this.minSamplesLeaf()
+	best, found := split{feature: j, category: -1}, false
+
+	if this.features[j].numeric {
+		// Sweep the threshold across the sorted values
+		sort.Slice(present, func(a, b int) bool {
+			return this.x[present[a]][j] < this.x[present[b]][j]
+		})
+		left, right := this.stats(nil), this.stats(present)
+		for k := 0; k < len(present)-1; k++ {
+			left.add(this.y[present[k]], 1)
+			right.add(this.y[present[k]], -1)
+			value, next := this.x[present[k]][j], this.x[present[k+1]][j]
+			if value == next || k+1 < leaf || len(present)-k-1 < leaf {
+				continue
+			}
+			if gain := scale * all.gain(left, right); gain > best.gain {
+				best.threshold, best.gain, found = value+(next-value)/2, gain, true
+			}
+		}
+	} else {
+		// Try each category against all others
+		categories := make(map[int]*stats)
+		for _, i := range present {
+			c := int(this.x[i][j])
+			if categories[c] == nil {
+				categories[c] = this.stats(nil)
+			}
+			categories[c].add(this.y[i], 1)
+		}
+		for c := range this.features[j].categories {
+			left := categories[c]
+			if left == nil || int(left.n) < leaf || len(present)-int(left.n) < leaf {
+				continue
+			}
+			right := all.minus(left)
+			if gain := scale * all.gain(left, right); gain > best.gain {
+				best.category, best.gain, found = c, gain, true
+			}
+		}
+	}
+	return best, found
+}
+
+// candidates returns the features to consider for a split, which are
+// chosen at random when MaxFeatures is less than the number of features
+func (this *builder) candidates() []int {
+	n := len(this.features)
+	if this.config.MaxFeatures == 0 || int(this.config.MaxFeatures) >= n {
+		return indexes(n)
+	}
+	return this.rand.Perm(n)[:this.config.MaxFeatures]
+}
+
+// stats returns the statistics for the rows
+func (this *builder) stats(rows []int) *stats {
+	s := &stats{criterion: this.config.Criterion, regression: this.classes == 0}
+	if s.regression == false {
+		s.counts = make([]float64, this.classes)
+	}
+	for _, i := range rows {
+		s.add(this.y[i], 1)
+	}
+	return s
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STATS
+
+// add adds a target with weight one, or removes it with weight minus one
+func (this *stats) add(y, weight float64) {
+	this.n += weight
+	if this.regression {
+		this.sum += weight * y
+		this.sumsq += weight * y * y
+	} else {
+		this.counts[int(y)] += weight
+	}
+}
+
+// minus returns the statistics with the other statistics removed
+func (this *stats) minus(other *stats) *stats {
+	s := &stats{n: this.n - other.n, sum: this.sum - other.sum, sumsq: this.sumsq - other.sumsq, criterion: this.criterion, regression: this.regression}
+	if this.regression == false {
+		s.counts = make([]float64, len(this.counts))
+		for i := range s.counts {
+			s.counts[i] = this.counts[i] - other.counts[i]
+		}
+	}
+	return s
+}
+
+// impurity returns the impurity of the samples for the criterion
+func (this *stats) impurity() float64 {
+	if this.n <= 0 {
+		return 0
+	}
+	switch this.criterion {
+	case CRITERION_GINI:
+		impurity := 1.0
+		for _, count := range this.counts {
+			impurity -= (count / this.n) * (count / this.n)
+		}
+		return impurity
+	case CRITERION_ENTROPY:
+		var impurity float64
+		for _, count := range this.counts {
+			if count > 0 {
+				impurity -= (count / this.n) * math.Log2(count/this.n)
+			}
+		}
+		return impurity
+	default:
+		mean := this.sum / this.n
+		return math.Max(0, this.sumsq/this.n-mean*mean)
+	}
+}
+
+// gain returns the decrease in impurity when the samples are split into
+// left and right
+func (this *stats) gain(left, right *stats) float64 {
+	return this.impurity() - (left.n*left.impurity()+right.n*right.impurity())/this.n
+}
+
+// value returns the proportion of each class or the mean value
+func (this *stats) value() []float64 {
+	if this.regression {
+		return []float64{this.sum / this.n}
+	}
+	value := make([]float64, len(this.counts))
+	for i, count := range this.counts {
+		value[i] = count / this.n
+	}
+	return value
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// NODE
+
+// leaf returns true if the node has no children
+func (this *node) leaf() bool {
+	return this.left == nil
+}
+
+// find returns the leaf reached by a row of feature values
+func (this *node) find(row []float64) *node {
+	for this.leaf() == false {
+		if this.goesLeft(row[this.feature]) {
+			this = this.left
+		} else {
+			this = this.right
+		}
+	}
+	return this
+}
+
+// goesLeft returns true if a feature value goes to the left child. Unseen
+// categories go right
+func (this *node) goesLeft(value float64) bool {
+	switch {
+	case math.IsNaN(value):
+		return this.missingLeft
+	case this.category < 0:
+		return value <= this.threshold
+	default:
+		return int(value) == this.category
+	}
+}
+
+// collapse removes the children of the node, making it a leaf
+func (this *node) collapse() *node {
+	this.feature, this.left, this.right = -1, nil, nil
+	this.missing, this.missingLeft = 0, false
+	return this
+}
+
+// leaves returns the number of leaves below and including the node
+func (this *node) leaves() int {
+	if this.leaf() {
+		return 1
+	}
+	return this.left.leaves() + this.right.leaves()
+}
+
+// walk calls a function for the node and every node below it in
+// depth-first order
+func (this *node) walk(fn func(n *node, depth int)) {
+	var visit func(n *node, depth int)
+	visit = func(n *node, depth int) {
+		fn(n, depth)
+		if n.leaf() == false {
+			visit(n.left, depth+1)
+			visit(n.right, depth+1)
+		}
+	}
+	visit(this, 0)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// indexes returns the indexes from zero to n-1
+func indexes(n int) []int {
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	return idx
+}
@@ -0,0 +1,85 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// COST-COMPLEXITY PRUNING
+
+// Prune collapses the subtrees which do not decrease the weighted impurity
+// of their leaves by more than alpha for each additional leaf, weakest
+// subtree first. The impurity of a node is weighted by the proportion of
+// samples which reach it
+func (this *tree) Prune(alpha float64) error {
+	if this.root == nil {
+		return ErrNotFitted
+	} else if alpha < 0 {
+		return fmt.Errorf("%v: Alpha cannot be negative", ErrBadParameter)
+	}
+	for {
+		if g, weakest := this.weakest(this.root); weakest == nil || g > alpha {
+			return nil
+		} else {
+			weakest.collapse()
+		}
+	}
+}
+
+// Alphas returns the effective alphas at which subtrees are collapsed, in
+// increasing order. Pruning with an alpha between two consecutive values
+// results in the same tree, so these are the alphas worth comparing when
+// choosing how much to prune
+func (this *tree) Alphas() []float64 {
+	alphas := make([]float64, 0)
+	if this.root == nil {
+		return alphas
+	}
+	root := this.root.clone()
+	for {
+		if g, weakest := this.weakest(root); weakest == nil {
+			return alphas
+		} else {
+			if len(alphas) == 0 || g > alphas[len(alphas)-1] {
+				alphas = append(alphas, g)
+			}
+			weakest.collapse()
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// weakest returns the internal node with the smallest increase in
+// weighted impurity per leaf removed when it is collapsed, or nil if the
+// root is a leaf
+func (this *tree) weakest(root *node) (float64, *node) {
+	min, weakest := math.Inf(1), (*node)(nil)
+	var visit func(n *node) (float64, int)
+	visit = func(n *node) (float64, int) {
+		risk := n.impurity * float64(n.samples) / float64(this.samples)
+		if n.leaf() {
+			return risk, 1
+		}
+		lrisk, lleaves := visit(n.left)
+		rrisk, rleaves := visit(n.right)
+		leaves := lleaves + rleaves
+		if g := (risk - lrisk - rrisk) / float64(leaves-1); g < min {
+			min, weakest = g, n
+		}
+		return lrisk + rrisk, leaves
+	}
+	visit(root)
+	return min, weakest
+}
+
+// clone returns a copy of the node and every node below it
+func (this *node) clone() *node {
+	that := *this
+	if this.leaf() == false {
+		that.left, that.right = this.left.clone(), this.right.clone()
+	}
+	return &that
+}
@@ -0,0 +1,447 @@
+/*
+	Package tree implements classification and regression trees (CART),
+	which predict a sample by following binary splits on one feature at a
+	time from the root of the tree to a leaf. Features can be numeric or
+	categorical and can have missing values. Trees can be pruned by
+	minimal cost-complexity pruning and exported as text or in the
+	Graphviz DOT format.
+*/
+package tree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/djthorpe/MachineLearning/util"
+	"gonum.org/v1/gonum/mat"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Criterion determines how the impurity of a node is measured
+type Criterion int
+
+// Config is the configuration for a classifier or regressor
+type Config struct {
+	// Criterion used to choose splits
+	Criterion Criterion
+
+	// MaxDepth is the maximum depth of the tree, or zero for no limit
+	MaxDepth uint
+
+	// MinSamplesSplit is the minimum number of samples in a node for it
+	// to be split, and MinSamplesLeaf is the minimum number of samples in
+	// each leaf
+	MinSamplesSplit uint
+	MinSamplesLeaf  uint
+
+	// Alpha is the complexity parameter for cost-complexity pruning
+	// after the tree is grown, or zero for no pruning
+	Alpha float64
+
+	// MaxFeatures is the number of features chosen at random from the
+	// seed as candidates for each split, or zero for all features
+	MaxFeatures uint
+	Seed        int64
+}
+
+// Classifier predicts the label of a sample from the proportion of each
+// label in the leaf the sample reaches
+type Classifier struct {
+	tree
+	classes []string
+}
+
+// Regressor predicts the value of a sample from the mean value in the
+// leaf the sample reaches
+type Regressor struct {
+	tree
+}
+
+// tree is the tree common to classifiers and regressors
+type tree struct {
+	config   Config
+	features []*feature
+	root     *node
+
+	// samples is the number of samples used to grow the tree, and label
+	// returns the prediction of a leaf for export
+	samples int
+	label   func(value []float64) string
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	// Gini impurity for a classifier and mean squared error for a
+	// regressor
+	CRITERION_DEFAULT Criterion = iota
+	// Probability of misclassifying a sample labelled at random
+	CRITERION_GINI
+	// Information entropy of the labels, in bits
+	CRITERION_ENTROPY
+	// Variance of the values
+	CRITERION_MSE
+)
+
+const (
+	DEFAULT_MIN_SAMPLES_SPLIT = 2
+	DEFAULT_MIN_SAMPLES_LEAF  = 1
+)
+
+var (
+	ErrEmpty        = fmt.Errorf("No samples")
+	ErrBadParameter = fmt.Errorf("Bad parameter")
+	ErrNotFitted    = fmt.Errorf("Model has not been fitted")
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewClassifier returns a classifier with the configuration
+func NewClassifier(config Config) *Classifier {
+	if config.Criterion == CRITERION_DEFAULT {
+		config.Criterion = CRITERION_GINI
+	}
+	return &Classifier{tree: tree{config: config}}
+}
+
+// NewRegressor returns a regressor with the configuration
+func NewRegressor(config Config) *Regressor {
+	if config.Criterion == CRITERION_DEFAULT {
+		config.Criterion = CRITERION_MSE
+	}
+	return &Regressor{tree: tree{config: config}}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CLASSIFIER
+
+// Fit grows the tree from the feature columns of the table to predict
+// the labels in the target column. Numeric columns are split on a
+// threshold and other columns on a single category. Rows with a missing
+// label are ignored
+func (this *Classifier) Fit(table *util.Table, features []string, target string) error {
+	if this.config.Criterion != CRITERION_GINI && this.config.Criterion != CRITERION_ENTROPY {
+		return fmt.Errorf("%v: Invalid criterion for classifier: %v", ErrBadParameter, this.config.Criterion)
+	}
+	labels, err := table.StringColumn(target, "")
+	if err != nil {
+		return err
+	}
+	rows := make([]int, 0, len(labels))
+	for i, label := range labels {
+		if label != "" {
+			rows = append(rows, i)
+		}
+	}
+	this.classes = unique(labels)
+	class := make(map[string]int, len(this.classes))
+	for i, label := range this.classes {
+		class[label] = i
+	}
+	y := make([]float64, len(labels))
+	for i, label := range labels {
+		y[i] = float64(class[label])
+	}
+	this.label = func(value []float64) string {
+		return this.classes[argmax(value)]
+	}
+	return this.fit(table, features, rows, y, len(this.classes))
+}
+
+// Classes returns the labels in sorted order, which are the columns
+// returned by PredictProba
+func (this *Classifier) Classes() []string {
+	return this.classes
+}
+
+// Predict returns the most common label in the leaf reached by each row
+func (this *Classifier) Predict(table *util.Table) ([]string, error) {
+	proba, err := this.PredictProba(table)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := proba.Dims()
+	predicted := make([]string, rows)
+	for i := range predicted {
+		predicted[i] = this.classes[argmax(proba.RawRowView(i))]
+	}
+	return predicted, nil
+}
+
+// PredictProba returns the proportion of each label in the leaf reached
+// by each row. The columns are in the order returned by Classes
+func (this *Classifier) PredictProba(table *util.Table) (*mat.Dense, error) {
+	leaves, err := this.predict(table)
+	if err != nil {
+		return nil, err
+	}
+	proba := mat.NewDense(len(leaves), len(this.classes), nil)
+	for i, leaf := range leaves {
+		proba.SetRow(i, leaf.value)
+	}
+	return proba, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// REGRESSOR
+
+// Fit grows the tree from the feature columns of the table to predict
+// the values in the target column. Numeric columns are split on a
+// threshold and other columns on a single category. Rows with a missing
+// value are ignored
+func (this *Regressor) Fit(table *util.Table, features []string, target string) error {
+	y, err := table.FloatColumn(target, math.NaN())
+	if err != nil {
+		return err
+	}
+	return this.FitValues(table, features, y)
+}
+
+// FitValues grows the tree from the feature columns of the table to
+// predict the values y, with one value for each row. Rows with a NaN
+// value are ignored
+func (this *Regressor) FitValues(table *util.Table, features []string, y []float64) error {
+	if this.config.Criterion != CRITERION_MSE {
+		return fmt.Errorf("%v: Invalid criterion for regressor: %v", ErrBadParameter, this.config.Criterion)
+	} else if len(y) != len(table.Rows) {
+		return fmt.Errorf("%v: Features and target samples mismatch", ErrBadParameter)
+	}
+	rows := make([]int, 0, len(y))
+	for i, value := range y {
+		if math.IsNaN(value) == false {
+			rows = append(rows, i)
+		}
+	}
+	this.label = func(value []float64) string {
+		return fmt.Sprintf("%.4g", value[0])
+	}
+	return this.fit(table, features, rows, y, 0)
+}
+
+// Predict returns the mean value in the leaf reached by each row
+func (this *Regressor) Predict(table *util.Table) ([]float64, error) {
+	leaves, err := this.predict(table)
+	if err != nil {
+		return nil, err
+	}
+	predicted := make([]float64, len(leaves))
+	for i, leaf := range leaves {
+		predicted[i] = leaf.value[0]
+	}
+	return predicted, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// TREE
+
+// Features returns the names of the features used to grow the tree
+func (this *tree) Features() []string {
+	names := make([]string, len(this.features))
+	for i, f := range this.features {
+		names[i] = f.name
+	}
+	return names
+}
+
+// Importances returns the importance of each feature, which is the total
+// decrease in impurity from the splits on that feature weighted by the
+// number of samples, normalised so that the importances sum to one
+func (this *tree) Importances() []float64 {
+	importances := make([]float64, len(this.features))
+	if this.root == nil {
+		return importances
+	}
+	var total float64
+	this.root.walk(func(n *node, depth int) {
+		if n.leaf() == false {
+			decrease := float64(n.samples)*n.impurity - float64(n.left.samples)*n.left.impurity - float64(n.right.samples)*n.right.impurity
+			importances[n.feature] += decrease
+			total += decrease
+		}
+	})
+	if total > 0 {
+		for i := range importances {
+			importances[i] /= total
+		}
+	}
+	return importances
+}
+
+// Depth returns the depth of the tree, which is zero when the tree is a
+// single leaf
+func (this *tree) Depth() int {
+	max := 0
+	if this.root != nil {
+		this.root.walk(func(n *node, depth int) {
+			if depth > max {
+				max = depth
+			}
+		})
+	}
+	return max
+}
+
+// Leaves returns the number of leaves in the tree
+func (this *tree) Leaves() int {
+	if this.root == nil {
+		return 0
+	}
+	return this.root.leaves()
+}
+
+// Stringify
+func (this *tree) String() string {
+	return fmt.Sprintf("tree{ criterion=%v max_depth=%v min_samples_split=%v min_samples_leaf=%v max_features=%v alpha=%v depth=%v leaves=%v }", this.config.Criterion, this.config.MaxDepth, this.minSamplesSplit(), this.minSamplesLeaf(), this.config.MaxFeatures, this.config.Alpha, this.Depth(), this.Leaves())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// fit reads the features and grows and prunes the tree from the rows
+func (this *tree) fit(table *util.Table, features []string, rows []int, y []float64, classes int) error {
+	if len(features) == 0 {
+		return fmt.Errorf("%v: Expected at least one feature", ErrBadParameter)
+	} else if len(rows) == 0 {
+		return ErrEmpty
+	} else if this.config.Alpha < 0 {
+		return fmt.Errorf("%v: Alpha cannot be negative", ErrBadParameter)
+	}
+	this.features = make([]*feature, len(features))
+	numeric := make(map[string]bool)
+	for _, column := range table.NumericColumns() {
+		numeric[column] = true
+	}
+	for i, name := range features {
+		this.features[i] = &feature{name: name, numeric: numeric[name]}
+	}
+	x, err := this.matrix(table, true)
+	if err != nil {
+		return err
+	}
+	b := &builder{tree: this, x: x, y: y, classes: classes, rand: rand.New(rand.NewSource(this.config.Seed))}
+	this.root = b.grow(rows, 0)
+	this.samples = len(rows)
+	if this.config.Alpha > 0 {
+		this.Prune(this.config.Alpha)
+	}
+	return nil
+}
+
+// predict returns the leaf reached by each row in the table
+func (this *tree) predict(table *util.Table) ([]*node, error) {
+	if this.root == nil {
+		return nil, ErrNotFitted
+	}
+	x, err := this.matrix(table, false)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([]*node, len(x))
+	for i, row := range x {
+		leaves[i] = this.root.find(row)
+	}
+	return leaves, nil
+}
+
+// matrix returns the feature values for each row of the table, with
+// missing values as NaN and categories as their index. When fitting, the
+// categories are collected from the table, otherwise categories which
+// were not seen when fitting are -1
+func (this *tree) matrix(table *util.Table, fitting bool) ([][]float64, error) {
+	x := make([][]float64, len(table.Rows))
+	for i := range x {
+		x[i] = make([]float64, len(this.features))
+	}
+	for j, f := range this.features {
+		if f.numeric {
+			if values, err := table.FloatColumn(f.name, math.NaN()); err != nil {
+				return nil, fmt.Errorf("%v: %v", f.name, err)
+			} else {
+				for i, value := range values {
+					x[i][j] = value
+				}
+			}
+		} else if values, err := table.StringColumn(f.name, ""); err != nil {
+			return nil, fmt.Errorf("%v: %v", f.name, err)
+		} else {
+			if fitting {
+				f.categories = unique(values)
+			}
+			index := make(map[string]int, len(f.categories))
+			for k, category := range f.categories {
+				index[category] = k
+			}
+			for i, value := range values {
+				if value == "" {
+					x[i][j] = math.NaN()
+				} else if k, exists := index[value]; exists {
+					x[i][j] = float64(k)
+				} else {
+					x[i][j] = -1
+				}
+			}
+		}
+	}
+	return x, nil
+}
+
+func (this *tree) minSamplesSplit() int {
+	if this.config.MinSamplesSplit == 0 {
+		return DEFAULT_MIN_SAMPLES_SPLIT
+	}
+	return int(this.config.MinSamplesSplit)
+}
+
+func (this *tree) minSamplesLeaf() int {
+	if this.config.MinSamplesLeaf == 0 {
+		return DEFAULT_MIN_SAMPLES_LEAF
+	}
+	return int(this.config.MinSamplesLeaf)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// unique returns the unique non-empty labels in sorted order
+func unique(labels []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, label := range labels {
+		if label != "" && seen[label] == false {
+			seen[label] = true
+			result = append(result, label)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// argmax returns the index of the largest value, or the first index when
+// values are equal
+func argmax(values []float64) int {
+	best := 0
+	for i, value := range values {
+		if value > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// Stringify
+func (this Criterion) String() string {
+	switch this {
+	case CRITERION_DEFAULT:
+		return "CRITERION_DEFAULT"
+	case CRITERION_GINI:
+		return "CRITERION_GINI"
+	case CRITERION_ENTROPY:
+		return "CRITERION_ENTROPY"
+	case CRITERION_MSE:
+		return "CRITERION_MSE"
+	default:
+		return "[?? Invalid Criterion value]"
+	}
+}
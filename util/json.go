@@ -0,0 +1,239 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONDecoder converts a JSON value into the string stored in a table. The
+// value is a json.Number, string or bool
+type JSONDecoder func(value interface{}) (string, error)
+
+// JSONOptions determine how JSON is read into a table
+type JSONOptions struct {
+	// Path is a dotted path to the array of objects within the
+	// document, such as data.stations. Array elements can be selected
+	// with an index. When empty, the document itself is used
+	Path string
+
+	// Decoders convert values for the named (flattened) columns
+	Decoders map[string]JSONDecoder
+}
+
+// jsonField is a key and value within an object, used to preserve
+// the order of keys
+type jsonField struct {
+	key   string
+	value interface{}
+}
+
+// jsonObject is an object with the keys in document order
+type jsonObject []jsonField
+
+var (
+	ErrJSONPath = &Error{reason: "Invalid JSON path"}
+)
+
+// ReadJSON creates a table from JSON, which should be an array of objects or
+// a single object at the path in the options. Nested objects are flattened
+// into columns with dotted names (for example, data.name) and array
+// elements are flattened into columns with an index (for example,
+// tags.0). Columns are created in the order they first appear
+func ReadJSON(r io.Reader, opts JSONOptions) (*Table, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	document, err := jsonValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Follow the path
+	if opts.Path != "" {
+		for _, key := range strings.Split(opts.Path, ".") {
+			if document, err = jsonElement(document, key); err != nil {
+				return nil, fmt.Errorf("%v: %v", err, opts.Path)
+			}
+		}
+	}
+
+	// Flatten each object into a row of fields
+	var objects []interface{}
+	switch v := document.(type) {
+	case []interface{}:
+		objects = v
+	case jsonObject:
+		objects = []interface{}{v}
+	default:
+		return nil, fmt.Errorf("%v: Expected an array or object", ErrJSONPath)
+	}
+	this, _ := NewTable()
+	rows := make([]map[string]interface{}, 0, len(objects))
+	for _, object := range objects {
+		row := make(map[string]interface{})
+		fields := make([]jsonField, 0)
+		fields = jsonFlatten("", object, fields)
+		for _, field := range fields {
+			if _, exists := this.colmap[field.key]; exists == false {
+				if err := this.AppendColumns(field.key); err != nil {
+					return nil, err
+				}
+			}
+			row[field.key] = field.value
+		}
+		rows = append(rows, row)
+	}
+
+	// Convert the rows into values
+	for _, fields := range rows {
+		row := make([]*Value, len(this.Columns))
+		for i, column := range this.Columns {
+			value, exists := fields[column]
+			if exists == false || value == nil {
+				continue
+			}
+			if decoder, exists := opts.Decoders[column]; exists {
+				if str, err := decoder(value); err != nil {
+					return nil, fmt.Errorf("%v: %v", column, err)
+				} else {
+					row[i] = &Value{Str: str}
+				}
+			} else {
+				row[i] = &Value{Str: jsonString(value)}
+			}
+		}
+		this.Rows = append(this.Rows, row)
+	}
+
+	// Return success
+	return this, nil
+}
+
+// JSONUnixTime is a decoder for a number of seconds since the unix epoch,
+// which is stored as an RFC3339 timestamp in UTC
+func JSONUnixTime(value interface{}) (string, error) {
+	if seconds, err := strconv.ParseInt(jsonString(value), 10, 64); err != nil {
+		return "", err
+	} else {
+		return time.Unix(seconds, 0).UTC().Format(time.RFC3339), nil
+	}
+}
+
+// JSONDuration is a decoder for a number of seconds, which is stored as a
+// duration string such as 1m30s
+func JSONDuration(value interface{}) (string, error) {
+	if seconds, err := strconv.ParseInt(jsonString(value), 10, 64); err != nil {
+		return "", err
+	} else {
+		return (time.Second * time.Duration(seconds)).String(), nil
+	}
+}
+
+// jsonValue reads the next value from the decoder, preserving the order
+// of keys within objects
+func jsonValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := make(jsonObject, 0)
+		for decoder.More() {
+			if key, err := decoder.Token(); err != nil {
+				return nil, err
+			} else if value, err := jsonValue(decoder); err != nil {
+				return nil, err
+			} else {
+				object = append(object, jsonField{key.(string), value})
+			}
+		}
+		// Read closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case json.Delim('['):
+		array := make([]interface{}, 0)
+		for decoder.More() {
+			if value, err := jsonValue(decoder); err != nil {
+				return nil, err
+			} else {
+				array = append(array, value)
+			}
+		}
+		// Read closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return array, nil
+	default:
+		return token, nil
+	}
+}
+
+// jsonElement returns the value for a key within an object, or an index
+// within an array
+func jsonElement(value interface{}, key string) (interface{}, error) {
+	switch v := value.(type) {
+	case jsonObject:
+		for _, field := range v {
+			if field.key == key {
+				return field.value, nil
+			}
+		}
+	case []interface{}:
+		if i, err := strconv.ParseUint(key, 10, 32); err == nil && int(i) < len(v) {
+			return v[i], nil
+		}
+	}
+	return nil, ErrJSONPath
+}
+
+// jsonFlatten appends the scalar values within value to fields, with
+// dotted keys
+func jsonFlatten(prefix string, value interface{}, fields []jsonField) []jsonField {
+	switch v := value.(type) {
+	case jsonObject:
+		for _, field := range v {
+			fields = jsonFlatten(jsonKey(prefix, field.key), field.value, fields)
+		}
+	case []interface{}:
+		for i, element := range v {
+			fields = jsonFlatten(jsonKey(prefix, fmt.Sprint(i)), element, fields)
+		}
+	default:
+		if prefix == "" {
+			// Scalar values which are not within an object
+			prefix = "value"
+		}
+		fields = append(fields, jsonField{prefix, value})
+	}
+	return fields
+}
+
+// jsonKey returns a dotted key
+func jsonKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	} else {
+		return prefix + "." + key
+	}
+}
+
+// jsonString returns a scalar JSON value as a string
+func jsonString(value interface{}) string {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String()
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Matrix returns a matrix with a row for each row in the table and a
+// column for each of the named columns c. Nil values are NaN, and an error
+// is returned if any other value cannot be converted to a float
+func (this *Table) Matrix(c ...string) (*mat.Dense, error) {
+	if len(c) == 0 || len(this.Rows) == 0 {
+		return nil, ErrOutOfRange
+	}
+	m := mat.NewDense(len(this.Rows), len(c), nil)
+	for j, column := range c {
+		if values, err := this.FloatColumn(column, math.NaN()); err != nil {
+			return nil, err
+		} else {
+			m.SetCol(j, values)
+		}
+	}
+	return m, nil
+}
+
+// Split shuffles the rows using the seed and returns two new tables, the
+// first with the fraction of rows for training and the second with the
+// remaining rows for testing
+func (this *Table) Split(fraction float64, seed int64) (*Table, *Table, error) {
+	if fraction <= 0 || fraction >= 1 {
+		return nil, nil, ErrBadParameter
+	}
+	rows := rand.New(rand.NewSource(seed)).Perm(len(this.Rows))
+	n := int(math.Round(fraction * float64(len(rows))))
+	if train, err := this.Subsample(rows[:n]); err != nil {
+		return nil, nil, err
+	} else if test, err := this.Subsample(rows[n:]); err != nil {
+		return nil, nil, err
+	} else {
+		train.layouts, test.layouts = this.layouts, this.layouts
+		return train, test, nil
+	}
+}
@@ -0,0 +1,197 @@
+package util
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLOptions determine how a table is written to a database
+type SQLOptions struct {
+	// Replace drops any existing table with the same name
+	Replace bool
+
+	// Append inserts rows into an existing table with the same name
+	// rather than returning an error
+	Append bool
+}
+
+// ReadSQL creates a table from the results of a query, with a column for each
+// result column. NULL values are stored as nil values
+func ReadSQL(db *sql.DB, query string, args ...interface{}) (*Table, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	this, err := NewTable(columns...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scan each row into interface values and then convert into strings
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make([]*Value, len(columns))
+		for i, value := range values {
+			row[i] = sqlValue(value)
+		}
+		this.Rows = append(this.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return this, nil
+}
+
+// WriteSQL creates a database table with the name provided, and inserts all
+// rows within a single transaction. The column types are inferred using
+// TypeForColumn as INTEGER, REAL or TEXT, and nil values are written as
+// NULL. An error is returned if the table already exists unless either
+// Replace or Append options are set
+func (this *Table) WriteSQL(db *sql.DB, name string, opts SQLOptions) error {
+	// Determine the column types
+	types := make([]string, len(this.Columns))
+	definitions := make([]string, len(this.Columns))
+	for i, c := range this.Columns {
+		if t, err := this.TypeForColumn(c); err != nil && err != ErrOutOfRange {
+			return err
+		} else {
+			types[i] = sqlType(t)
+			definitions[i] = sqlQuote(c) + " " + types[i]
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Create the table
+	if opts.Replace {
+		if _, err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %v", sqlQuote(name))); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	create := "CREATE TABLE"
+	if opts.Append {
+		create = "CREATE TABLE IF NOT EXISTS"
+	}
+	if _, err := tx.Exec(fmt.Sprintf("%v %v (%v)", create, sqlQuote(name), strings.Join(definitions, ","))); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Insert the rows
+	quoted := make([]string, len(this.Columns))
+	placeholders := make([]string, len(this.Columns))
+	for i, c := range this.Columns {
+		quoted[i] = sqlQuote(c)
+		placeholders[i] = "?"
+	}
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v)", sqlQuote(name), strings.Join(quoted, ","), strings.Join(placeholders, ",")))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	args := make([]interface{}, len(this.Columns))
+	for _, row := range this.Rows {
+		for i := range args {
+			if i >= len(row) || row[i] == nil {
+				args[i] = nil
+			} else if arg, err := row[i].sqlArg(types[i]); err != nil {
+				tx.Rollback()
+				return err
+			} else {
+				args[i] = arg
+			}
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Commit the transaction
+	return tx.Commit()
+}
+
+// sqlValue converts a value returned from a database into a Value
+func sqlValue(value interface{}) *Value {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return &Value{Str: string(v)}
+	case string:
+		return &Value{Str: v}
+	case int64:
+		return &Value{Str: strconv.FormatInt(v, 10), _Int64: &v}
+	case float64:
+		return &Value{Str: strconv.FormatFloat(v, 'g', -1, 64), _Float64: &v}
+	case bool:
+		if v {
+			return &Value{Str: "1"}
+		} else {
+			return &Value{Str: "0"}
+		}
+	case time.Time:
+		return &Value{Str: v.Format(time.RFC3339)}
+	default:
+		return &Value{Str: fmt.Sprint(v)}
+	}
+}
+
+// sqlArg returns the value as an argument for the column type
+func (this *Value) sqlArg(t string) (interface{}, error) {
+	switch t {
+	case "INTEGER":
+		if v, err := this.Int64(); err == nil {
+			return v, nil
+		} else if v, err := this.Uint64(); err != nil {
+			return nil, err
+		} else {
+			// Values too large for a signed integer are stored as text
+			return strconv.FormatUint(v, 10), nil
+		}
+	case "REAL":
+		return this.Float64()
+	default:
+		return this.Str, nil
+	}
+}
+
+// sqlType returns the SQL column type for a type returned by TypeForColumn
+func sqlType(t string) string {
+	switch t {
+	case "uint", "int":
+		return "INTEGER"
+	case "float":
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqlQuote quotes an identifier such as a table or column name
+func sqlQuote(name string) string {
+	return "\"" + strings.Replace(name, "\"", "\"\"", -1) + "\""
+}
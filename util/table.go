@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 )
@@ -31,6 +33,7 @@ type Table struct {
 	Columns []string
 	colmap  map[string]int
 	Rows    [][]*Value
+	layouts []string
 }
 
 var (
@@ -67,6 +70,36 @@ func (this *Table) Subsample(rows []int) (*Table, error) {
 	return that, nil
 }
 
+// Select creates a new table from an existing table with the specified
+// columns, in the order specified. The rows are copied, so that values
+// can be set in the new table without changing the existing table
+func (this *Table) Select(c ...string) (*Table, error) {
+	that := new(Table)
+	if err := that.SetColumns(c...); err != nil {
+		return nil, err
+	}
+	index := make([]int, len(c))
+	for j, column := range c {
+		if n, exists := this.colmap[column]; exists == false {
+			return nil, ErrNotFound
+		} else {
+			index[j] = n
+		}
+	}
+	that.Rows = make([][]*Value, len(this.Rows))
+	for i, values := range this.Rows {
+		row := make([]*Value, len(c))
+		for j, n := range index {
+			if n < len(values) {
+				row[j] = values[n]
+			}
+		}
+		that.Rows[i] = row
+	}
+	that.layouts = this.layouts
+	return that, nil
+}
+
 // SetColumns sets the columns for the table
 func (this *Table) SetColumns(columns ...string) error {
 	this.Columns = make([]string, 0, len(columns))
@@ -85,25 +118,27 @@ func (this *Table) NumberOfColumns() int {
 
 // AppendColumns appends columns onto the table
 func (this *Table) AppendColumns(columns ...string) error {
-	// Update columns and colmap
-	for i, column := range columns {
+	// Update columns and colmap, which maps each column to its position in
+	// Columns rather than its position in the arguments
+	for _, column := range columns {
 		if _, exists := this.colmap[column]; exists {
 			return ErrDuplicateColumn
 		}
-		this.colmap[column] = i
+		this.colmap[column] = len(this.Columns)
 		this.Columns = append(this.Columns, column)
 	}
 	return nil
 }
 
 // TypeForColumn returns uint, int or float as a string depending
-// on whether a column is all uint, int or float. It can also
+// on whether a column is all uint, int or float, or time if all values
+// can be parsed with the time layouts for the table. It can also
 // return empty string if indeterminate (empty data, for example)
 func (this *Table) TypeForColumn(c string) (string, error) {
 	if n, exists := this.colmap[c]; exists == false {
 		return "", ErrNotFound
 	} else {
-		var not_float, not_uint, not_int, any bool
+		var not_float, not_uint, not_int, not_time, any bool
 		for _, values := range this.Rows {
 			if n >= len(values) || values[n] == nil {
 				continue
@@ -131,9 +166,17 @@ func (this *Table) TypeForColumn(c string) (string, error) {
 					not_float = true
 				}
 			}
+			// Check for time
+			if not_time == false {
+				if _, err := values[n].Time(this.TimeLayouts()...); err != nil {
+					not_time = true
+				}
+			}
 		}
 		if any == false {
 			return "", ErrOutOfRange
+		} else if not_int == true && not_uint == true && not_float == true && not_time == false {
+			return "time", nil
 		} else if not_int == true && not_uint == true && not_float == true {
 			return "", nil
 		} else if not_int == true && not_uint == true {
@@ -146,6 +189,90 @@ func (this *Table) TypeForColumn(c string) (string, error) {
 	}
 }
 
+// NumericColumns returns the names of columns which contain only uint,
+// int or float values, in column order. Columns with no values or
+// with any non-numeric value are not returned
+func (this *Table) NumericColumns() []string {
+	columns := make([]string, 0, len(this.Columns))
+	for _, c := range this.Columns {
+		if t, err := this.TypeForColumn(c); err == nil && t != "" && t != "time" {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// RemoveThousandsSeparator removes a thousands separator, such as a comma,
+// from values in columns c so that they can be parsed as numbers. Values
+// which are not numbers with separated groups of three digits are not
+// changed
+func (this *Table) RemoveThousandsSeparator(separator string, c ...string) error {
+	if separator == "" {
+		return ErrBadParameter
+	}
+	sep := regexp.QuoteMeta(separator)
+	pattern := regexp.MustCompile(`^[-+]?\d{1,3}(` + sep + `\d{3})*(\.\d+)?$`)
+	for _, column := range c {
+		n, exists := this.colmap[column]
+		if exists == false {
+			return ErrNotFound
+		}
+		for _, values := range this.Rows {
+			if n >= len(values) || values[n] == nil {
+				continue
+			} else if str := strings.TrimSpace(values[n].Str); pattern.MatchString(str) {
+				values[n] = &Value{Str: strings.Replace(str, separator, "", -1)}
+			}
+		}
+	}
+	return nil
+}
+
+// SetFloat sets the value in row n of column c, where NaN is set as nil
+func (this *Table) SetFloat(n int, c string, value float64) error {
+	if i, exists := this.colmap[c]; exists == false {
+		return ErrNotFound
+	} else if n < 0 || n >= len(this.Rows) {
+		return ErrOutOfRange
+	} else {
+		if i >= len(this.Rows[n]) {
+			row := make([]*Value, len(this.Columns))
+			copy(row, this.Rows[n])
+			this.Rows[n] = row
+		}
+		this.Rows[n][i] = floatValue(value)
+		return nil
+	}
+}
+
+// AppendStringColumn appends a column with a value for each row, where
+// empty strings are nil
+func (this *Table) AppendStringColumn(c string, values []string) error {
+	if len(values) != len(this.Rows) {
+		return ErrDimensionError
+	}
+	column := make([]*Value, len(values))
+	for i, value := range values {
+		if value != "" {
+			column[i] = &Value{Str: value}
+		}
+	}
+	return this.appendColumn(c, column)
+}
+
+// AppendFloatColumn appends a column with a value for each row, where
+// NaN values are nil
+func (this *Table) AppendFloatColumn(c string, values []float64) error {
+	if len(values) != len(this.Rows) {
+		return ErrDimensionError
+	}
+	column := make([]*Value, len(values))
+	for i, value := range values {
+		column[i] = floatValue(value)
+	}
+	return this.appendColumn(c, column)
+}
+
 // AppendStringRow appends a row of string values onto the table
 // and will return an error if the length of the string exceeds
 // the number of columns. If you set treat_empty_as_nil to true
@@ -335,6 +462,29 @@ func (this *Table) ReadCSV(filename string, skip_header, skip_comments, treat_em
 	return nil
 }
 
+// WriteCSV writes the columns and rows to a CSV file, where nil values
+// are written as empty strings
+func (this *Table) WriteCSV(filename string) error {
+	if f, err := os.Create(filename); err != nil {
+		return err
+	} else {
+		defer f.Close()
+		w := csv.NewWriter(f)
+		if err := w.Write(this.Columns); err != nil {
+			return err
+		}
+		for i := range this.Rows {
+			if row, err := this.StringRow(i, ""); err != nil {
+				return err
+			} else if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	}
+}
+
 // Stringify
 func (this *Value) String() string {
 	return this.Str
@@ -385,6 +535,21 @@ func (this *Value) Int64() (int64, error) {
 	}
 }
 
+// Time parses the value with the first layout which succeeds
+func (this *Value) Time(layouts ...string) (time.Time, error) {
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, strings.TrimSpace(this.Str)); err == nil {
+			return t, nil
+		}
+	}
+	if err == nil {
+		err = ErrNotTime
+	}
+	return time.Time{}, err
+}
+
 func float64conv(str string) (float64, error) {
 	return strconv.ParseFloat(str, 64)
 }
@@ -0,0 +1,389 @@
+package util
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Aggregation determines how values are combined when resampling or
+// computing rolling windows
+type Aggregation int
+
+const (
+	AGGREGATE_MEAN Aggregation = iota
+	AGGREGATE_SUM
+	AGGREGATE_MIN
+	AGGREGATE_MAX
+	AGGREGATE_STD
+	AGGREGATE_FIRST
+	AGGREGATE_LAST
+	AGGREGATE_COUNT
+)
+
+var (
+	// The time layouts used when no layouts are set for a table
+	DEFAULT_TIME_LAYOUTS = []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+)
+
+var (
+	ErrNotTime        = &Error{reason: "Value is not a time"}
+	ErrBadParameter   = &Error{reason: "Bad parameter"}
+	ErrBadAggregation = &Error{reason: "Unknown aggregation"}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TIME COLUMNS
+
+// SetTimeLayouts sets the layouts used to parse time values, in the format
+// used by time.Parse. Each value is parsed with the first layout which
+// succeeds. With no layouts, DEFAULT_TIME_LAYOUTS are used
+func (this *Table) SetTimeLayouts(layouts ...string) {
+	this.layouts = layouts
+}
+
+// TimeLayouts returns the layouts used to parse time values
+func (this *Table) TimeLayouts() []string {
+	if len(this.layouts) == 0 {
+		return DEFAULT_TIME_LAYOUTS
+	} else {
+		return this.layouts
+	}
+}
+
+// TimeColumn returns all values in a specific named column, c as time
+// values. If any values are nil then the zero time is used. If any value
+// cannot be parsed, then an error is returned
+func (this *Table) TimeColumn(c string) ([]time.Time, error) {
+	if n, exists := this.colmap[c]; exists == false {
+		return nil, ErrNotFound
+	} else {
+		layouts := this.TimeLayouts()
+		column := make([]time.Time, len(this.Rows))
+		for i, values := range this.Rows {
+			if n >= len(values) || values[n] == nil {
+				continue
+			} else if t, err := values[n].Time(layouts...); err != nil {
+				return nil, ErrNotTime
+			} else {
+				column[i] = t
+			}
+		}
+		return column, nil
+	}
+}
+
+// SortByTime sorts the rows by the time in column c, keeping the order
+// of rows with the same time. Rows with nil values are sorted first
+func (this *Table) SortByTime(c string) error {
+	times, err := this.TimeColumn(c)
+	if err != nil {
+		return err
+	}
+	index := make([]int, len(this.Rows))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return times[index[i]].Before(times[index[j]])
+	})
+	rows := make([][]*Value, len(this.Rows))
+	for i, j := range index {
+		rows[i] = this.Rows[j]
+	}
+	this.Rows = rows
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RESAMPLING
+
+// Resample returns a new table with one row for each interval between the
+// first and last times in column c, which contains the start of the
+// interval formatted with the first time layout. Each column in
+// aggregations is aggregated over the rows within the interval, in the
+// order of the columns in this table. Intervals with no values are nil,
+// except for counts which are zero. Rows with nil times are ignored
+func (this *Table) Resample(c string, interval time.Duration, aggregations map[string]Aggregation) (*Table, error) {
+	if interval <= 0 {
+		return nil, ErrBadParameter
+	}
+	times, err := this.TimeColumn(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine the columns and their values
+	columns := []string{c}
+	values := make([][]float64, 0, len(aggregations))
+	for _, column := range this.Columns {
+		if aggregation, exists := aggregations[column]; exists == false {
+			continue
+		} else if aggregation < AGGREGATE_MEAN || aggregation > AGGREGATE_COUNT {
+			return nil, ErrBadAggregation
+		} else if v, err := this.FloatColumn(column, math.NaN()); err != nil {
+			return nil, err
+		} else {
+			columns = append(columns, column)
+			values = append(values, v)
+		}
+	}
+	if len(columns) != len(aggregations)+1 {
+		return nil, ErrNotFound
+	}
+
+	// Determine the range of times
+	var first, last time.Time
+	for _, t := range times {
+		if t.IsZero() {
+			continue
+		}
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if last.IsZero() || t.After(last) {
+			last = t
+		}
+	}
+	that, err := NewTable(columns...)
+	if err != nil {
+		return nil, err
+	}
+	that.layouts = this.layouts
+	if first.IsZero() {
+		return that, nil
+	}
+	first = first.Truncate(interval)
+
+	// Collect the values within each interval
+	intervals := int(last.Sub(first)/interval) + 1
+	buckets := make([][][]float64, len(values))
+	for i := range buckets {
+		buckets[i] = make([][]float64, intervals)
+	}
+	for row, t := range times {
+		if t.IsZero() {
+			continue
+		}
+		bucket := int(t.Sub(first) / interval)
+		for i := range values {
+			if math.IsNaN(values[i][row]) == false {
+				buckets[i][bucket] = append(buckets[i][bucket], values[i][row])
+			}
+		}
+	}
+
+	// Aggregate each interval
+	layout := that.TimeLayouts()[0]
+	for bucket := 0; bucket < intervals; bucket++ {
+		row := make([]*Value, len(columns))
+		row[0] = &Value{Str: first.Add(interval * time.Duration(bucket)).Format(layout)}
+		for i := range values {
+			row[i+1] = aggregationValue(aggregations[columns[i+1]], buckets[i][bucket])
+		}
+		that.Rows = append(that.Rows, row)
+	}
+
+	// Return success
+	return that, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// DERIVED COLUMNS
+
+// AppendRolling appends a column called name with the aggregation of the
+// values in column c over a window of rows ending with the current row.
+// The value is nil until the window is full, or if any value within the
+// window is nil. Rows should be sorted by time
+func (this *Table) AppendRolling(name, c string, window uint, aggregation Aggregation) error {
+	if window == 0 {
+		return ErrBadParameter
+	} else if aggregation < AGGREGATE_MEAN || aggregation > AGGREGATE_COUNT {
+		return ErrBadAggregation
+	}
+	values, err := this.FloatColumn(c, math.NaN())
+	if err != nil {
+		return err
+	}
+	column := make([]*Value, len(values))
+	for i := int(window) - 1; i < len(values); i++ {
+		w := values[i-int(window)+1 : i+1]
+		if hasNaN(w) == false {
+			column[i] = aggregationValue(aggregation, w)
+		}
+	}
+	return this.appendColumn(name, column)
+}
+
+// AppendLag appends a column called name with the value in column c from
+// n rows earlier, or later if n is negative. Rows should be sorted by time
+func (this *Table) AppendLag(name, c string, n int) error {
+	i, exists := this.colmap[c]
+	if exists == false {
+		return ErrNotFound
+	}
+	column := make([]*Value, len(this.Rows))
+	for row := range column {
+		if from := row - n; from >= 0 && from < len(this.Rows) && i < len(this.Rows[from]) {
+			column[row] = this.Rows[from][i]
+		}
+	}
+	return this.appendColumn(name, column)
+}
+
+// AppendLead appends a column called name with the value in column c from
+// n rows later. Rows should be sorted by time
+func (this *Table) AppendLead(name, c string, n int) error {
+	return this.AppendLag(name, c, -n)
+}
+
+// AppendDiff appends a column called name with the difference between the
+// value in column c and the value n rows earlier
+func (this *Table) AppendDiff(name, c string, n uint) error {
+	return this.appendChange(name, c, n, func(value, previous float64) float64 {
+		return value - previous
+	})
+}
+
+// AppendPercentChange appends a column called name with the change between
+// the value n rows earlier and the value in column c, as a percentage of
+// the earlier value. The value is nil when the earlier value is zero
+func (this *Table) AppendPercentChange(name, c string, n uint) error {
+	return this.appendChange(name, c, n, func(value, previous float64) float64 {
+		if previous == 0 {
+			return math.NaN()
+		}
+		return 100 * (value - previous) / previous
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// appendChange appends a column with a function of each value and the
+// value n rows earlier
+func (this *Table) appendChange(name, c string, n uint, change func(float64, float64) float64) error {
+	if n == 0 {
+		return ErrBadParameter
+	}
+	values, err := this.FloatColumn(c, math.NaN())
+	if err != nil {
+		return err
+	}
+	column := make([]*Value, len(values))
+	for i := int(n); i < len(values); i++ {
+		column[i] = floatValue(change(values[i], values[i-int(n)]))
+	}
+	return this.appendColumn(name, column)
+}
+
+// appendColumn appends a column with a value for each row
+func (this *Table) appendColumn(name string, column []*Value) error {
+	if err := this.AppendColumns(name); err != nil {
+		return err
+	}
+	n := len(this.Columns) - 1
+	for i, row := range this.Rows {
+		// Copy the row, which may be shared with another table
+		values := make([]*Value, len(this.Columns))
+		copy(values, row)
+		values[n] = column[i]
+		this.Rows[i] = values
+	}
+	return nil
+}
+
+// aggregationValue returns the aggregation of values, or nil if there are
+// no values
+func aggregationValue(aggregation Aggregation, values []float64) *Value {
+	if aggregation == AGGREGATE_COUNT {
+		return floatValue(float64(len(values)))
+	} else if len(values) == 0 {
+		return nil
+	}
+	var result float64
+	switch aggregation {
+	case AGGREGATE_MEAN, AGGREGATE_SUM:
+		for _, v := range values {
+			result += v
+		}
+		if aggregation == AGGREGATE_MEAN {
+			result /= float64(len(values))
+		}
+	case AGGREGATE_MIN, AGGREGATE_MAX:
+		result = values[0]
+		for _, v := range values {
+			if aggregation == AGGREGATE_MIN {
+				result = math.Min(result, v)
+			} else {
+				result = math.Max(result, v)
+			}
+		}
+	case AGGREGATE_STD:
+		// Sample standard deviation, which needs at least two values
+		if len(values) < 2 {
+			return nil
+		}
+		var mean, ss float64
+		for _, v := range values {
+			mean += v / float64(len(values))
+		}
+		for _, v := range values {
+			ss += (v - mean) * (v - mean)
+		}
+		result = math.Sqrt(ss / float64(len(values)-1))
+	case AGGREGATE_FIRST:
+		result = values[0]
+	case AGGREGATE_LAST:
+		result = values[len(values)-1]
+	}
+	return floatValue(result)
+}
+
+// floatValue returns a value for a float, or nil for NaN
+func floatValue(f float64) *Value {
+	if math.IsNaN(f) {
+		return nil
+	}
+	return &Value{Str: strconv.FormatFloat(f, 'f', -1, 64), _Float64: &f}
+}
+
+// hasNaN returns true if any value is NaN
+func hasNaN(values []float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stringify
+func (this Aggregation) String() string {
+	switch this {
+	case AGGREGATE_MEAN:
+		return "AGGREGATE_MEAN"
+	case AGGREGATE_SUM:
+		return "AGGREGATE_SUM"
+	case AGGREGATE_MIN:
+		return "AGGREGATE_MIN"
+	case AGGREGATE_MAX:
+		return "AGGREGATE_MAX"
+	case AGGREGATE_STD:
+		return "AGGREGATE_STD"
+	case AGGREGATE_FIRST:
+		return "AGGREGATE_FIRST"
+	case AGGREGATE_LAST:
+		return "AGGREGATE_LAST"
+	case AGGREGATE_COUNT:
+		return "AGGREGATE_COUNT"
+	default:
+		return "[?? Invalid Aggregation value]"
+	}
+}